@@ -0,0 +1,348 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrAmendFormat 排除/增加的时间段格式不对
+var ErrAmendFormat = errors.New("amend format not math")
+
+var (
+	// amendDateRegex 整天, etc: 2024-02-10
+	amendDateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// amendUnitRegex 某天中的时间段, etc: 2024-03-01 20:00:00-22:00:00
+	amendUnitRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}) (\d{1,2}:\d{2}:\d{2}-\d{1,2}:\d{2}:\d{2})$`)
+	// amendRangeRegex 跨天的时间段, etc: 2024-03-01 20:00:00~2024-03-02 02:00:00
+	amendRangeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{1,2}:\d{2}:\d{2})~(\d{4}-\d{2}-\d{2} \d{1,2}:\d{2}:\d{2})$`)
+)
+
+// amendMinTime, amendMaxTime 永远有效的表达式对应的周期
+var (
+	amendMinTime = time.Date(1, time.January, 1, 0, 0, 0, 0, time.Local)
+	amendMaxTime = time.Date(MaxYear+1, time.January, 1, 0, 0, 0, 0, time.Local)
+)
+
+// AmendedExpression 在表达式上排除和增加时间段, 例如节假日取消某次活动, 或者临时加开一场
+// 有效的时间为(基础表达式 ∪ 增加的时间段) - 排除的时间段, 排除的时间段可以把一个周期拆分为多个
+type AmendedExpression struct {
+	base    Expression
+	excepts []window
+	plus    []window
+
+	// dayStart 逻辑上一天开始的时间, 用于整天的时间段
+	dayStart int
+}
+
+var _ Expression = (*AmendedExpression)(nil)
+
+// Amend 创建基于表达式的AmendedExpression, 再通过Except和Plus排除和增加时间段
+func Amend(base Expression) *AmendedExpression {
+	return &AmendedExpression{base: base}
+}
+
+// Except 排除时间段[start, end)
+func (expression *AmendedExpression) Except(start time.Time, end time.Time) *AmendedExpression {
+	expression.excepts = mergeWindows(append(expression.excepts, window{start: start, end: end}))
+	return expression
+}
+
+// Plus 增加时间段[start, end)
+func (expression *AmendedExpression) Plus(start time.Time, end time.Time) *AmendedExpression {
+	expression.plus = mergeWindows(append(expression.plus, window{start: start, end: end}))
+	return expression
+}
+
+// ParseAmendedExpression 解析带有排除和增加时间段的表达式
+// 格式为 表达式[; except 时间段,时间段...][; plus 时间段,时间段...], 表达式为DateTimeExpression, CycleExpression或者RangeExpression
+// 时间段可以为整天 2024-02-10, 某天中的时间段 2024-03-01 20:00:00-22:00:00, 或者跨天的 2024-03-01 20:00:00~2024-03-02 02:00:00
+// 整天的时间段按WithDayStart设置的逻辑上的一天计算
+func ParseAmendedExpression(expression string, opts ...Option) (*AmendedExpression, error) {
+	clauses := strings.Split(expression, ";")
+
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	amended := &AmendedExpression{dayStart: options.dayStart}
+	baseStr := strings.TrimSpace(clauses[0])
+	switch {
+	case strings.Contains(baseStr, "[r"):
+		amended.base, err = NewCycleExpression(baseStr)
+	case !strings.HasPrefix(baseStr, "[") && strings.Contains(baseStr, "~"):
+		amended.base, err = NewRangeExpression(baseStr)
+	default:
+		amended.base, err = NewDateTimeExpression(baseStr, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, clause := range clauses[1:] {
+		clause = strings.TrimSpace(clause)
+		var add func(time.Time, time.Time) *AmendedExpression
+		switch {
+		case strings.HasPrefix(clause, "except "):
+			add = amended.Except
+			clause = strings.TrimPrefix(clause, "except ")
+		case strings.HasPrefix(clause, "plus "):
+			add = amended.Plus
+			clause = strings.TrimPrefix(clause, "plus ")
+		default:
+			return nil, fmt.Errorf("%w: unknown clause '%s'", ErrAmendFormat, clause)
+		}
+		for _, item := range strings.Split(clause, ",") {
+			w, err := amended.parseItem(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			add(w.start, w.end)
+		}
+	}
+
+	return amended, nil
+}
+
+// parseItem 解析一个时间段
+func (expression *AmendedExpression) parseItem(item string) (window, error) {
+	if amendDateRegex.MatchString(item) {
+		day, err := time.ParseInLocation("2006-01-02", item, time.Local)
+		if err != nil {
+			return window{}, fmt.Errorf("%w: invalid date '%s'", ErrAmendFormat, item)
+		}
+		return window{start: expression.dayTime(day, 0), end: expression.dayTime(day, secondsPerDay)}, nil
+	}
+
+	if matches := amendUnitRegex.FindStringSubmatch(item); matches != nil {
+		day, err := time.ParseInLocation("2006-01-02", matches[1], time.Local)
+		if err != nil {
+			return window{}, fmt.Errorf("%w: invalid date '%s'", ErrAmendFormat, item)
+		}
+		unit, err := newHourUnitExpression(matches[2])
+		if err != nil {
+			return window{}, err
+		}
+		return window{start: unitTime(day, unit.start), end: unitTime(day, unit.end)}, nil
+	}
+
+	if matches := amendRangeRegex.FindStringSubmatch(item); matches != nil {
+		start, startErr := time.ParseInLocation("2006-01-02 15:04:05", matches[1], time.Local)
+		end, endErr := time.ParseInLocation("2006-01-02 15:04:05", matches[2], time.Local)
+		if startErr != nil || endErr != nil || !start.Before(end) {
+			return window{}, fmt.Errorf("%w: invalid range '%s'", ErrAmendFormat, item)
+		}
+		return window{start: start, end: end}, nil
+	}
+
+	return window{}, fmt.Errorf("%w: invalid item '%s'", ErrAmendFormat, item)
+}
+
+// dayTime 逻辑上某天开始之后sec秒的时间
+func (expression *AmendedExpression) dayTime(day time.Time, sec int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, expression.dayStart+sec, 0, time.Local)
+}
+
+// IsIn 判断时间是否在表达式指定范围内
+func (expression *AmendedExpression) IsIn(t time.Time) bool {
+	if isInWindows(expression.excepts, t) {
+		return false
+	}
+	return expression.base.IsIn(t) || isInWindows(expression.plus, t)
+}
+
+// GetStartTime 获取开始时间, 在周期内返回本次的开始时间, 否则返回下次的开始时间
+func (expression *AmendedExpression) GetStartTime(t time.Time) (time.Time, error) {
+	w, err := expression.windowAt(t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if w.start.Equal(amendMinTime) {
+		return time.Time{}, ErrAlwaysActiveNoStartTime
+	}
+	return w.start, nil
+}
+
+// GetEndTime 获取结束时间, 在周期内返回本次的结束时间, 否则返回下次的结束时间
+func (expression *AmendedExpression) GetEndTime(t time.Time) (time.Time, error) {
+	w, err := expression.windowAt(t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if w.end.Equal(amendMaxTime) {
+		return time.Time{}, ErrNoEnd
+	}
+	return w.end, nil
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在周期内，都获取下次的时间
+func (expression *AmendedExpression) GetNextStartTime(t time.Time) (time.Time, error) {
+	return nextStartTime(expression, t)
+}
+
+// String 格式化为 表达式; except 时间段; plus 时间段
+func (expression *AmendedExpression) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprint(expression.base))
+	for _, clause := range []struct {
+		name    string
+		windows []window
+	}{{"except", expression.excepts}, {"plus", expression.plus}} {
+		if len(clause.windows) == 0 {
+			continue
+		}
+		items := make([]string, 0, len(clause.windows))
+		for _, w := range clause.windows {
+			items = append(items, expression.formatItem(w))
+		}
+		builder.WriteString("; " + clause.name + " " + strings.Join(items, ","))
+	}
+	return builder.String()
+}
+
+// formatItem 格式化一个时间段, 尽量使用整天或者某天中的时间段
+func (expression *AmendedExpression) formatItem(w window) string {
+	day := truncateDay(w.start)
+	if w.start.Equal(expression.dayTime(day, 0)) && w.end.Equal(expression.dayTime(day, secondsPerDay)) {
+		return day.Format("2006-01-02")
+	}
+	if !w.end.After(day.AddDate(0, 0, 1)) {
+		end := w.end.Format("15:04:05")
+		if w.end.Equal(day.AddDate(0, 0, 1)) {
+			end = "24:00:00"
+		}
+		return w.start.Format("2006-01-02 15:04:05") + "-" + end
+	}
+	return w.start.Format("2006-01-02 15:04:05") + "~" + w.end.Format("2006-01-02 15:04:05")
+}
+
+// windowAt 获取包含t的周期, 不在周期内时获取下一个周期
+func (expression *AmendedExpression) windowAt(t time.Time) (window, error) {
+	// 找到和t所在的周期相连的最早的时间, 基础表达式和增加的时间段可能互相重叠
+	from := t
+	for {
+		earliest := from
+		for _, w := range expression.plus {
+			if w.start.Before(earliest) && !from.Before(w.start) && from.Before(w.end) {
+				earliest = w.start
+			}
+		}
+		if expression.base.IsIn(earliest) {
+			if start, err := expression.base.GetStartTime(earliest); err == nil && start.Before(earliest) {
+				earliest = start
+			}
+		}
+		if earliest.Equal(from) {
+			break
+		}
+		from = earliest
+	}
+
+	stream := &amendStream{base: expression.base, from: from, plus: expression.plus}
+	for len(stream.plus) > 0 && !stream.plus[0].end.After(from) {
+		stream.plus = stream.plus[1:]
+	}
+	for {
+		w, err := stream.next()
+		if err != nil {
+			return window{}, err
+		}
+		for _, piece := range subtractWindows(w, expression.excepts) {
+			if t.Before(piece.end) {
+				return piece, nil
+			}
+		}
+	}
+}
+
+// amendStream 按开始时间的顺序合并基础表达式的周期和增加的时间段
+type amendStream struct {
+	base Expression
+	from time.Time
+	// plus 还没有合并的增加的时间段
+	plus []window
+
+	baseNext *window
+	baseErr  error
+}
+
+// nextBase 获取基础表达式的下一个周期, 没有时返回nil
+func (stream *amendStream) nextBase() *window {
+	if stream.baseNext != nil || stream.baseErr != nil {
+		return stream.baseNext
+	}
+	start, err := stream.base.GetStartTime(stream.from)
+	if err == ErrAlwaysActiveNoStartTime {
+		stream.baseNext = &window{start: amendMinTime, end: amendMaxTime}
+		stream.baseErr = ErrOutOfDate
+		return stream.baseNext
+	}
+	if err != nil {
+		stream.baseErr = err
+		return nil
+	}
+	end, err := stream.base.GetEndTime(start)
+	if err != nil {
+		stream.baseErr = err
+		return nil
+	}
+	stream.baseNext = &window{start: start, end: end}
+	stream.from = end
+	return stream.baseNext
+}
+
+// next 获取下一个合并后的周期
+func (stream *amendStream) next() (window, error) {
+	var merged *window
+	for {
+		w := stream.nextBase()
+		fromBase := w != nil
+		if len(stream.plus) > 0 && (w == nil || stream.plus[0].start.Before(w.start)) {
+			w, fromBase = &stream.plus[0], false
+		}
+		if w == nil || (merged != nil && !w.start.Before(merged.end)) {
+			break
+		}
+
+		if merged == nil {
+			merged = &window{start: w.start, end: w.end}
+		} else if w.end.After(merged.end) {
+			merged.end = w.end
+		}
+		if fromBase {
+			stream.baseNext = nil
+		} else {
+			stream.plus = stream.plus[1:]
+		}
+	}
+
+	if merged == nil {
+		return window{}, stream.baseErr
+	}
+	return *merged, nil
+}
+
+// subtractWindows 从周期中去掉排除的时间段, excepts需要是排序并合并过的
+func subtractWindows(w window, excepts []window) []window {
+	pieces := []window{w}
+	for _, except := range excepts {
+		last := pieces[len(pieces)-1]
+		if !except.start.Before(last.end) {
+			break
+		}
+		if !except.end.After(last.start) {
+			continue
+		}
+		pieces = pieces[:len(pieces)-1]
+		if except.start.After(last.start) {
+			pieces = append(pieces, window{start: last.start, end: except.start})
+		}
+		if !except.end.Before(last.end) {
+			break
+		}
+		pieces = append(pieces, window{start: except.end, end: last.end})
+	}
+	return pieces
+}
@@ -0,0 +1,204 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseAmendedExpression(t *testing.T) {
+	testDatas := []struct {
+		exp string
+		// formatted String()的结果, 为空时和exp相同
+		formatted string
+		err       error
+	}{
+		{exp: "[*][*][w6][20:00:00-22:00:00]; except 2024-02-10,2024-02-11; plus 2024-03-01 20:00:00-22:00:00"},
+		{exp: "[*][*][w6][20:00:00-22:00:00]; plus 2024-03-01 20:00:00~2024-03-02 02:00:00"},
+		{exp: "[*][*][w6][20:00:00-22:00:00]; plus 2024-03-01 20:00:00-24:00:00"},
+		{exp: "[*][r2024-03-04 20:00:00/14d/2h]; except 2024-03-18"},
+		{exp: "2024-01-05 10:00:00 ~ 2024-01-20 18:00:00; except 2024-01-10"},
+		{exp: "[*][*][*][*]"},
+		{
+			// 排序并合并重叠的时间段
+			exp:       "[*][*][*][*];except 2024-02-11 , 2024-02-10 10:00:00-12:00:00;except 2024-02-10 11:00:00-13:00:00",
+			formatted: "[*][*][*][*]; except 2024-02-10 10:00:00-13:00:00,2024-02-11",
+		},
+
+		{exp: "[*][*][*][*]; exclude 2024-02-10", err: ErrAmendFormat},
+		{exp: "[*][*][*][*]; except 2024-02-30", err: ErrAmendFormat},
+		{exp: "[*][*][*][*]; except 2024-2-3", err: ErrAmendFormat},
+		{exp: "[*][*][*][*]; plus 2024-03-02 02:00:00~2024-03-01 20:00:00", err: ErrAmendFormat},
+		{exp: "[*][*][*]; except 2024-02-10", err: ErrDateTimeFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := ParseAmendedExpression(data.exp)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.exp
+		}
+		assert.Equal(t, formatted, expr.String())
+	}
+}
+
+func TestAmendedExpression(t *testing.T) {
+	testDatas := []struct {
+		exp      string
+		t        time.Time
+		isIn     bool
+		start    time.Time
+		startErr error
+		end      time.Time
+		endErr   error
+	}{
+		{
+			// 2024-02-10的周六被排除
+			exp:   "[*][*][w6][20:00:00-22:00:00]; except 2024-02-10; plus 2024-03-01 20:00:00-22:00:00",
+			t:     time.Date(2024, time.February, 9, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 17, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 17, 22, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][w6][20:00:00-22:00:00]; except 2024-02-10; plus 2024-03-01 20:00:00-22:00:00",
+			t:     time.Date(2024, time.February, 10, 21, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 17, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 17, 22, 0, 0, 0, time.Local),
+		},
+		{
+			// 2024-03-01是周五, 临时加开
+			exp:   "[*][*][w6][20:00:00-22:00:00]; except 2024-02-10; plus 2024-03-01 20:00:00-22:00:00",
+			t:     time.Date(2024, time.March, 1, 21, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 1, 22, 0, 0, 0, time.Local),
+		},
+		{
+			// 排除的时间段把周期拆分为两个
+			exp:   "[2024][03][01][*]; except 2024-03-01 12:00:00-13:00:00",
+			t:     time.Date(2024, time.March, 1, 10, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 1, 12, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][03][01][*]; except 2024-03-01 12:00:00-13:00:00",
+			t:     time.Date(2024, time.March, 1, 12, 30, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 1, 13, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 2, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[2024][03][01][*]; except 2024-03-01 12:00:00-13:00:00",
+			t:        time.Date(2024, time.March, 2, 0, 0, 0, 0, time.Local),
+			startErr: ErrOutOfDate,
+			endErr:   ErrOutOfDate,
+		},
+		{
+			// 增加的时间段和周期重叠时合并
+			exp:   "[2024][03][01][10:00:00-12:00:00,13:00:00-15:00:00]; plus 2024-03-01 11:00:00-13:30:00",
+			t:     time.Date(2024, time.March, 1, 14, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 10, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 1, 15, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][03][01][10:00:00-12:00:00]; plus 2024-03-05 10:00:00-12:00:00",
+			t:     time.Date(2024, time.March, 2, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 5, 10, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 5, 12, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[*][*][*][*]; except 2024-02-10",
+			t:        time.Date(2024, time.February, 9, 0, 0, 0, 0, time.Local),
+			isIn:     true,
+			startErr: ErrAlwaysActiveNoStartTime,
+			end:      time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[*][*][*][*]; except 2024-02-10",
+			t:      time.Date(2024, time.February, 10, 12, 0, 0, 0, time.Local),
+			start:  time.Date(2024, time.February, 11, 0, 0, 0, 0, time.Local),
+			endErr: ErrNoEnd,
+		},
+		{
+			exp:   "[*][r2024-03-04 20:00:00/14d/2h]; except 2024-03-18",
+			t:     time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.April, 1, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.April, 1, 22, 0, 0, 0, time.Local),
+		},
+		{
+			// 绝对时间范围中间排除一天
+			exp:   "2024-01-05 10:00:00 ~ 2024-01-20 18:00:00; except 2024-01-10",
+			t:     time.Date(2024, time.January, 10, 12, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.January, 11, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.January, 20, 18, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := ParseAmendedExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Equal(t, data.startErr, err)
+		if data.startErr == nil {
+			assert.Equal(t, data.start, start)
+		}
+		end, err := expr.GetEndTime(data.t)
+		assert.Equal(t, data.endErr, err)
+		if data.endErr == nil {
+			assert.Equal(t, data.end, end)
+		}
+	}
+}
+
+func TestAmendedExpression_DayStart(t *testing.T) {
+	expr, err := ParseAmendedExpression("[*][*][*][20:00:00-22:00:00,02:00:00-03:00:00]; except 2024-02-10", WithDayStart(5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "[*][*][*][02:00:00-03:00:00,20:00:00-22:00:00]; except 2024-02-10", expr.String())
+
+	// 逻辑上的2024-02-10为02-10 05:00到02-11 05:00
+	next, err := expr.GetNextStartTime(time.Date(2024, time.February, 10, 4, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 11, 20, 0, 0, 0, time.Local), next)
+	assert.True(t, expr.IsIn(time.Date(2024, time.February, 10, 2, 30, 0, 0, time.Local)))
+	assert.False(t, expr.IsIn(time.Date(2024, time.February, 11, 2, 30, 0, 0, time.Local)))
+}
+
+func TestAmend(t *testing.T) {
+	base, err := NewDateTimeExpression("[*][*][w6][20:00:00-22:00:00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := Amend(base).
+		Except(time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local), time.Date(2024, time.February, 11, 0, 0, 0, 0, time.Local)).
+		Except(time.Date(2024, time.February, 17, 21, 0, 0, 0, time.Local), time.Date(2024, time.February, 17, 21, 30, 0, 0, time.Local)).
+		Plus(time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local), time.Date(2024, time.March, 1, 22, 0, 0, 0, time.Local))
+	assert.Equal(t, "[*][*][w6][20:00:00-22:00:00]; except 2024-02-10,2024-02-17 21:00:00-21:30:00; plus 2024-03-01 20:00:00-22:00:00", expr.String())
+
+	from := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, time.March, 3, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, []window{
+		{start: time.Date(2024, time.February, 3, 20, 0, 0, 0, time.Local), end: time.Date(2024, time.February, 3, 22, 0, 0, 0, time.Local)},
+		{start: time.Date(2024, time.February, 17, 20, 0, 0, 0, time.Local), end: time.Date(2024, time.February, 17, 21, 0, 0, 0, time.Local)},
+		{start: time.Date(2024, time.February, 17, 21, 30, 0, 0, time.Local), end: time.Date(2024, time.February, 17, 22, 0, 0, 0, time.Local)},
+		{start: time.Date(2024, time.February, 24, 20, 0, 0, 0, time.Local), end: time.Date(2024, time.February, 24, 22, 0, 0, 0, time.Local)},
+		{start: time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local), end: time.Date(2024, time.March, 1, 22, 0, 0, 0, time.Local)},
+		{start: time.Date(2024, time.March, 2, 20, 0, 0, 0, time.Local), end: time.Date(2024, time.March, 2, 22, 0, 0, 0, time.Local)},
+	}, icalWindows(t, expr, from, until))
+}
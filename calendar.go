@@ -0,0 +1,220 @@
+package timeexpression
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCalendarFormat 日历文件格式不对
+	ErrCalendarFormat = errors.New("calendar format not math")
+	// ErrNoCalendar 表达式使用了工作日(bd), 但是没有通过WithCalendar设置日历
+	ErrNoCalendar = errors.New("expression needs a calendar")
+)
+
+// calendarDayLayout 日历中日期的格式
+const calendarDayLayout = "2006-01-02"
+
+// calendarDay 日历中的一天, 不带时区
+type calendarDay struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// newCalendarDay 取时间t所在的日期
+func newCalendarDay(t time.Time) calendarDay {
+	return calendarDay{year: t.Year(), month: t.Month(), day: t.Day()}
+}
+
+// Calendar 节假日和调休的日历
+// 工作日为周一到周五中不是节假日的日期, 加上调休上班的日期(例如春节前后的周末)
+type Calendar struct {
+	// holidays 节假日, 值为节日名称
+	holidays map[calendarDay]string
+	// workdays 调休上班的日期, 值为说明
+	workdays map[calendarDay]string
+}
+
+// NewCalendar 创建空的日历, 空的日历中工作日即周一到周五
+func NewCalendar() *Calendar {
+	return &Calendar{holidays: map[calendarDay]string{}, workdays: map[calendarDay]string{}}
+}
+
+// AddHoliday 增加一天节假日
+func (calendar *Calendar) AddHoliday(day time.Time, name string) *Calendar {
+	calendar.holidays[newCalendarDay(day)] = name
+	return calendar
+}
+
+// AddWorkday 增加一天调休上班的日期
+func (calendar *Calendar) AddWorkday(day time.Time, name string) *Calendar {
+	calendar.workdays[newCalendarDay(day)] = name
+	return calendar
+}
+
+// IsHoliday 判断时间t所在的日期是否为节假日
+func (calendar *Calendar) IsHoliday(t time.Time) bool {
+	_, ok := calendar.holidays[newCalendarDay(t)]
+	return ok
+}
+
+// HolidayName 获取节假日的名称, 不是节假日时ok为false
+func (calendar *Calendar) HolidayName(t time.Time) (name string, ok bool) {
+	name, ok = calendar.holidays[newCalendarDay(t)]
+	return name, ok
+}
+
+// IsBusinessDay 判断时间t所在的日期是否为工作日
+func (calendar *Calendar) IsBusinessDay(t time.Time) bool {
+	day := newCalendarDay(t)
+	if _, ok := calendar.workdays[day]; ok {
+		return true
+	}
+	if _, ok := calendar.holidays[day]; ok {
+		return false
+	}
+	return isoWeekday(t) <= 5
+}
+
+// add 按类型增加日期, dates为yyyy-MM-dd或者yyyy-MM-dd~yyyy-MM-dd
+func (calendar *Calendar) add(kind string, dates string, name string) error {
+	days, err := parseCalendarDays(dates)
+	if err != nil {
+		return err
+	}
+
+	var target, other map[calendarDay]string
+	switch kind {
+	case "holiday":
+		target, other = calendar.holidays, calendar.workdays
+	case "workday":
+		target, other = calendar.workdays, calendar.holidays
+	default:
+		return fmt.Errorf("%w: unknown type '%s'", ErrCalendarFormat, kind)
+	}
+	for _, day := range days {
+		if _, ok := other[day]; ok {
+			return fmt.Errorf("%w: %04d-%02d-%02d is both holiday and workday", ErrCalendarFormat, day.year, day.month, day.day)
+		}
+		target[day] = name
+	}
+	return nil
+}
+
+// parseCalendarDays 解析单个日期或者日期范围, 范围两端都包含
+func parseCalendarDays(dates string) ([]calendarDay, error) {
+	dateSplits := strings.Split(dates, "~")
+	if len(dateSplits) > 2 {
+		return nil, fmt.Errorf("%w: invalid date '%s'", ErrCalendarFormat, dates)
+	}
+	start, err := time.ParseInLocation(calendarDayLayout, strings.TrimSpace(dateSplits[0]), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid date '%s'", ErrCalendarFormat, dates)
+	}
+	end := start
+	if len(dateSplits) == 2 {
+		end, err = time.ParseInLocation(calendarDayLayout, strings.TrimSpace(dateSplits[1]), time.Local)
+		if err != nil || end.Before(start) {
+			return nil, fmt.Errorf("%w: invalid date '%s'", ErrCalendarFormat, dates)
+		}
+	}
+
+	var days []calendarDay
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, newCalendarDay(day))
+	}
+	return days, nil
+}
+
+// calendarEntry JSON日历中的一项
+type calendarEntry struct {
+	// Date 日期, yyyy-MM-dd或者yyyy-MM-dd~yyyy-MM-dd
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// LoadCalendarJSON 从JSON加载日历, 格式为
+// {"holidays": [{"date": "2024-02-10~2024-02-17", "name": "春节"}], "workdays": [{"date": "2024-02-04", "name": "春节调休"}]}
+func LoadCalendarJSON(r io.Reader) (*Calendar, error) {
+	var file struct {
+		Holidays []calendarEntry `json:"holidays"`
+		Workdays []calendarEntry `json:"workdays"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCalendarFormat, err)
+	}
+
+	calendar := NewCalendar()
+	for _, entry := range file.Holidays {
+		if err := calendar.add("holiday", entry.Date, entry.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range file.Workdays {
+		if err := calendar.add("workday", entry.Date, entry.Name); err != nil {
+			return nil, err
+		}
+	}
+	return calendar, nil
+}
+
+// LoadCalendarCSV 从CSV加载日历, 每行为 date,type[,name], type为holiday或者workday
+// 第一行为date,type,name时作为表头跳过, #开头的行为注释
+func LoadCalendarCSV(r io.Reader) (*Calendar, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	calendar := NewCalendar()
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCalendarFormat, err)
+		}
+		if line == 0 && strings.TrimSpace(record[0]) == "date" {
+			continue
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("%w: record '%s' should be date,type[,name]", ErrCalendarFormat, strings.Join(record, ","))
+		}
+		name := ""
+		if len(record) == 3 {
+			name = strings.TrimSpace(record[2])
+		}
+		if err := calendar.add(strings.TrimSpace(record[1]), record[0], name); err != nil {
+			return nil, err
+		}
+	}
+	return calendar, nil
+}
+
+// LoadCalendarFile 按扩展名(.json或者.csv)从本地文件加载日历
+func LoadCalendarFile(path string) (*Calendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadCalendarJSON(file)
+	case ".csv":
+		return LoadCalendarCSV(file)
+	}
+	return nil, fmt.Errorf("%w: unknown file extension '%s'", ErrCalendarFormat, filepath.Ext(path))
+}
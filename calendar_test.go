@@ -0,0 +1,172 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadTestCalendar 加载测试用的2024年日历
+func loadTestCalendar(t *testing.T) *Calendar {
+	calendar, err := LoadCalendarFile(filepath.Join("testdata", "calendar", "cn_2024.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return calendar
+}
+
+func TestLoadCalendar(t *testing.T) {
+	fromJSON := loadTestCalendar(t)
+	fromCSV, err := LoadCalendarFile(filepath.Join("testdata", "calendar", "cn_2024.csv"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, fromJSON, fromCSV)
+
+	testDatas := []struct {
+		day      time.Time
+		business bool
+		holiday  string
+	}{
+		{day: time.Date(2024, time.February, 4, 10, 0, 0, 0, time.Local), business: true},
+		{day: time.Date(2024, time.February, 9, 0, 0, 0, 0, time.Local), business: true},
+		{day: time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local), holiday: "春节"},
+		{day: time.Date(2024, time.February, 12, 23, 59, 59, 0, time.Local), holiday: "春节"},
+		{day: time.Date(2024, time.February, 18, 0, 0, 0, 0, time.Local), business: true},
+		{day: time.Date(2024, time.February, 24, 0, 0, 0, 0, time.Local)},
+		{day: time.Date(2024, time.October, 7, 0, 0, 0, 0, time.Local), holiday: "国庆节"},
+		{day: time.Date(2024, time.October, 8, 0, 0, 0, 0, time.Local), business: true},
+		// 日历之外的日期只按星期计算
+		{day: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local), business: true},
+	}
+	for i, data := range testDatas {
+		fmt.Printf("[%d] day:%s\n", i, data.day)
+		assert.Equal(t, data.business, fromJSON.IsBusinessDay(data.day))
+		assert.Equal(t, data.holiday != "", fromJSON.IsHoliday(data.day))
+		name, _ := fromJSON.HolidayName(data.day)
+		assert.Equal(t, data.holiday, name)
+	}
+}
+
+func TestLoadCalendar_Error(t *testing.T) {
+	testDatas := []struct {
+		json string
+		csv  string
+	}{
+		{json: `{"holidays": [{"date": "2024-02-30"}]}`},
+		{json: `{"holidays": [{"date": "2024-02-17~2024-02-10"}]}`},
+		{json: `{"holidays": [{"date": "2024-02-10"}], "workdays": [{"date": "2024-02-10"}]}`},
+		{json: `{"holiday": []}`},
+		{json: `[`},
+		{csv: "2024-02-10,vacation"},
+		{csv: "2024-02-10"},
+		{csv: "2024-02-10,holiday,春节,extra"},
+		{csv: "2024-02-10~2024-02-11~2024-02-12,holiday"},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] json:%s csv:%s\n", i, data.json, data.csv)
+		var err error
+		if data.json != "" {
+			_, err = LoadCalendarJSON(strings.NewReader(data.json))
+		} else {
+			_, err = LoadCalendarCSV(strings.NewReader(data.csv))
+		}
+		assert.True(t, errors.Is(err, ErrCalendarFormat), fmt.Sprint(err))
+	}
+
+	_, err := LoadCalendarFile(filepath.Join("testdata", "calendar", "cn_2024.txt"))
+	assert.NotNil(t, err)
+}
+
+func TestDateTimeExpression_BusinessDay(t *testing.T) {
+	_, err := NewDateTimeExpression("[*][*][bd][09:00:00-18:00:00]")
+	assert.Equal(t, ErrNoCalendar, err)
+
+	calendar := loadTestCalendar(t)
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+	}{
+		{
+			exp:   "[*][*][bd][09:00:00-18:00:00]",
+			t:     time.Date(2024, time.February, 8, 20, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 9, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 9, 18, 0, 0, 0, time.Local),
+		},
+		{
+			// 春节放假, 2024-02-18周日调休上班
+			exp:   "[*][*][bd][09:00:00-18:00:00]",
+			t:     time.Date(2024, time.February, 9, 20, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 18, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 18, 18, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][bd][09:00:00-18:00:00]",
+			t:     time.Date(2024, time.February, 4, 10, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.February, 4, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 4, 18, 0, 0, 0, time.Local),
+		},
+		{
+			// 连续的工作日合并为一个周期
+			exp:   "[2024][*][bd][*]",
+			t:     time.Date(2024, time.September, 30, 12, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.September, 29, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.October, 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp, WithCalendar(calendar))
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	expr, err := NewDateTimeExpression("[*][*][bd][09:00:00-18:00:00]", WithCalendar(calendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.ToCron(false)
+	assert.True(t, errors.Is(err, ErrCronLossy), fmt.Sprint(err))
+	_, err = expr.ToOpeningHours()
+	assert.True(t, errors.Is(err, ErrOpeningHoursLossy), fmt.Sprint(err))
+}
+
+func TestOpeningHours_Calendar(t *testing.T) {
+	calendar := loadTestCalendar(t)
+	openingHours, err := ParseOpeningHours("Mo-Fr 09:00-18:00; PH off; Sa,PH 10:00-14:00", WithCalendar(calendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-10-01是周二, 节假日按PH的规则
+	assert.False(t, openingHours.IsIn(time.Date(2024, time.October, 1, 9, 30, 0, 0, time.Local)))
+	assert.True(t, openingHours.IsIn(time.Date(2024, time.October, 1, 10, 30, 0, 0, time.Local)))
+	assert.True(t, openingHours.IsIn(time.Date(2024, time.October, 8, 9, 30, 0, 0, time.Local)))
+
+	// 没有日历时PH不命中任何日期
+	openingHours, err = ParseOpeningHours("Mo-Fr 09:00-18:00; PH off; Sa,PH 10:00-14:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, openingHours.IsIn(time.Date(2024, time.October, 1, 9, 30, 0, 0, time.Local)))
+}
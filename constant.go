@@ -19,6 +19,14 @@ var (
 	ErrOutOfDate = errors.New("expression is out of date")
 	// ErrNoEnd 没有结束范围
 	ErrNoEnd = errors.New("expression is no end time")
+	// ErrNoStart 没有开始范围
+	ErrNoStart = errors.New("expression is no start time")
+	// ErrNeverMatch 表达式永远不会命中
+	ErrNeverMatch = errors.New("expression never matches")
+	// ErrBeyondScanHorizon 逐天扫描的表达式在扫描的范围(没有年的限制时为8年)内没有命中, 不代表永远不会命中
+	ErrBeyondScanHorizon = errors.New("expression does not match within the scan horizon")
+	// ErrDayStartFormat 一天开始的时间不对
+	ErrDayStartFormat = errors.New("day start format not math")
 )
 
 const (
@@ -0,0 +1,477 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCronFormat cron表达式格式不对
+	ErrCronFormat = errors.New("cron format not math")
+	// ErrCronUnsupported cron表达式中有无法转换为时间表达式的部分
+	ErrCronUnsupported = errors.New("cron feature is unsupported")
+	// ErrCronLossy 时间表达式转换为cron会丢失信息
+	ErrCronLossy = errors.New("conversion to cron is lossy")
+)
+
+// cronMacros cron的预定义宏
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronMonthNames cron月份字段支持的名字
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// cronWeekdayNames cron星期字段支持的名字, 0为周日
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// triggerPlan 在每个周期开始时触发的规则, 由ToCron, ToQuartz, ToOnCalendar格式化为各自的语法
+type triggerPlan struct {
+	month  *monthExpression
+	day    *dayExpression
+	starts []hourUnit
+}
+
+// triggerPlan 计算每个周期开始的日期和时分秒, 无法只用开始时间表达周期时返回lossy错误
+func (expression *DateTimeExpression) triggerPlan(lossy error) (*triggerPlan, error) {
+	if expression.alwaysActive {
+		return nil, fmt.Errorf("%w: expression is always active, no window start", lossy)
+	}
+	if expression.dayStart != 0 {
+		return nil, fmt.Errorf("%w: day does not start at 00:00:00", lossy)
+	}
+	switch expression.day.kind {
+	case dayKindMonthDay, dayKindWeekday, dayKindNthWeekday, dayKindLast, dayKindLastWeekday, dayKindNearestWeekday:
+		// 由各自的格式决定能否表达
+	case dayKindBusinessDay:
+		return nil, fmt.Errorf("%w: business days depend on the calendar", lossy)
+	case dayKindSolarTerm:
+		return nil, fmt.Errorf("%w: solar term %s", lossy, expression.day)
+	case dayKindDayOfYear:
+		return nil, fmt.Errorf("%w: day of year %s", lossy, expression.day)
+	default:
+		return nil, fmt.Errorf("%w: day %s", lossy, expression.day)
+	}
+	switch expression.month.kind {
+	case monthKindMonth, monthKindQuarter:
+	case monthKindLunar, monthKindLunarLeap:
+		return nil, fmt.Errorf("%w: lunar month %s", lossy, expression.month)
+	case monthKindWeekOfYear:
+		return nil, fmt.Errorf("%w: ISO week %s", lossy, expression.month)
+	case monthKindFiscal, monthKindFiscalQuarter:
+		return nil, fmt.Errorf("%w: fiscal period %s", lossy, expression.month)
+	default:
+		return nil, fmt.Errorf("%w: month %s", lossy, expression.month)
+	}
+	if expression.hour.hasSun {
+		return nil, fmt.Errorf("%w: sunrise and sunset change every day", lossy)
+	}
+
+	plan := &triggerPlan{month: expression.month, day: expression.day}
+	if expression.hour.isAll {
+		// 整天的周期, 只在连续的天的第一天触发
+		switch {
+		case expression.day.isPositional():
+			// 只有一天, 不会和其他天相连
+		case expression.day.kind == dayKindWeekday:
+			if !expression.month.isAll && expression.day.start != expression.day.end {
+				return nil, fmt.Errorf("%w: weekday period may start at the beginning of month", lossy)
+			}
+			plan.day = &dayExpression{start: expression.day.start, end: expression.day.start, kind: dayKindWeekday}
+		case !expression.day.isAll:
+			if expression.day.start == 1 && expression.day.end >= 28 {
+				return nil, fmt.Errorf("%w: day %s merges with the next month", lossy, expression.day)
+			}
+			plan.day = &dayExpression{start: expression.day.start, end: expression.day.start}
+		default:
+			if expression.month.start == 1 && expression.month.end == 12 {
+				return nil, fmt.Errorf("%w: month %s merges with the next year", lossy, expression.month)
+			}
+			plan.day = &dayExpression{start: 1, end: 1}
+			plan.month = &monthExpression{start: expression.month.start, end: expression.month.start}
+		}
+		plan.starts = []hourUnit{{}}
+		return plan, nil
+	}
+
+	startsAtMidnight, endsAtMidnight := false, false
+	for _, unit := range expression.hour.hourUnits {
+		if unit.start.Nsec != 0 {
+			return nil, fmt.Errorf("%w: start time %s has fractional seconds", lossy, unit.start.String())
+		}
+		startsAtMidnight = startsAtMidnight || unit.start.toSec() == 0
+		endsAtMidnight = endsAtMidnight || unit.end.Hour == 24
+		plan.starts = append(plan.starts, unit.start)
+	}
+	if startsAtMidnight && endsAtMidnight {
+		return nil, fmt.Errorf("%w: hour %s merges with the next day", lossy, expression.hour)
+	}
+	return plan, nil
+}
+
+// ToCron 转换为在每个周期开始时触发的cron表达式
+// withSeconds为true时生成6段的(秒 分 时 日 月 星期), 否则生成5段的(分 时 日 月 星期)
+// 开始时间不同的时间段可能会生成多条cron表达式
+// cron没有年, 也无法表达秒级的开始时间(5段时)和L, W, #, 这些情况返回ErrCronLossy
+// 整天的周期在cron中只剩下00:00的触发时间, 无法表达持续一天或多天, 也返回ErrCronLossy
+func (expression *DateTimeExpression) ToCron(withSeconds bool) ([]string, error) {
+	if !expression.alwaysActive && !expression.year.isAll {
+		return nil, fmt.Errorf("%w: year %s cannot be represented in cron", ErrCronLossy, expression.year)
+	}
+	if !expression.alwaysActive && expression.hour.isAll {
+		return nil, fmt.Errorf("%w: whole-day window %s has no duration in cron", ErrCronLossy, expression.day)
+	}
+	plan, err := expression.triggerPlan(ErrCronLossy)
+	if err != nil {
+		return nil, err
+	}
+	monthField := formatCronRange(plan.month.start, plan.month.end, plan.month.isAll)
+	dayField := "*"
+	weekdayField := "*"
+	switch plan.day.kind {
+	case dayKindWeekday:
+		weekdayField = formatCronWeekdays(plan.day.start, plan.day.end)
+	case dayKindMonthDay:
+		dayField = formatCronRange(plan.day.start, plan.day.end, plan.day.isAll)
+	default:
+		return nil, fmt.Errorf("%w: day %s cannot be represented in cron", ErrCronLossy, plan.day)
+	}
+
+	if !withSeconds {
+		for _, start := range plan.starts {
+			if start.Sec != 0 {
+				return nil, fmt.Errorf("%w: start time %s has seconds", ErrCronLossy, start.String())
+			}
+		}
+	}
+
+	var specs []string
+	for _, group := range groupCronStarts(plan.starts) {
+		fields := []string{formatCronList(group.minutes, 0, 59), formatCronList(group.hours, 0, 23), dayField, monthField, weekdayField}
+		if withSeconds {
+			fields = append([]string{strconv.Itoa(group.sec)}, fields...)
+		}
+		specs = append(specs, strings.Join(fields, " "))
+	}
+
+	return specs, nil
+}
+
+// cronStartGroup 秒相同且小时集合相同的开始时间可以合并为一条cron
+type cronStartGroup struct {
+	sec     int
+	minutes []int
+	hours   []int
+}
+
+// groupCronStarts 合并开始时间, 先按秒和分合并小时, 再把小时相同的分钟合并
+func groupCronStarts(starts []hourUnit) []cronStartGroup {
+	type secMinute struct{ sec, minute int }
+	hoursBySecMinute := map[secMinute][]int{}
+	var keys []secMinute
+	for _, start := range starts {
+		key := secMinute{start.Sec, start.Minute}
+		if _, ok := hoursBySecMinute[key]; !ok {
+			keys = append(keys, key)
+		}
+		hoursBySecMinute[key] = append(hoursBySecMinute[key], start.Hour)
+	}
+
+	type secHours struct {
+		sec   int
+		hours string
+	}
+	var groups []cronStartGroup
+	groupIndex := map[secHours]int{}
+	for _, key := range keys {
+		hours := hoursBySecMinute[key]
+		sort.Ints(hours)
+		groupKey := secHours{key.sec, fmt.Sprint(hours)}
+		idx, ok := groupIndex[groupKey]
+		if !ok {
+			idx = len(groups)
+			groupIndex[groupKey] = idx
+			groups = append(groups, cronStartGroup{sec: key.sec, hours: hours})
+		}
+		groups[idx].minutes = append(groups[idx].minutes, key.minute)
+	}
+	for i := range groups {
+		sort.Ints(groups[i].minutes)
+	}
+	return groups
+}
+
+// formatCronRange 格式化为 *, a, a-b
+func formatCronRange(start int, end int, isAll bool) string {
+	if isAll {
+		return "*"
+	}
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// formatCronWeekdays 把星期几(1为周一, 7为周日)转换为cron的星期字段(0为周日)
+func formatCronWeekdays(start int, end int) string {
+	if end < 7 {
+		return formatCronRange(start, end, false)
+	}
+	if start == 7 {
+		return "0"
+	}
+	return formatCronRange(start, 6, false) + ",0"
+}
+
+// formatCronList 把数字列表格式化为 *, 或者逗号分隔的数字和范围
+func formatCronList(values []int, min int, max int) string {
+	sort.Ints(values)
+	if len(values) == max-min+1 {
+		return "*"
+	}
+
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		items = append(items, formatCronRange(values[i], values[j], false))
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// ParseCron 把5段(分 时 日 月 星期)或者6段(秒 分 时 日 月 星期)的cron表达式转换为时间表达式
+// 每次触发转换为1秒的周期, GetStartTime/GetNextStartTime 即为cron的触发时间
+// 日和月只支持*,a,a-b, 星期只支持连续的范围, 同时配置日和星期(cron中为"或"的关系)是不支持的
+func ParseCron(spec string) (*DateTimeExpression, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expect 5 or 6 fields, got %d", ErrCronFormat, len(fields))
+	}
+
+	seconds, err := parseCronList(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := parseCronList(fields[1], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := parseCronList(fields[2], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	day, err := parseCronDay(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	month, err := parseCronMonth(fields[4])
+	if err != nil {
+		return nil, err
+	}
+	weekday, err := parseCronWeekday(fields[5])
+	if err != nil {
+		return nil, err
+	}
+	if weekday != "*" {
+		if day != "*" {
+			return nil, fmt.Errorf("%w: both day of month and day of week are restricted", ErrCronUnsupported)
+		}
+		day = weekday
+	}
+
+	units, err := cronFiringUnits(seconds, minutes, hours, ErrCronUnsupported)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDateTimeExpression("[*][" + month + "][" + day + "][" + units + "]")
+}
+
+// cronFiringUnits 把每次触发转换为1秒的时间段, 返回时间表达式中时分秒的格式
+// 无法区分每次触发时返回unsupported错误
+func cronFiringUnits(seconds []int, minutes []int, hours []int, unsupported error) (string, error) {
+	var startSecs []int
+	for _, hour := range hours {
+		for _, minute := range minutes {
+			for _, sec := range seconds {
+				start := hourUnit{Hour: hour, Minute: minute, Sec: sec}
+				startSecs = append(startSecs, start.toSec())
+			}
+		}
+	}
+	sort.Ints(startSecs)
+
+	var units []string
+	for i, startSec := range startSecs {
+		// 每次触发为1秒的周期, 连续的秒会首尾相接, 无法区分每次的触发
+		if i > 0 && startSec == startSecs[i-1]+1 {
+			return "", fmt.Errorf("%w: firing on consecutive seconds", unsupported)
+		}
+		endSec := startSec + 1
+		units = append(units, fmt.Sprintf("%02d:%02d:%02d-%02d:%02d:%02d",
+			startSec/3600, startSec%3600/60, startSec%60, endSec/3600, endSec%3600/60, endSec%60))
+	}
+	return strings.Join(units, ","), nil
+}
+
+// parseCronList 解析cron字段中的 *, a, a-b, */n, a-b/n 和逗号分隔的列表, 返回排序后的数字
+func parseCronList(field string, min int, max int, names map[string]int) ([]int, error) {
+	set := map[int]bool{}
+	for _, item := range strings.Split(field, ",") {
+		step, hasStep := 1, false
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			hasStep = true
+			var err error
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("%w: invalid step '%s'", ErrCronFormat, item)
+			}
+			item = item[:idx]
+		}
+
+		start, end := min, max
+		if item != "*" && item != "?" {
+			bounds := strings.Split(item, "-")
+			if len(bounds) > 2 {
+				return nil, fmt.Errorf("%w: invalid range '%s'", ErrCronFormat, item)
+			}
+			var err error
+			start, err = parseCronValue(bounds[0], min, max, names)
+			if err != nil {
+				return nil, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = parseCronValue(bounds[1], min, max, names)
+				if err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				// a/n 表示从a开始到最大值
+				end = max
+			}
+			if start > end {
+				return nil, fmt.Errorf("%w: range '%s' start after end", ErrCronFormat, item)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			set[value] = true
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToUpper(value)]; ok {
+		return named, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, fmt.Errorf("%w: value '%s' out of range %d-%d", ErrCronFormat, value, min, max)
+	}
+	return number, nil
+}
+
+// parseCronContinuous 解析只能是连续范围的cron字段, 返回时间表达式中的格式
+func parseCronContinuous(field string, min int, max int, names map[string]int, layout string) (string, error) {
+	if field == "*" || field == "?" {
+		return "*", nil
+	}
+	values, err := parseCronList(field, min, max, names)
+	if err != nil {
+		return "", err
+	}
+	if values[len(values)-1]-values[0]+1 != len(values) {
+		return "", fmt.Errorf("%w: '%s' is not a continuous range", ErrCronUnsupported, field)
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf(layout, values[0]), nil
+	}
+	return fmt.Sprintf(layout+"-"+layout, values[0], values[len(values)-1]), nil
+}
+
+func parseCronDay(field string) (string, error) {
+	return parseCronContinuous(field, 1, 31, nil, "%02d")
+}
+
+func parseCronMonth(field string) (string, error) {
+	return parseCronContinuous(field, 1, 12, cronMonthNames, "%02d")
+}
+
+// parseCronWeekday 解析cron的星期字段(0和7都为周日), 返回w1-7格式
+func parseCronWeekday(field string) (string, error) {
+	if field == "*" || field == "?" {
+		return "*", nil
+	}
+	values, err := parseCronList(field, 0, 7, cronWeekdayNames)
+	if err != nil {
+		return "", err
+	}
+
+	// 转换为1为周一, 7为周日
+	weekdays := make([]int, 0, len(values))
+	for _, value := range values {
+		if value == 0 {
+			value = 7
+		}
+		weekdays = append(weekdays, value)
+	}
+	return formatWeekdayField(weekdays, field, ErrCronUnsupported)
+}
+
+// formatWeekdayField 把星期几(1为周一, 7为周日)的列表格式化为时间表达式中的w1-7格式
+// 不是连续的范围时返回unsupported错误
+func formatWeekdayField(values []int, field string, unsupported error) (string, error) {
+	set := map[int]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	weekdays := make([]int, 0, len(set))
+	for weekday := range set {
+		weekdays = append(weekdays, weekday)
+	}
+	sort.Ints(weekdays)
+	if weekdays[len(weekdays)-1]-weekdays[0]+1 != len(weekdays) {
+		return "", fmt.Errorf("%w: weekday '%s' is not a continuous range", unsupported, field)
+	}
+	if len(weekdays) == 7 {
+		return "*", nil
+	}
+	if len(weekdays) == 1 {
+		return fmt.Sprintf("w%d", weekdays[0]), nil
+	}
+	return fmt.Sprintf("w%d-%d", weekdays[0], weekdays[len(weekdays)-1]), nil
+}
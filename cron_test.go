@@ -0,0 +1,194 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDateTimeExpression_ToCron(t *testing.T) {
+	testDatas := []struct {
+		exp         string
+		withSeconds bool
+		err         error
+		specs       []string
+	}{
+		{
+			exp:   "[*][*][*][20:00:00-22:00:00]",
+			specs: []string{"0 20 * * *"},
+		},
+		{
+			exp:         "[*][02-04][05-07][08:00:00-10:00:00,11:30:00-12:30:30,20:00:00-21:00:00]",
+			withSeconds: true,
+			specs:       []string{"0 0 8,20 5-7 2-4 *", "0 30 11 5-7 2-4 *"},
+		},
+		{
+			exp:   "[*][*][w1-5][09:00:00-12:00:00,13:30:00-18:00:00]",
+			specs: []string{"0 9 * * 1-5", "30 13 * * 1-5"},
+		},
+		{
+			exp:   "[*][*][w6-7][18:00:00-22:00:00]",
+			specs: []string{"0 18 * * 6,0"},
+		},
+		{
+			// 整天的周期无法表达持续时间
+			exp: "[*][*][w1][*]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][w6-7][*]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][05-07][*]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][03-04][*][*]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[2024][*][*][20:00:00-22:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][L][20:00:00-22:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][*][20:00:30-22:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp:         "[*][*][*][20:00:30-22:00:00]",
+			withSeconds: true,
+			specs:       []string{"30 0 20 * * *"},
+		},
+		{
+			exp: "[*][*][*][00:00:00-02:00:00,22:00:00-24:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][*][*]",
+			err: ErrCronLossy,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		specs, err := expr.ToCron(data.withSeconds)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), "%v", err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.specs, specs)
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	testDatas := []struct {
+		spec  string
+		err   error
+		input time.Time
+		next  []time.Time
+		cron  string
+	}{
+		{
+			spec:  "30 20 * * *",
+			input: time.Date(2024, time.March, 1, 21, 0, 0, 0, time.Local),
+			next: []time.Time{
+				time.Date(2024, time.March, 2, 20, 30, 0, 0, time.Local),
+				time.Date(2024, time.March, 3, 20, 30, 0, 0, time.Local),
+			},
+			cron: "30 20 * * *",
+		},
+		{
+			spec:  "*/15 9-10 * * MON-FRI",
+			input: time.Date(2024, time.March, 8, 10, 50, 0, 0, time.Local),
+			next: []time.Time{
+				time.Date(2024, time.March, 11, 9, 0, 0, 0, time.Local),
+				time.Date(2024, time.March, 11, 9, 15, 0, 0, time.Local),
+			},
+			cron: "0,15,30,45 9-10 * * 1-5",
+		},
+		{
+			spec:  "10 0 12 1 JAN-MAR *",
+			input: time.Date(2024, time.January, 1, 12, 0, 10, 0, time.Local),
+			next: []time.Time{
+				time.Date(2024, time.February, 1, 12, 0, 10, 0, time.Local),
+				time.Date(2024, time.March, 1, 12, 0, 10, 0, time.Local),
+				time.Date(2025, time.January, 1, 12, 0, 10, 0, time.Local),
+			},
+		},
+		{
+			spec:  "@daily",
+			input: time.Date(2024, time.January, 1, 12, 0, 10, 0, time.Local),
+			next: []time.Time{
+				time.Date(2024, time.January, 2, 0, 0, 0, 0, time.Local),
+			},
+			cron: "0 0 * * *",
+		},
+		{
+			spec:  "0 22 * * 6,0",
+			input: time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local),
+			next: []time.Time{
+				time.Date(2024, time.March, 9, 22, 0, 0, 0, time.Local),
+				time.Date(2024, time.March, 10, 22, 0, 0, 0, time.Local),
+			},
+			cron: "0 22 * * 6,0",
+		},
+		{
+			spec: "0 0 1,15 * *",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 1 * 1",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "* * * * * *",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 * *",
+			err:  ErrCronFormat,
+		},
+		{
+			spec: "0 25 * * *",
+			err:  ErrCronFormat,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] spec:%s\n", i, data.spec)
+		expr, err := ParseCron(data.spec)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), "%v", err)
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+
+		input := data.input
+		for _, next := range data.next {
+			input, err = expr.GetNextStartTime(input)
+			assert.Nil(t, err)
+			assert.Equal(t, next, input)
+		}
+
+		if data.cron != "" {
+			specs, err := expr.ToCron(false)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{data.cron}, specs)
+		}
+	}
+}
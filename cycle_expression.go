@@ -0,0 +1,246 @@
+package timeexpression
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCycleFormat 周期表达式格式不对
+var ErrCycleFormat = errors.New("cycle format not math")
+
+// cycleDurationRegex 周期和持续时间, etc: 14d, 2w, 2h30m, 90s
+var cycleDurationRegex = regexp.MustCompile(`^(?:(\d+)w)?(?:(\d+)d)?(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?$`)
+
+// cycleAnchorLayouts 锚点时间支持的格式
+var cycleAnchorLayouts = []string{"2006-01-02 15:04:05", "2006-01-02"}
+
+// CycleExpression 从锚点时间开始, 每隔固定的时间重复一次的表达式, 例如每14天一次, 每次持续2小时
+// 第k个周期为[anchor+k*period, anchor+k*period+active), 锚点之前没有周期
+// 年的字段用于限定范围, 开始时间在范围内的周期才有效
+type CycleExpression struct {
+	year   *yearExpression
+	anchor time.Time
+	// period 两次周期开始的间隔, 周和天按日历计算, 时分秒按实际时长计算
+	period *isoPeriod
+	// active 每次周期持续的时间
+	active *isoPeriod
+
+	// first, last 年的范围内第一个和最后一个周期, 没有结束年时last为-1
+	first int
+	last  int
+}
+
+var _ Expression = (*CycleExpression)(nil)
+
+// NewCycleExpression 创建周期表达式, 格式为 [*,yyyy,yyyy-yyyy,yyyy-,-yyyy][ryyyy-MM-dd hh:mm:ss/period/active]
+// period和active由w(周), d(天), h(时), m(分), s(秒)组成, 例如: [*][r2024-03-04 20:00:00/14d/2h]
+func NewCycleExpression(expression string) (*CycleExpression, error) {
+	// 去掉最前的'['和最后的']'
+	expression = strings.TrimPrefix(expression, "[")
+	expression = strings.TrimSuffix(expression, "]")
+
+	expSplits := strings.Split(expression, "][")
+	if len(expSplits) != 2 {
+		return nil, ErrCycleFormat
+	}
+
+	cycleExpression := &CycleExpression{}
+	var err error
+	// 解析年
+	cycleExpression.year, err = newYearExpression(expSplits[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析锚点, 周期和持续时间
+	cycleStr := strings.Trim(expSplits[1], " ")
+	if !strings.HasPrefix(cycleStr, "r") {
+		return nil, ErrCycleFormat
+	}
+	cycleSplits := strings.Split(cycleStr[1:], "/")
+	if len(cycleSplits) != 3 {
+		return nil, ErrCycleFormat
+	}
+	for _, layout := range cycleAnchorLayouts {
+		if cycleExpression.anchor, err = time.ParseInLocation(layout, cycleSplits[0], time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, ErrCycleFormat
+	}
+	if cycleExpression.period, err = parseCycleDuration(cycleSplits[1]); err != nil {
+		return nil, err
+	}
+	if cycleExpression.active, err = parseCycleDuration(cycleSplits[2]); err != nil {
+		return nil, err
+	}
+	// 持续时间不能超过周期, 否则周期之间会重叠
+	if cycleExpression.active.addTo(cycleExpression.anchor, 1).After(cycleExpression.startOf(1)) {
+		return nil, ErrCycleFormat
+	}
+
+	cycleExpression.first, cycleExpression.last = cycleExpression.bounds()
+	return cycleExpression, nil
+}
+
+// parseCycleDuration 解析周期或持续时间, 不能为0
+func parseCycleDuration(expression string) (*isoPeriod, error) {
+	matches := cycleDurationRegex.FindStringSubmatch(expression)
+	if matches == nil || expression == "" {
+		return nil, ErrCycleFormat
+	}
+	values := make([]int, len(matches)-1)
+	total := 0
+	for i, match := range matches[1:] {
+		if match != "" {
+			values[i], _ = strconv.Atoi(match)
+			total += values[i]
+		}
+	}
+	if total == 0 {
+		return nil, ErrCycleFormat
+	}
+	return &isoPeriod{weeks: values[0], days: values[1], hours: values[2], minutes: values[3], seconds: values[4]}, nil
+}
+
+// formatCycleDuration 格式化周期或持续时间, 为0的部分省略
+func formatCycleDuration(period *isoPeriod) string {
+	var builder strings.Builder
+	for _, item := range []struct {
+		value int
+		unit  string
+	}{{period.weeks, "w"}, {period.days, "d"}, {period.hours, "h"}, {period.minutes, "m"}, {period.seconds, "s"}} {
+		if item.value > 0 {
+			builder.WriteString(strconv.Itoa(item.value) + item.unit)
+		}
+	}
+	return builder.String()
+}
+
+// startOf 第k个周期的开始时间
+func (expression *CycleExpression) startOf(k int) time.Time {
+	return expression.period.addTo(expression.anchor, k)
+}
+
+// endOf 第k个周期的结束时间
+func (expression *CycleExpression) endOf(k int) time.Time {
+	return expression.active.addTo(expression.startOf(k), 1)
+}
+
+// bounds 计算年的范围内第一个和最后一个周期, 没有结束年时last为-1, 没有周期时first大于last
+func (expression *CycleExpression) bounds() (first int, last int) {
+	if expression.year.hasStart {
+		yearStart := time.Date(expression.year.start, time.January, 1, 0, 0, 0, 0, time.Local)
+		if yearStart.After(expression.anchor) {
+			first = periodIndex(yearStart, expression.startOf)
+			if expression.startOf(first).Before(yearStart) {
+				first++
+			}
+		}
+	}
+	if !expression.year.hasEnd {
+		return first, -1
+	}
+	yearEnd := time.Date(expression.year.end+1, time.January, 1, 0, 0, 0, 0, time.Local)
+	if !yearEnd.After(expression.anchor) {
+		return first, first - 1
+	}
+	last = periodIndex(yearEnd, expression.startOf)
+	if !expression.startOf(last).Before(yearEnd) {
+		last--
+	}
+	return first, last
+}
+
+// neverMatch 年的范围内是否没有周期
+func (expression *CycleExpression) neverMatch() bool {
+	return expression.year.hasEnd && expression.first > expression.last
+}
+
+// current 获取包含时间t的周期, 不在周期内时获取下一个周期, ok为false表示之后没有周期
+func (expression *CycleExpression) current(t time.Time) (k int, ok bool) {
+	k = expression.first
+	if !t.Before(expression.startOf(k)) {
+		k = periodIndex(t, expression.startOf)
+		if !t.Before(expression.endOf(k)) {
+			k++
+		}
+	}
+	if expression.year.hasEnd && k > expression.last {
+		return 0, false
+	}
+	return k, true
+}
+
+// IsIn 判断时间是否在表达式指定范围内
+func (expression *CycleExpression) IsIn(t time.Time) bool {
+	k, ok := expression.current(t)
+	return ok && !t.Before(expression.startOf(k))
+}
+
+// GetStartTime 获取开始时间, 在周期内返回本次的开始时间, 否则返回下次的开始时间
+func (expression *CycleExpression) GetStartTime(t time.Time) (time.Time, error) {
+	k, ok := expression.current(t)
+	if !ok {
+		return time.Time{}, ErrOutOfDate
+	}
+	return expression.startOf(k), nil
+}
+
+// GetEndTime 获取结束时间, 在周期内返回本次的结束时间, 否则返回下次的结束时间
+func (expression *CycleExpression) GetEndTime(t time.Time) (time.Time, error) {
+	k, ok := expression.current(t)
+	if !ok {
+		return time.Time{}, ErrOutOfDate
+	}
+	return expression.endOf(k), nil
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在周期内，都获取下次的时间
+func (expression *CycleExpression) GetNextStartTime(t time.Time) (time.Time, error) {
+	return nextStartTime(expression, t)
+}
+
+// FirstStartTime 获取第一个周期的开始时间
+func (expression *CycleExpression) FirstStartTime() (time.Time, error) {
+	if expression.neverMatch() {
+		return time.Time{}, ErrNeverMatch
+	}
+	return expression.startOf(expression.first), nil
+}
+
+// FinalEndTime 获取最后一个周期的结束时间
+func (expression *CycleExpression) FinalEndTime() (time.Time, error) {
+	if !expression.year.hasEnd {
+		return time.Time{}, ErrNoEnd
+	}
+	if expression.neverMatch() {
+		return time.Time{}, ErrNeverMatch
+	}
+	return expression.endOf(expression.last), nil
+}
+
+// IsExpired 判断是否已经过了最后一个周期
+func (expression *CycleExpression) IsExpired(t time.Time) bool {
+	if !expression.year.hasEnd {
+		return false
+	}
+
+	finalEnd, err := expression.FinalEndTime()
+	if err != nil {
+		// 永远不会命中
+		return true
+	}
+
+	return !t.Before(finalEnd)
+}
+
+// String 格式化为标准的表达式 [*,yyyy,yyyy-yyyy,yyyy-,-yyyy][ryyyy-MM-dd hh:mm:ss/period/active]
+func (expression *CycleExpression) String() string {
+	return "[" + expression.year.String() + "][r" + expression.anchor.Format("2006-01-02 15:04:05") + "/" +
+		formatCycleDuration(expression.period) + "/" + formatCycleDuration(expression.active) + "]"
+}
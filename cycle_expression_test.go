@@ -0,0 +1,213 @@
+package timeexpression
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewCycleExpression(t *testing.T) {
+	testDatas := []struct {
+		exp string
+		// formatted String()的结果, 为空时和exp相同
+		formatted string
+		err       error
+	}{
+		{exp: "[*][r2024-03-04 20:00:00/14d/2h]"},
+		{exp: "[2024-2025][r2024-03-04 20:00:00/2w/1d]"},
+		{exp: "[*][r2024-03-04/1d12h/12h]", formatted: "[*][r2024-03-04 00:00:00/1d12h/12h]"},
+		{exp: "[*][r2024-03-04 08:00:00/90m/90m]"},
+		{exp: "[*][r2024-03-04 08:00:00/1w/30s]"},
+
+		{exp: "[*][2024-03-04 20:00:00/14d/2h]", err: ErrCycleFormat},
+		{exp: "[*][r2024-03-04 20:00:00/14d]", err: ErrCycleFormat},
+		{exp: "[*][r2024-03-04 20:00:00/2h/3h]", err: ErrCycleFormat},
+		{exp: "[*][r2024-03-04 20:00:00/0d/2h]", err: ErrCycleFormat},
+		{exp: "[*][r2024-03-04 20:00:00/1d/]", err: ErrCycleFormat},
+		{exp: "[*][r2024-03-04 20:00:00/1x/1h]", err: ErrCycleFormat},
+		{exp: "[*][r2024-13-04/1d/1h]", err: ErrCycleFormat},
+		{exp: "[*][*][r2024-03-04/1d/1h]", err: ErrCycleFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewCycleExpression(data.exp)
+		if data.err != nil {
+			assert.Equal(t, data.err, err)
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.exp
+		}
+		assert.Equal(t, formatted, expr.String())
+	}
+}
+
+func TestCycleExpression(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		now   time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		next  time.Time
+		err   error
+	}{
+		{
+			// 锚点之前
+			exp:   "[*][r2024-03-04 20:00:00/14d/2h]",
+			now:   time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 4, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 4, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 4, 20, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][r2024-03-04 20:00:00/14d/2h]",
+			now:   time.Date(2024, time.March, 18, 21, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 18, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 18, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.April, 1, 20, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][r2024-03-04 20:00:00/14d/2h]",
+			now:   time.Date(2024, time.March, 18, 22, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.April, 1, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.April, 1, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.April, 1, 20, 0, 0, 0, time.Local),
+		},
+		{
+			// 按天计算的周期保持当地的时间
+			exp:   "[*][r2024-03-01 20:00:00/1d/2h]",
+			now:   time.Date(2024, time.November, 3, 21, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.November, 3, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.November, 3, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.November, 4, 20, 0, 0, 0, time.Local),
+		},
+		{
+			// 年的范围内最后一个周期为2024-12-23
+			exp:   "[2024][r2024-03-04 20:00:00/14d/2h]",
+			now:   time.Date(2024, time.December, 23, 21, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.December, 23, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.December, 23, 22, 0, 0, 0, time.Local),
+			err:   ErrOutOfDate,
+		},
+		{
+			// 年的范围内第一个周期为2025-01-06
+			exp:   "[2025][r2024-03-04 20:00:00/14d/2h]",
+			now:   time.Date(2024, time.December, 23, 21, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.January, 6, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 6, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2025, time.January, 6, 20, 0, 0, 0, time.Local),
+		},
+		{
+			exp: "[2023][r2024-03-04 20:00:00/1w/2h]",
+			now: time.Date(2023, time.March, 1, 0, 0, 0, 0, time.Local),
+			err: ErrOutOfDate,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s now:%s\n", i, data.exp, data.now)
+		expr, err := NewCycleExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.isIn, expr.IsIn(data.now))
+
+		if !data.start.IsZero() {
+			start, err := expr.GetStartTime(data.now)
+			assert.Nil(t, err)
+			assert.Equal(t, data.start, start)
+			end, err := expr.GetEndTime(data.now)
+			assert.Nil(t, err)
+			assert.Equal(t, data.end, end)
+		}
+
+		next, err := expr.GetNextStartTime(data.now)
+		if data.err != nil {
+			assert.Equal(t, data.err, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.next, next)
+	}
+}
+
+func TestCycleExpression_FirstFinal(t *testing.T) {
+	testDatas := []struct {
+		exp      string
+		first    time.Time
+		firstErr error
+		final    time.Time
+		finalErr error
+		now      time.Time
+		expired  bool
+	}{
+		{
+			exp:      "[*][r2024-03-04 20:00:00/14d/2h]",
+			first:    time.Date(2024, time.March, 4, 20, 0, 0, 0, time.Local),
+			finalErr: ErrNoEnd,
+			now:      time.Date(2030, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:     "[2024][r2024-03-04 20:00:00/14d/2h]",
+			first:   time.Date(2024, time.March, 4, 20, 0, 0, 0, time.Local),
+			final:   time.Date(2024, time.December, 23, 22, 0, 0, 0, time.Local),
+			now:     time.Date(2024, time.December, 24, 0, 0, 0, 0, time.Local),
+			expired: true,
+		},
+		{
+			exp:   "[2025-2026][r2024-03-04 20:00:00/14d/2h]",
+			first: time.Date(2025, time.January, 6, 20, 0, 0, 0, time.Local),
+			final: time.Date(2026, time.December, 21, 22, 0, 0, 0, time.Local),
+			now:   time.Date(2026, time.December, 21, 21, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[2025-][r2024-03-04 20:00:00/14d/2h]",
+			first:    time.Date(2025, time.January, 6, 20, 0, 0, 0, time.Local),
+			finalErr: ErrNoEnd,
+			now:      time.Date(2030, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:     "[-2024][r2024-03-04 20:00:00/14d/2h]",
+			first:   time.Date(2024, time.March, 4, 20, 0, 0, 0, time.Local),
+			final:   time.Date(2024, time.December, 23, 22, 0, 0, 0, time.Local),
+			now:     time.Date(2024, time.December, 24, 0, 0, 0, 0, time.Local),
+			expired: true,
+		},
+		{
+			exp:      "[2023][r2024-03-04 20:00:00/1w/2h]",
+			firstErr: ErrNeverMatch,
+			finalErr: ErrNeverMatch,
+			now:      time.Date(2023, time.March, 1, 0, 0, 0, 0, time.Local),
+			expired:  true,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewCycleExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		first, err := expr.FirstStartTime()
+		assert.Equal(t, data.firstErr, err)
+		if data.firstErr == nil {
+			assert.Equal(t, data.first, first)
+		}
+		final, err := expr.FinalEndTime()
+		assert.Equal(t, data.finalErr, err)
+		if data.finalErr == nil {
+			assert.Equal(t, data.final, final)
+		}
+		assert.Equal(t, data.expired, expr.IsExpired(data.now))
+	}
+}
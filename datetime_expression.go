@@ -1,6 +1,7 @@
 package timeexpression
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -13,12 +14,28 @@ type DateTimeExpression struct {
 
 	alwaysActive bool // 表示该表达式是否永远有效
 	hasEnd       bool // 表示是否会结束
+
+	// dayStart 逻辑上一天开始的时间, 距离0点的秒数, 不为0时hour为距离一天开始的时间
+	dayStart int
+	// clockHour 钟表上的时分秒, 用于格式化
+	clockHour *hourExpression
+	// location 观测者的位置, 时分秒使用日出日落时不为nil
+	location *GeoLocation
+	// fiscal 财年, 月份为财年的期时不为nil
+	fiscal *FiscalCalendar
 }
 
-// 时间表达式为[*,yyyy,yyyy-yyyy][*,mm,mm-mm][*,dd,dd-dd][*,h1-h2]
-func NewDateTimeExpression(expression string) (*DateTimeExpression, error) {
+// 时间表达式为[*,yyyy,yyyy-yyyy,yyyy-,-yyyy][*,mm,mm-mm][*,dd,dd-dd][*,h1-h2]
+// 可以使用RegisterMacro注册的宏, etc: [2024][*][@weekend][@primetime]
+func NewDateTimeExpression(expression string, opts ...Option) (*DateTimeExpression, error) {
 	dateTimeExpression := &DateTimeExpression{}
 
+	// 先展开宏
+	expression, err := expandMacros(expression)
+	if err != nil {
+		return nil, err
+	}
+
 	// 去掉最前的'['和最后的']'
 	expression = strings.TrimPrefix(expression, "[")
 	expression = strings.TrimSuffix(expression, "]")
@@ -28,7 +45,6 @@ func NewDateTimeExpression(expression string) (*DateTimeExpression, error) {
 		return nil, ErrDateTimeFormat
 	}
 
-	var err error
 	// 解析年
 	dateTimeExpression.year, err = newYearExpression(expSplits[0])
 	if err != nil {
@@ -50,16 +66,64 @@ func NewDateTimeExpression(expression string) (*DateTimeExpression, error) {
 		return nil, err
 	}
 
+	switch dateTimeExpression.month.kind {
+	case monthKindLunar, monthKindLunarLeap:
+		err = dateTimeExpression.checkLunar()
+	case monthKindWeekOfYear:
+		err = dateTimeExpression.checkWeekOfYear()
+	case monthKindFiscal, monthKindFiscalQuarter:
+		err = dateTimeExpression.checkFiscal()
+	}
+	if err != nil {
+		return nil, err
+	}
+	gregorianAll := dateTimeExpression.month.isAll && dateTimeExpression.month.kind == monthKindMonth
+	if dateTimeExpression.day.kind == dayKindDayOfYear && !gregorianAll {
+		return nil, fmt.Errorf("%w: day of year needs month *", ErrMonthFormat)
+	}
+
 	if dateTimeExpression.year.isAll &&
-		dateTimeExpression.month.isAll &&
+		gregorianAll &&
 		dateTimeExpression.day.isAll &&
 		dateTimeExpression.hour.isAll {
 		dateTimeExpression.alwaysActive = true
 	}
-	if !dateTimeExpression.year.isAll {
+	if dateTimeExpression.year.hasEnd {
 		dateTimeExpression.hasEnd = true
 	}
 
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	dateTimeExpression.dayStart = options.dayStart
+	if dateTimeExpression.day.kind == dayKindBusinessDay {
+		if options.calendar == nil {
+			return nil, ErrNoCalendar
+		}
+		dateTimeExpression.day.calendar = options.calendar
+	}
+	if dateTimeExpression.day.kind == dayKindSolarTerm && !dateTimeExpression.year.inRange(solarTermMinYear, solarTermMaxYear) {
+		return nil, ErrSolarTermOutOfRange
+	}
+	if dateTimeExpression.hour.hasSun {
+		if options.location == nil {
+			return nil, ErrNoGeoLocation
+		}
+		if options.dayStart != 0 {
+			return nil, fmt.Errorf("%w: can not be used with sunrise or sunset", ErrDayStartFormat)
+		}
+		dateTimeExpression.location = options.location
+	}
+	if dateTimeExpression.month.kind == monthKindFiscal || dateTimeExpression.month.kind == monthKindFiscalQuarter {
+		if options.fiscal == nil {
+			return nil, ErrNoFiscalCalendar
+		}
+		dateTimeExpression.fiscal = options.fiscal
+	}
+	dateTimeExpression.clockHour = dateTimeExpression.hour
+	dateTimeExpression.hour = dateTimeExpression.hour.rebase(options.dayStart)
+
 	return dateTimeExpression, nil
 }
 
@@ -70,33 +134,98 @@ func (expression *DateTimeExpression) IsIn(t time.Time) bool {
 	if expression.alwaysActive {
 		return true
 	}
+	if expression.hour.hasSun {
+		_, _, in := scanWindowAt(expression, t)
+		return in
+	}
+	t = expression.toLogical(t)
 
-	in := expression.year.isIn(t.Year())
+	in := expression.isInDate(t)
 	if !in {
 		return false
 	}
 
-	in = expression.month.isIn(int(t.Month()))
-	if !in {
-		return false
+	in = expression.hour.isIn(t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+	return in
+}
+
+// isInDate 日期是否在年, 月, 日的范围内, 月份为农历时年月日都按农历判断, 月份为ISO周时年为周所属的年, 月份为财年的期时年月日都按财年判断
+func (expression *DateTimeExpression) isInDate(t time.Time) bool {
+	switch expression.month.kind {
+	case monthKindLunar, monthKindLunarLeap:
+		date, err := ToLunar(t)
+		return err == nil && expression.year.isIn(date.Year) &&
+			expression.month.isInLunar(date.Month, date.Leap) && expression.day.isInLunarDate(t, date)
+	case monthKindWeekOfYear:
+		year, week := t.ISOWeek()
+		return expression.year.isIn(year) && expression.month.isIn(week) && expression.day.isInDate(t)
+	case monthKindFiscal, monthKindFiscalQuarter:
+		date := expression.fiscal.ToFiscal(t)
+		return expression.year.isIn(date.Year) && expression.month.isIn(date.Period) &&
+			expression.day.isInFiscalDate(t, date, expression.fiscal.periodDays(t, date))
+	}
+
+	return expression.year.isIn(t.Year()) && expression.month.isIn(int(t.Month())) && expression.day.isInDate(t)
+}
+
+// checkLunar 检查农历的表达式, 年需要在农历支持的范围内, 日不支持W和#
+func (expression *DateTimeExpression) checkLunar() error {
+	if !expression.year.inRange(lunarMinYear, lunarMaxYear) {
+		return ErrLunarOutOfRange
 	}
+	switch expression.day.kind {
+	case dayKindNearestWeekday, dayKindLastWeekday, dayKindNthWeekday:
+		return ErrDayFormat
+	}
+	return nil
+}
 
-	in = expression.day.isIn(t.Day())
-	if !in {
-		return false
+// checkWeekOfYear 检查ISO周的表达式, 日只能是*, 星期几, 工作日或者节气
+func (expression *DateTimeExpression) checkWeekOfYear() error {
+	day := expression.day
+	switch day.kind {
+	case dayKindWeekday, dayKindBusinessDay, dayKindSolarTerm:
+		return nil
+	case dayKindMonthDay:
+		if day.isAll {
+			return nil
+		}
 	}
+	return fmt.Errorf("%w: %s can not be used with ISO weeks", ErrDayFormat, day)
+}
 
-	in = expression.hour.isIn(t.Hour(), t.Minute(), t.Second())
-	return in
+// checkFiscal 检查财年的表达式, 日不支持W, #和一年中的第几天
+func (expression *DateTimeExpression) checkFiscal() error {
+	day := expression.day
+	switch day.kind {
+	case dayKindNearestWeekday, dayKindLastWeekday, dayKindNthWeekday, dayKindDayOfYear:
+		return fmt.Errorf("%w: %s can not be used with fiscal periods", ErrDayFormat, day)
+	}
+	return nil
 }
 
 // GetStartTime 获取开始时间
 // 1. 如果在周期内,则返回本次周期的开始时间
 // 2. 如果在周期外,则返回下次周期的开始时间
 func (expression *DateTimeExpression) GetStartTime(t time.Time) (time.Time, error) {
+	startTime, err := expression.getStartTime(expression.toLogical(t))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expression.fromLogical(startTime), nil
+}
+
+// getStartTime 按逻辑上的时间计算开始时间
+func (expression *DateTimeExpression) getStartTime(t time.Time) (time.Time, error) {
 	if expression.alwaysActive {
 		return time.Time{}, ErrAlwaysActiveNoStartTime
 	}
+	if expression.needScan() {
+		return scanStartTime(expression, t)
+	}
+	if expression.neverMatches() {
+		return time.Time{}, ErrNeverMatch
+	}
 
 	// 0.0 计算开始年
 	startTime, err := expression.calculateStartYear(t)
@@ -107,19 +236,19 @@ func (expression *DateTimeExpression) GetStartTime(t time.Time) (time.Time, erro
 	// 1.0 计算开始月
 	startTime, err = expression.calculateStartMonth(startTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoStart)
 	}
 
 	// 2.0 计算开始天
 	startTime, err = expression.calculateStartDay(startTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoStart)
 	}
 
 	// 3.0 计算时分秒
 	startTime, err = expression.calculateStartHourUnit(startTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoStart)
 	}
 
 	return startTime, nil
@@ -131,6 +260,10 @@ func (expression *DateTimeExpression) calculateStartYear(t time.Time) (time.Time
 	if err != nil {
 		return time.Time{}, err
 	}
+	if expression.month.isAll && expression.day.isAll && expression.hour.isAll && !expression.year.hasStart {
+		// 只设了结束年, 整个范围是一个没有开始的周期
+		return time.Time{}, ErrNoStart
+	}
 	if expression.month.isAll && expression.day.isAll && expression.hour.isAll ||
 		t.Year() < startYear {
 		// 如果只设了年，则返回开始的年
@@ -160,7 +293,7 @@ func (expression *DateTimeExpression) calculateStartMonth(t time.Time) (time.Tim
 	if addYear {
 		// 时间跨越1年，需要从新的一年的第一个时刻开始
 		t = time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 	}
@@ -191,7 +324,7 @@ func (expression *DateTimeExpression) calculateStartDay(t time.Time) (time.Time,
 	if addMonth {
 		// 时间跨越1个月，需要从新的一个月的第一个时刻开始
 		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 		if int(t.Month()) > expression.month.end {
@@ -221,14 +354,14 @@ func (expression *DateTimeExpression) calculateStartDay(t time.Time) (time.Time,
 
 // calculateStartHourUnit 计算开始的时分秒
 func (expression *DateTimeExpression) calculateStartHourUnit(t time.Time) (time.Time, error) {
-	startHourUnit, addDay, err := expression.hour.getStart(t.Hour(), t.Minute(), t.Second())
+	startHourUnit, addDay, err := expression.hour.getStart(t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
 	if err != nil {
 		return time.Time{}, err
 	}
 	if addDay {
 		// 推后1天，就从新的一天的第一刻开始
 		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 		if int(t.Month()) > expression.month.end {
@@ -237,11 +370,11 @@ func (expression *DateTimeExpression) calculateStartHourUnit(t time.Time) (time.
 		if t.Day() > expression.day.end {
 			// 有可能下个月的, 递归处理
 			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.Local)
-			return expression.GetStartTime(t)
+			return expression.getStartTime(t)
 		}
 	}
 	t = time.Date(t.Year(), t.Month(), t.Day(), startHourUnit.Hour, startHourUnit.Minute, startHourUnit.Sec,
-		0, time.Local)
+		startHourUnit.Nsec, time.Local)
 
 	return t, nil
 }
@@ -268,9 +401,24 @@ func (expression *DateTimeExpression) GetNextStartTime(t time.Time) (time.Time,
 // GetEndTime 获取结束时间,仅在周期内有效
 // 实现为左闭右开 etc: [2001][09][10][18:00:00-19:00:00] 那么结束时间为2001-09-10 19:00:00(因为结束时间,已经结束了)
 func (expression *DateTimeExpression) GetEndTime(t time.Time) (time.Time, error) {
+	endTime, err := expression.getEndTime(expression.toLogical(t))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expression.fromLogical(endTime), nil
+}
+
+// getEndTime 按逻辑上的时间计算结束时间
+func (expression *DateTimeExpression) getEndTime(t time.Time) (time.Time, error) {
 	if expression.alwaysActive {
 		return time.Time{}, ErrNoEnd
 	}
+	if expression.needScan() {
+		return scanEndTime(expression, t)
+	}
+	if expression.neverMatches() {
+		return time.Time{}, ErrNeverMatch
+	}
 
 	endTime, err := expression.calculateEndYear(t)
 	if err != nil {
@@ -279,17 +427,17 @@ func (expression *DateTimeExpression) GetEndTime(t time.Time) (time.Time, error)
 
 	endTime, err = expression.calculateEndMonth(endTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoEnd)
 	}
 
 	endTime, err = expression.calculateEndDay(endTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoEnd)
 	}
 
 	endTime, err = expression.calculateEndHourUnit(endTime)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, publicError(err, ErrNoEnd)
 	}
 
 	return endTime, nil
@@ -302,6 +450,10 @@ func (expression *DateTimeExpression) calculateEndYear(t time.Time) (time.Time,
 	if err != nil {
 		return time.Time{}, err
 	}
+	if expression.month.isAll && expression.day.isAll && expression.hour.isAll && !expression.year.hasEnd {
+		// 只设了开始年, 整个范围是一个没有结束的周期
+		return time.Time{}, ErrNoEnd
+	}
 	if expression.month.isAll && expression.day.isAll && expression.hour.isAll {
 		// 如果只设了年，则返回结束的年
 		t = time.Date(endYear, time.January, 1, 0, 0, 0, 0, time.Local)
@@ -343,7 +495,7 @@ func (expression *DateTimeExpression) calculateEndMonth(t time.Time) (time.Time,
 	if addYear {
 		// 时间跨越1年，需要从新的一年的第一个时刻开始
 		t = time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 	}
@@ -379,7 +531,7 @@ func (expression *DateTimeExpression) calculateEndDay(t time.Time) (time.Time, e
 	if addMonth {
 		// 时间跨越1个月，需要从新的一个月的第一个时刻开始
 		t = time.Date(t.Year(), t.Month()+1, expression.day.start, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 		if int(t.Month()) > expression.month.end {
@@ -396,6 +548,10 @@ func (expression *DateTimeExpression) calculateEndDay(t time.Time) (time.Time, e
 			return expression.calculateEndDay(t)
 		}
 	}
+	if days := daysIn(t.Month(), t.Year()); endDay > days {
+		// 结束日超过了当月的天数, 例如 [*][*][29-31][*] 在2月
+		endDay = days
+	}
 	if t.Day() < endDay && expression.hour.isAll {
 		// 当日向后推进时，防止时分秒跨越
 		t = time.Date(t.Year(), t.Month(), endDay, 0, 0, 0, 0, time.Local)
@@ -406,14 +562,14 @@ func (expression *DateTimeExpression) calculateEndDay(t time.Time) (time.Time, e
 
 // calculateEndHourUnit 计算结束的时分秒
 func (expression *DateTimeExpression) calculateEndHourUnit(t time.Time) (time.Time, error) {
-	endHourUnit, addDay, err := expression.hour.getEnd(t.Hour(), t.Minute(), t.Second())
+	endHourUnit, addDay, err := expression.hour.getEnd(t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
 	if err != nil {
 		return time.Time{}, err
 	}
 	if addDay {
 		// 推后1天，就从新的一天的第一刻开始
 		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.Local)
-		if t.Year() > expression.year.end {
+		if expression.year.isAfterEnd(t.Year()) {
 			return time.Time{}, ErrOutOfDate
 		}
 		if int(t.Month()) > expression.month.end {
@@ -422,11 +578,320 @@ func (expression *DateTimeExpression) calculateEndHourUnit(t time.Time) (time.Ti
 		if t.Day() > expression.day.end {
 			// 有可能下个月的, 递归处理
 			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.Local)
-			return expression.GetEndTime(t)
+			return expression.getEndTime(t)
 		}
 	}
 	t = time.Date(t.Year(), t.Month(), t.Day(), endHourUnit.Hour, endHourUnit.Minute, endHourUnit.Sec,
-		0, time.Local)
+		endHourUnit.Nsec, time.Local)
 
 	return t, nil
 }
+
+// FirstStartTime 获取表达式第一个周期的开始时间, 仅对有年范围的表达式有效
+func (expression *DateTimeExpression) FirstStartTime() (time.Time, error) {
+	startTime, err := expression.firstStartTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expression.fromLogical(startTime), nil
+}
+
+// firstStartTime 按逻辑上的时间计算第一个周期的开始时间
+func (expression *DateTimeExpression) firstStartTime() (time.Time, error) {
+	if expression.alwaysActive {
+		return time.Time{}, ErrAlwaysActiveNoStartTime
+	}
+	if !expression.year.hasStart {
+		return time.Time{}, ErrNoStart
+	}
+	if expression.needScan() {
+		first, _ := expression.dayRange()
+		startTime, err := scanStartTime(expression, first)
+		if err == ErrOutOfDate {
+			return time.Time{}, ErrNeverMatch
+		}
+		return startTime, err
+	}
+
+	_, lastYear := expression.year.bounds()
+	for year := expression.year.start; year <= lastYear; year++ {
+		for month := expression.month.start; month <= expression.month.end; month++ {
+			if expression.day.start > daysIn(time.Month(month), year) {
+				continue
+			}
+			startUnit := expression.hour.hourUnits[0].start
+			return time.Date(year, time.Month(month), expression.day.start,
+				startUnit.Hour, startUnit.Minute, startUnit.Sec, startUnit.Nsec, time.Local), nil
+		}
+	}
+
+	return time.Time{}, ErrNeverMatch
+}
+
+// FinalEndTime 获取表达式最后一个周期的结束时间, 仅对会结束的表达式有效
+func (expression *DateTimeExpression) FinalEndTime() (time.Time, error) {
+	endTime, err := expression.finalEndTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expression.fromLogical(endTime), nil
+}
+
+// finalEndTime 按逻辑上的时间计算最后一个周期的结束时间
+func (expression *DateTimeExpression) finalEndTime() (time.Time, error) {
+	if !expression.hasEnd {
+		return time.Time{}, ErrNoEnd
+	}
+	if expression.needScan() {
+		return scanFinalEndTime(expression)
+	}
+
+	endUnit := expression.hour.hourUnits[0].end
+	for _, unit := range expression.hour.hourUnits {
+		if unit.end.toDuration() > endUnit.toDuration() {
+			endUnit = unit.end
+		}
+	}
+	firstYear, _ := expression.year.bounds()
+	for year := expression.year.end; year >= firstYear; year-- {
+		for month := expression.month.end; month >= expression.month.start; month-- {
+			endDay := expression.day.end
+			if days := daysIn(time.Month(month), year); endDay > days {
+				endDay = days
+			}
+			if endDay < expression.day.start {
+				continue
+			}
+			// 24:00:00 会被time.Date转换为第二天的0点
+			return time.Date(year, time.Month(month), endDay,
+				endUnit.Hour, endUnit.Minute, endUnit.Sec, endUnit.Nsec, time.Local), nil
+		}
+	}
+
+	return time.Time{}, ErrNeverMatch
+}
+
+// neverMatches 配置的日在选中的月份中都不存在时, 表达式永远不会命中, etc: [*][02][30][*]
+func (expression *DateTimeExpression) neverMatches() bool {
+	if expression.day.isAll {
+		return false
+	}
+	for month := expression.month.start; month <= expression.month.end; month++ {
+		if expression.day.start <= expression.maxDaysIn(time.Month(month)) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicError 按字段推算时的内部错误不对外暴露, 统一转换为fallback
+func publicError(err error, fallback error) error {
+	switch err {
+	case ErrOutOfDate, ErrNoStart, ErrNoEnd, ErrNeverMatch, ErrBeyondScanHorizon:
+		return err
+	}
+	return fallback
+}
+
+// IsExpired 判断在时间t及之后, 表达式是否再也不会命中
+func (expression *DateTimeExpression) IsExpired(t time.Time) bool {
+	if !expression.needScan() && expression.neverMatches() {
+		return true
+	}
+
+	if !expression.hasEnd {
+		return false
+	}
+
+	finalEnd, err := expression.FinalEndTime()
+	if err != nil {
+		// 永远不会命中
+		return true
+	}
+
+	return !t.Before(finalEnd)
+}
+
+// String 格式化为标准的表达式 [*,yyyy,yyyy-yyyy,yyyy-,-yyyy][*,mm,mm-mm][*,dd,dd-dd][*,hh:mm:ss-hh:mm:ss]
+func (expression *DateTimeExpression) String() string {
+	return expression.Format(NameNumeric)
+}
+
+// Format 格式化为标准的表达式, style为NameEnglish或者NameChinese时公历月份和星期几使用名称, etc: [2024][Jan-Mar][Mon-Fri][*]
+func (expression *DateTimeExpression) Format(style NameStyle) string {
+	return "[" + expression.year.String() + "][" + expression.month.format(style) + "][" +
+		expression.day.format(style) + "][" + expression.clockHour.String() + "]"
+}
+
+// toLogical 把实际的时间转换为逻辑上的时间, 逻辑上的一天从0点开始
+func (expression *DateTimeExpression) toLogical(t time.Time) time.Time {
+	if expression.dayStart == 0 {
+		return t
+	}
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()-expression.dayStart,
+		t.Nanosecond(), time.Local)
+}
+
+// fromLogical 把逻辑上的时间转换为实际的时间
+func (expression *DateTimeExpression) fromLogical(t time.Time) time.Time {
+	if expression.dayStart == 0 {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+expression.dayStart,
+		t.Nanosecond(), time.Local)
+}
+
+// needScan 是否需要逐天扫描计算开始/结束时间, 按星期几, 月中的位置, 工作日, 节气, 农历, ISO周, 一年中的第几天, 日出日落或者财年配置时无法按字段推算
+// 只在闰年存在的日期按字段推算时下一年可能没有这一天, 也需要逐天扫描
+func (expression *DateTimeExpression) needScan() bool {
+	if expression.hour.hasSun {
+		return true
+	}
+	switch expression.month.kind {
+	case monthKindMonth, monthKindQuarter:
+		// 公历月份中的日期可以按字段推算
+		return expression.day.kind != dayKindMonthDay || expression.leapDayOnly()
+	}
+	return true
+}
+
+// leapDayOnly 选中的月份中只有闰年的2月有开始日, etc: [*][02][29][*], 年的范围内没有闰年时为永远不会命中
+func (expression *DateTimeExpression) leapDayOnly() bool {
+	if expression.day.isAll {
+		return false
+	}
+	for month := expression.month.start; month <= expression.month.end; month++ {
+		if expression.day.start <= daysIn(time.Month(month), 2001) {
+			return false
+		}
+	}
+	return expression.month.isIn(int(time.February)) && expression.day.start <= expression.maxDaysIn(time.February)
+}
+
+// dayUnits 实现daySchedule, 返回某一天的时间段
+func (expression *DateTimeExpression) dayUnits(day time.Time) []*hourUnitExpression {
+	if expression.hour.hasSun {
+		return expression.sunDayUnits(day)
+	}
+	if !expression.isInDate(day) {
+		return nil
+	}
+	return expression.hour.hourUnits
+}
+
+// joinsDays 实现daySchedule, 和按字段推算时一致, 配置了时分秒的时间段不会跨过0点, 相邻两天的时间段保持独立
+// 时分秒为*时连续的整天是同一个周期, 日出日落的时间段按天拆分, 跨过0点的部分需要合并回来
+func (expression *DateTimeExpression) joinsDays() bool {
+	return expression.hour.isAll || expression.hour.hasSun
+}
+
+// seekDay 实现daySeeker, 农历闰月每隔几年才有一次, 按农历数据表跳到下一个有这个闰月的年份
+func (expression *DateTimeExpression) seekDay(day time.Time) (time.Time, bool) {
+	if expression.month.kind != monthKindLunarLeap {
+		return day, true
+	}
+	date, err := ToLunar(day)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for year := date.Year; year <= lunarMaxYear; year++ {
+		if !expression.year.isIn(year) || lunarLeapMonth(year) != expression.month.start {
+			continue
+		}
+		leap := LunarDate{Year: year, Month: expression.month.start, Leap: true}
+		if year == date.Year && date.Month == leap.Month && date.Leap {
+			// 已经在闰月中
+			return day, true
+		}
+		if year == date.Year && date.Month > leap.Month {
+			// 今年的闰月已经过去
+			continue
+		}
+		leap.Day = 1
+		first, err := leap.Time()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return first, true
+	}
+	return time.Time{}, false
+}
+
+// dayRange 实现daySchedule, 有效日期的范围为年的范围, 农历时为农历年的范围, ISO周时为周所属的年的范围, 财年时为财年的范围
+// 没有开始年或结束年时对应的一端为零值
+func (expression *DateTimeExpression) dayRange() (first time.Time, last time.Time) {
+	year := expression.year
+	switch expression.month.kind {
+	case monthKindLunar, monthKindLunarLeap:
+		startYear, endYear := lunarMinYear, lunarMaxYear
+		if year.hasStart {
+			startYear = year.start
+		}
+		if year.hasEnd {
+			endYear = year.end
+		}
+		return lunarRange(startYear, endYear)
+	}
+
+	if year.hasStart {
+		first = time.Date(year.start, time.January, 1, 0, 0, 0, 0, time.Local)
+	}
+	if year.hasEnd {
+		last = time.Date(year.end, time.December, 31, 0, 0, 0, 0, time.Local)
+	}
+	switch expression.month.kind {
+	case monthKindWeekOfYear:
+		if year.hasStart {
+			first = isoWeekYearStart(year.start)
+		}
+		if year.hasEnd {
+			last = isoWeekYearStart(year.end+1).AddDate(0, 0, -1)
+		}
+	case monthKindFiscal, monthKindFiscalQuarter:
+		if year.hasStart {
+			first, _ = expression.fiscal.yearRange(year.start, year.start)
+		}
+		if year.hasEnd {
+			_, last = expression.fiscal.yearRange(year.end, year.end)
+		}
+	}
+	return first, last
+}
+
+// sunDayUnits 时分秒使用日出日落时某一天的时间段
+// 时间段属于开始的那一天, 前一天开始的时间段跨过0点的部分也在这一天中, 偏移最多24小时, 所以最多需要看前两天
+func (expression *DateTimeExpression) sunDayUnits(day time.Time) []*hourUnitExpression {
+	dayEnd := day.AddDate(0, 0, 1)
+	var windows []window
+	for offset := -2; offset <= 1; offset++ {
+		startDay := day.AddDate(0, 0, offset)
+		if !expression.isInDate(startDay) {
+			continue
+		}
+		for _, w := range expression.hour.sunWindows(startDay, *expression.location) {
+			if w.start.Before(day) {
+				w.start = day
+			}
+			if w.end.After(dayEnd) {
+				w.end = dayEnd
+			}
+			windows = append(windows, w)
+		}
+	}
+
+	var units []*hourUnitExpression
+	for _, w := range mergeWindows(windows) {
+		units = append(units, &hourUnitExpression{start: clockUnit(day, w.start), end: clockUnit(day, w.end)})
+	}
+	return units
+}
+
+// clockUnit 时间t在day这一天中的时分秒, 第二天的0点为24:00:00
+func clockUnit(day time.Time, t time.Time) hourUnit {
+	if !t.Before(day.AddDate(0, 0, 1)) {
+		return hourUnit{Hour: 24}
+	}
+	t = t.In(time.Local)
+	return hourUnit{Hour: t.Hour(), Minute: t.Minute(), Sec: t.Second(), Nsec: t.Nanosecond()}
+}
@@ -1,6 +1,7 @@
 package timeexpression
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -203,6 +204,69 @@ func TestDateTimeExpression_GetStartTime_Year(t *testing.T) {
 			err:    ErrOutOfDate,
 			result: time.Time{},
 		},
+		{
+			index:  6,
+			exp:    "[2024-][*][*][*]",
+			input:  time.Date(2030, time.May, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			index:  7,
+			exp:    "[-2030][*][*][*]",
+			input:  time.Date(2020, time.May, 1, 0, 0, 0, 0, time.Local),
+			err:    ErrNoStart,
+			result: time.Time{},
+		},
+		{
+			index:  8,
+			exp:    "[-2030][*][*][*]",
+			input:  time.Date(2031, time.January, 1, 1, 0, 0, 0, time.Local),
+			err:    ErrOutOfDate,
+			result: time.Time{},
+		},
+		{
+			index:  9,
+			exp:    "[-2030][03][*][*]",
+			input:  time.Date(2030, time.January, 10, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2030, time.March, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			index:  10,
+			exp:    "[-2030][03][*][*]",
+			input:  time.Date(2030, time.April, 1, 0, 0, 0, 0, time.Local),
+			err:    ErrOutOfDate,
+			result: time.Time{},
+		},
+		{
+			index:  11,
+			exp:    "[2024-][03][*][20:00:00-22:00:00]",
+			input:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local),
+		},
+		{
+			index:  12,
+			exp:    "[2024-][12][31][23:00:00-24:00:00]",
+			input:  time.Date(9999, time.December, 31, 23, 30, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(9999, time.December, 31, 23, 0, 0, 0, time.Local),
+		},
+		{
+			index:  13,
+			exp:    "[2024-][02][29][*]",
+			input:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.Local),
+		},
+		{
+			index:  14,
+			exp:    "[2024-][02][29][*]",
+			input:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.Local),
+		},
 	}
 
 	for i, data := range testDatas {
@@ -699,6 +763,41 @@ func TestDateTimeExpression_GetEndTime_Year(t *testing.T) {
 			err:    ErrOutOfDate,
 			result: time.Time{},
 		},
+		{
+			index:  6,
+			exp:    "[2024-][*][*][*]",
+			input:  time.Date(2030, time.May, 1, 0, 0, 0, 0, time.Local),
+			err:    ErrNoEnd,
+			result: time.Time{},
+		},
+		{
+			index:  7,
+			exp:    "[-2030][*][*][*]",
+			input:  time.Date(2020, time.May, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2031, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			index:  8,
+			exp:    "[-2030][*][*][*]",
+			input:  time.Date(2031, time.January, 1, 0, 0, 0, 0, time.Local),
+			err:    ErrOutOfDate,
+			result: time.Time{},
+		},
+		{
+			index:  9,
+			exp:    "[2024-][12][31][*]",
+			input:  time.Date(9999, time.December, 31, 10, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(10000, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			index:  10,
+			exp:    "[2024-][02][29][*]",
+			input:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local),
+			err:    nil,
+			result: time.Date(2028, time.March, 1, 0, 0, 0, 0, time.Local),
+		},
 	}
 
 	for i, data := range testDatas {
@@ -1185,3 +1284,721 @@ func TestDateTimeExpression_GetNextStartTime(t *testing.T) {
 		assert.Equal(t, data.result, nextStartTime)
 	}
 }
+
+func TestDateTimeExpression_FirstStartTime(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		err    error
+		result time.Time
+	}{
+		{
+			exp: "[*][*][*][*]",
+			err: ErrAlwaysActiveNoStartTime,
+		},
+		{
+			exp: "[*][03][*][*]",
+			err: ErrNoStart,
+		},
+		{
+			exp:    "[2020-2021][*][*][*]",
+			result: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			result: time.Date(2000, time.February, 5, 8, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[2021-2024][02][29][20:00:00-22:00:00]",
+			result: time.Date(2024, time.February, 29, 20, 0, 0, 0, time.Local),
+		},
+		{
+			exp: "[2021][02][30][*]",
+			err: ErrNeverMatch,
+		},
+		{
+			exp: "[-2030][03][*][*]",
+			err: ErrNoStart,
+		},
+		{
+			exp:    "[2025-][02][29][20:00:00-22:00:00]",
+			result: time.Date(2028, time.February, 29, 20, 0, 0, 0, time.Local),
+		},
+		{
+			exp: "[2025-][02][30][*]",
+			err: ErrNeverMatch,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		firstStartTime, err := expr.FirstStartTime()
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.result, firstStartTime)
+
+	}
+}
+
+func TestDateTimeExpression_FinalEndTime(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		err    error
+		result time.Time
+	}{
+		{
+			exp: "[*][*][*][*]",
+			err: ErrNoEnd,
+		},
+		{
+			exp: "[*][03][*][08:00:00-09:00:00]",
+			err: ErrNoEnd,
+		},
+		{
+			exp:    "[2020-2021][*][*][*]",
+			result: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			result: time.Date(2000, time.April, 7, 12, 30, 30, 0, time.Local),
+		},
+		{
+			exp:    "[2001][01-02][29-31][*]",
+			result: time.Date(2001, time.February, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[2020-2023][02][29][20:00:00-22:00:00]",
+			result: time.Date(2020, time.February, 29, 22, 0, 0, 0, time.Local),
+		},
+		{
+			exp: "[2021][02][30][*]",
+			err: ErrNeverMatch,
+		},
+		{
+			exp: "[2024-][03][*][*]",
+			err: ErrNoEnd,
+		},
+		{
+			exp:    "[-2030][02][29][20:00:00-22:00:00]",
+			result: time.Date(2028, time.February, 29, 22, 0, 0, 0, time.Local),
+		},
+		{
+			exp:    "[-2030][*][w6][*]",
+			result: time.Date(2030, time.December, 29, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		finalEndTime, err := expr.FinalEndTime()
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.result, finalEndTime)
+	}
+}
+
+func TestDateTimeExpression_NeverMatch(t *testing.T) {
+	input := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.Local)
+	for _, exp := range []string{"[*][02][30][*]", "[2021][02][29][*]", "[*][04][31][10:00:00-11:00:00]"} {
+		fmt.Printf("exp:%s\n", exp)
+		expr, err := NewDateTimeExpression(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = expr.GetStartTime(input)
+		assert.True(t, errors.Is(err, ErrNeverMatch))
+		_, err = expr.GetEndTime(input)
+		assert.True(t, errors.Is(err, ErrNeverMatch))
+		_, err = expr.GetNextStartTime(input)
+		assert.True(t, errors.Is(err, ErrNeverMatch))
+	}
+}
+
+func TestDateTimeExpression_LeapDay(t *testing.T) {
+	input := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+	for _, exp := range []string{"[2024-][02][29][*]", "[*][02][29][*]", "[*][02][29-31][*]"} {
+		fmt.Printf("exp:%s\n", exp)
+		expr, err := NewDateTimeExpression(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 2025-2027年没有2月29日, 下一次在2028年
+		start, err := expr.GetStartTime(input)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.Local), start)
+
+		end, err := expr.GetEndTime(input)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2028, time.March, 1, 0, 0, 0, 0, time.Local), end)
+
+		next, err := expr.GetNextStartTime(input)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.Local), next)
+	}
+
+	// 年的范围内没有闰年
+	expr, err := NewDateTimeExpression("[2025-2027][02][29][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.GetStartTime(input)
+	assert.True(t, errors.Is(err, ErrNeverMatch))
+}
+
+func TestDateTimeExpression_IsExpired(t *testing.T) {
+	testDatas := []struct {
+		exp     string
+		input   time.Time
+		expired bool
+	}{
+		{
+			exp:     "[*][03][*][*]",
+			input:   time.Date(2100, time.April, 1, 0, 0, 0, 0, time.Local),
+			expired: false,
+		},
+		{
+			exp:     "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			input:   time.Date(2000, time.April, 7, 12, 0, 0, 0, time.Local),
+			expired: false,
+		},
+		{
+			exp:     "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			input:   time.Date(2000, time.April, 7, 12, 30, 30, 0, time.Local),
+			expired: true,
+		},
+		{
+			exp:     "[2021][02][30][*]",
+			input:   time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local),
+			expired: true,
+		},
+		{
+			// 没有结束年但永远不会命中
+			exp:     "[*][02][30][*]",
+			input:   time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local),
+			expired: true,
+		},
+		{
+			exp:     "[2024-][02][29][*]",
+			input:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local),
+			expired: false,
+		},
+		{
+			exp:     "[2024-][03][*][*]",
+			input:   time.Date(2100, time.April, 1, 0, 0, 0, 0, time.Local),
+			expired: false,
+		},
+		{
+			exp:     "[-2030][03][*][*]",
+			input:   time.Date(2030, time.April, 1, 0, 0, 0, 0, time.Local),
+			expired: true,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, data.expired, expr.IsExpired(data.input))
+	}
+}
+
+func TestDateTimeExpression_Weekday(t *testing.T) {
+	// 2024-03-04 是周一
+	expr, err := NewDateTimeExpression("[2024][03][w6-7][18:00:00-22:00:00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, expr.IsIn(time.Date(2024, time.March, 9, 18, 0, 0, 0, time.Local)))
+	assert.False(t, expr.IsIn(time.Date(2024, time.March, 8, 18, 0, 0, 0, time.Local)))
+
+	start, err := expr.GetStartTime(time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 9, 18, 0, 0, 0, time.Local), start)
+
+	end, err := expr.GetEndTime(time.Date(2024, time.March, 10, 19, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 10, 22, 0, 0, 0, time.Local), end)
+
+	next, err := expr.GetNextStartTime(time.Date(2024, time.March, 10, 19, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 16, 18, 0, 0, 0, time.Local), next)
+
+	_, err = expr.GetStartTime(time.Date(2024, time.March, 31, 23, 0, 0, 0, time.Local))
+	assert.Equal(t, ErrOutOfDate, err)
+
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 2, 18, 0, 0, 0, time.Local), first)
+
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 31, 22, 0, 0, 0, time.Local), final)
+
+	// 整天的周末, 周六和周日合并为一个周期
+	expr, err = NewDateTimeExpression("[*][*][w6-7][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, err = expr.GetStartTime(time.Date(2024, time.March, 10, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 9, 0, 0, 0, 0, time.Local), start)
+	end, err = expr.GetEndTime(time.Date(2024, time.March, 9, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 11, 0, 0, 0, 0, time.Local), end)
+
+	// 配置了时分秒时每天是独立的周期, 和按日配置的[*][*][09-10][00:00:00-24:00:00]一致
+	for _, exp := range []string{"[*][*][w6-7][00:00:00-24:00:00]", "[2024][03][09-10][00:00:00-24:00:00]"} {
+		expr, err = NewDateTimeExpression(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start, err = expr.GetStartTime(time.Date(2024, time.March, 10, 12, 0, 0, 0, time.Local))
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.Local), start, exp)
+		end, err = expr.GetEndTime(time.Date(2024, time.March, 9, 12, 0, 0, 0, time.Local))
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.Local), end, exp)
+	}
+}
+
+func TestDateTimeExpression_Positional(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		start time.Time
+		end   time.Time
+		err   error
+	}{
+		{
+			exp:   "[*][*][L][20:00:00-22:00:00]",
+			t:     time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 29, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 29, 22, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][L][*]",
+			t:     time.Date(2023, time.February, 28, 12, 0, 0, 0, time.Local),
+			start: time.Date(2023, time.February, 28, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2023, time.March, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][*][w5#3][10:00:00-11:00:00]",
+			t:     time.Date(2024, time.March, 15, 11, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.April, 19, 10, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.April, 19, 11, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][06][15W][*]",
+			t:     time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.June, 14, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.June, 15, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp: "[2024][03][w5L][*]",
+			t:   time.Date(2024, time.March, 30, 0, 0, 0, 0, time.Local),
+			err: ErrOutOfDate,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start, err := expr.GetStartTime(data.t)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.end, end)
+		if data.err == nil {
+			assert.True(t, expr.IsIn(start))
+			assert.False(t, expr.IsIn(end))
+		}
+	}
+}
+
+func TestDateTimeExpression_GetEndTime_ShortMonth(t *testing.T) {
+	expr, err := NewDateTimeExpression("[2024][*][29-31][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 结束日不能超过当月的天数
+	end, err := expr.GetEndTime(time.Date(2024, time.February, 29, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local), end)
+
+	end, err = expr.GetEndTime(time.Date(2024, time.April, 29, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local), end)
+
+	// 2月的28-31日在平年和闰年都到3月1日结束
+	expr, err = NewDateTimeExpression("[*][02][28-31][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err = expr.GetEndTime(time.Date(2023, time.February, 28, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, time.March, 1, 0, 0, 0, 0, time.Local), end)
+
+	end, err = expr.GetEndTime(time.Date(2024, time.February, 28, 12, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local), end)
+}
+
+func TestDateTimeExpression_DayStart(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		next  time.Time
+	}{
+		{
+			exp:   "[*][*][01][*]",
+			t:     time.Date(2024, time.March, 1, 4, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 1, 5, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 2, 5, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 1, 5, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][01][*]",
+			t:     time.Date(2024, time.March, 2, 4, 59, 59, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 5, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 2, 5, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.April, 1, 5, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][01][20:00:00-22:00:00]",
+			t:     time.Date(2024, time.March, 1, 4, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 1, 22, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 1, 20, 0, 0, 0, time.Local),
+		},
+		{
+			// 早于05:00的时间属于逻辑上1日的末尾
+			exp:   "[*][*][01][02:00:00-04:00:00]",
+			t:     time.Date(2024, time.March, 1, 3, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 2, 2, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 2, 4, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 2, 2, 0, 0, 0, time.Local),
+		},
+		{
+			// 跨过05:00的时间段被拆分为逻辑上一天的开头和末尾
+			exp:   "[*][*][01][03:00:00-06:00:00]",
+			t:     time.Date(2024, time.March, 1, 5, 30, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 5, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 1, 6, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 2, 3, 0, 0, 0, time.Local),
+		},
+		{
+			// 跨年
+			exp:   "[2024][12][31][*]",
+			t:     time.Date(2025, time.January, 1, 3, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.December, 31, 5, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 1, 5, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][w6][*]",
+			t:     time.Date(2024, time.March, 9, 3, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 9, 5, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 10, 5, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 9, 5, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp, WithDayStart(5*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+		next, err := expr.GetNextStartTime(data.t)
+		if data.next.IsZero() {
+			assert.Equal(t, ErrOutOfDate, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.next, next)
+	}
+
+	expr, err := NewDateTimeExpression("[2024][12][31][*]", WithDayStart(5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.December, 31, 5, 0, 0, 0, time.Local), first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, time.January, 1, 5, 0, 0, 0, time.Local), final)
+	assert.False(t, expr.IsExpired(time.Date(2025, time.January, 1, 4, 0, 0, 0, time.Local)))
+	_, err = expr.ToCron(false)
+	assert.True(t, errors.Is(err, ErrCronLossy))
+
+	for _, offset := range []time.Duration{-time.Hour, 24 * time.Hour, 1500 * time.Millisecond} {
+		_, err = NewDateTimeExpression("[*][*][01][*]", WithDayStart(offset))
+		assert.True(t, errors.Is(err, ErrDayStartFormat), fmt.Sprint(err))
+	}
+}
+
+func TestDateTimeExpression_WeekOfYear(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+	}{
+		{
+			// 2025年的第1周从2024-12-30(周一)开始
+			exp:   "[2025][W01][*][*]",
+			t:     time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.December, 30, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 6, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 2021-01-01 属于2020年的第53周
+			exp:   "[2020][W53][*][*]",
+			t:     time.Date(2021, time.January, 1, 12, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2020, time.December, 28, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2021, time.January, 4, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2021][W01][*][*]",
+			t:     time.Date(2021, time.January, 1, 12, 0, 0, 0, time.Local),
+			start: time.Date(2021, time.January, 4, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2021, time.January, 11, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][W10-20][w1-5][09:00:00-18:00:00]",
+			t:     time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 4, 18, 0, 0, 0, time.Local),
+		},
+		{
+			// 2024年第20周的周末之后, 下一次是2025年第10周的周一
+			exp:   "[*][W10-20][w1-5][09:00:00-18:00:00]",
+			t:     time.Date(2024, time.May, 18, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.March, 3, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.March, 3, 18, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	expr, err := NewDateTimeExpression("[2025][W01][*][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.December, 30, 0, 0, 0, 0, time.Local), first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, time.January, 6, 0, 0, 0, 0, time.Local), final)
+
+	// 2021年只有52周
+	expr, err = NewDateTimeExpression("[2021][W53][*][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.FirstStartTime()
+	assert.Equal(t, ErrNeverMatch, err)
+
+	for _, exp := range []string{"[*][W10][15][*]", "[*][W10][L][*]", "[*][W10][w1#2][*]"} {
+		_, err := NewDateTimeExpression(exp)
+		assert.True(t, errors.Is(err, ErrDayFormat), exp)
+	}
+}
+
+func TestDateTimeExpression_DayOfYear(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+	}{
+		{
+			// 2024年是闰年, 第100天是4月9日, 第200天是7月18日
+			exp:   "[2024][*][D100-200][*]",
+			t:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.April, 9, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.July, 19, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 第366天只在闰年存在
+			exp:   "[*][*][D366][20:00:00-24:00:00]",
+			t:     time.Date(2023, time.June, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.December, 31, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][*][D001][*]",
+			t:     time.Date(2025, time.January, 1, 12, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 2, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	for _, exp := range []string{"[*][03][D100][*]", "[*][L*][D100][*]", "[*][W10][D100][*]"} {
+		_, err := NewDateTimeExpression(exp)
+		assert.NotNil(t, err, exp)
+	}
+}
+
+func TestDateTimeExpression_SubSecond(t *testing.T) {
+	ms := int(time.Millisecond)
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		next  time.Time
+	}{
+		{
+			exp:   "[*][*][*][12:00:00.500-12:00:05.250]",
+			t:     time.Date(2024, time.March, 1, 12, 0, 0, 499*ms, time.Local),
+			start: time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+			end:   time.Date(2024, time.March, 1, 12, 0, 5, 250*ms, time.Local),
+			next:  time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+		},
+		{
+			exp:   "[*][*][*][12:00:00.500-12:00:05.250]",
+			t:     time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+			end:   time.Date(2024, time.March, 1, 12, 0, 5, 250*ms, time.Local),
+			next:  time.Date(2024, time.March, 2, 12, 0, 0, 500*ms, time.Local),
+		},
+		{
+			exp:   "[*][*][*][12:00:00.500-12:00:05.250]",
+			t:     time.Date(2024, time.March, 1, 12, 0, 5, 250*ms-1, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+			end:   time.Date(2024, time.March, 1, 12, 0, 5, 250*ms, time.Local),
+			next:  time.Date(2024, time.March, 2, 12, 0, 0, 500*ms, time.Local),
+		},
+		{
+			exp:   "[*][*][*][12:00:00.500-12:00:05.250]",
+			t:     time.Date(2024, time.March, 1, 12, 0, 6, 0, time.Local),
+			start: time.Date(2024, time.March, 2, 12, 0, 0, 500*ms, time.Local),
+			end:   time.Date(2024, time.March, 2, 12, 0, 5, 250*ms, time.Local),
+			next:  time.Date(2024, time.March, 2, 12, 0, 0, 500*ms, time.Local),
+		},
+		{
+			exp:   "[*][*][*][12:00:00.000001-12:00:00.000002,12:00:00.5-12:00:01]",
+			t:     time.Date(2024, time.March, 1, 12, 0, 0, 1500, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 1, 12, 0, 0, 1000, time.Local),
+			end:   time.Date(2024, time.March, 1, 12, 0, 0, 2000, time.Local),
+			next:  time.Date(2024, time.March, 1, 12, 0, 0, 500*ms, time.Local),
+		},
+		{
+			// 最后一个周五, 按天扫描
+			exp:   "[*][*][w5L][12:00:00.500-12:00:05.250]",
+			t:     time.Date(2024, time.March, 29, 12, 0, 1, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 29, 12, 0, 0, 500*ms, time.Local),
+			end:   time.Date(2024, time.March, 29, 12, 0, 5, 250*ms, time.Local),
+			next:  time.Date(2024, time.April, 26, 12, 0, 0, 500*ms, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+		next, err := expr.GetNextStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.next, next)
+	}
+
+	expr, err := NewDateTimeExpression("[2024][03][01][23:59:59.999-24:00:00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "[2024][03][01][23:59:59.999-24:00:00]", expr.String())
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 1, 23, 59, 59, 999*ms, time.Local), first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.Local), final)
+	_, err = expr.ToCron(true)
+	assert.True(t, errors.Is(err, ErrCronLossy))
+	_, err = expr.ToOnCalendar()
+	assert.True(t, errors.Is(err, ErrOnCalendarLossy))
+	_, err = expr.ToOpeningHours()
+	assert.True(t, errors.Is(err, ErrOpeningHoursLossy))
+}
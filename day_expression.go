@@ -2,6 +2,8 @@ package timeexpression
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,17 +16,59 @@ func parseDayInt(dayStr string) (int, error) {
 	return day.Day(), nil
 }
 
+func parseWeekdayInt(weekdayStr string) (int, error) {
+	weekday, err := strconv.Atoi(weekdayStr)
+	if err != nil {
+		return 0, err
+	}
+	if weekday < 1 || weekday > 7 {
+		return 0, ErrDayFormat
+	}
+	return weekday, nil
+}
+
+// dayKind 日字段的类型, 决定start和end的含义以及按什么规则匹配日期
+type dayKind int
+
+const (
+	dayKindMonthDay       dayKind = iota // *, dd, dd-dd: 月中的第几天
+	dayKindWeekday                       // wi, wi-j: start和end表示星期几, 1为周一, 7为周日
+	dayKindNthWeekday                    // wi#n, wiL: 每月第nth个星期start, nth为-1表示最后一个
+	dayKindLast                          // L, L-n: 每月最后一天往前lastOffset天, start和end不使用
+	dayKindLastWeekday                   // LW: 每月最后一个工作日(周一到周五), start和end不使用
+	dayKindNearestWeekday                // ddW: 离start日最近的工作日(周一到周五), 不会跨月
+	dayKindBusinessDay                   // bd: 按日历计算的工作日, start和end不使用
+	dayKindSolarTerm                     // 节气, 节气-节气: start和end不使用
+	dayKindDayOfYear                     // Dnnn, Dnnn-nnn: 一年中的第几天, 1月1日为第1天, start和end为1-366, 月需要为*
+)
+
 type dayExpression struct {
 	start int
 	end   int
 	isAll bool
+	kind  dayKind
+
+	// 按月中的位置计算的日期, 对应Quartz中的L, #
+	lastOffset int
+	nth        int
+
+	// bd的日历, 在创建表达式时设置
+	calendar *Calendar
+
+	// 节气, termStart和termEnd相同时为节气当天, 否则为termStart当天到termEnd当天, 和其他日的范围一样包含结束的那天
+	termStart SolarTerm
+	termEnd   SolarTerm
 }
 
-// newDayExpression 创建日的时间表达式,支持格式为 [*,dd,dd-dd]
+// newDayExpression 创建日的时间表达式,支持格式为 [*,dd,dd-dd,wi,wi-j,wi#n,wiL,L,L-n,LW,ddW,bd,节气,节气-节气,Dnnn,Dnnn-nnn]
 func newDayExpression(expression string) (*dayExpression, error) {
 	dayExpression := &dayExpression{}
 
 	expression = strings.Trim(expression, " ")
+	if weekdays, ok := replaceWeekdayNames(expression); ok {
+		// 星期几的名称, etc: Mon-Fri, 周一-周五, Fri#3
+		expression = weekdays
+	}
 	if expression == "*" {
 		// *的情况
 		dayExpression.start = 1
@@ -32,6 +76,23 @@ func newDayExpression(expression string) (*dayExpression, error) {
 		dayExpression.isAll = true
 		return dayExpression, nil
 	}
+	if expression == "bd" {
+		// 工作日, 日历在创建表达式时设置
+		dayExpression.kind = dayKindBusinessDay
+		return dayExpression, nil
+	}
+	if _, err := ParseSolarTerm(strings.Split(expression, "-")[0]); err == nil {
+		return newSolarTermDayExpression(expression)
+	}
+	if strings.HasPrefix(expression, "w") {
+		return newWeekdayExpression(strings.TrimPrefix(expression, "w"))
+	}
+	if strings.HasPrefix(expression, "D") {
+		return newDayOfYearExpression(strings.TrimPrefix(expression, "D"))
+	}
+	if strings.HasPrefix(expression, "L") || strings.HasSuffix(expression, "W") {
+		return newPositionalDayExpression(expression)
+	}
 	splitDayStr := strings.Split(expression, "-")
 	if len(splitDayStr) > 2 {
 		return nil, ErrDayFormat
@@ -60,6 +121,141 @@ func newDayExpression(expression string) (*dayExpression, error) {
 	return dayExpression, nil
 }
 
+// newSolarTermDayExpression 创建节气的日期, 支持格式为 立春(节气当天), 立春-立夏(立春当天到立夏当天), 也可以使用拼音
+func newSolarTermDayExpression(expression string) (*dayExpression, error) {
+	splitTermStr := strings.Split(expression, "-")
+	if len(splitTermStr) > 2 {
+		return nil, ErrDayFormat
+	}
+
+	dayExpression := &dayExpression{kind: dayKindSolarTerm}
+	var err error
+	dayExpression.termStart, err = ParseSolarTerm(splitTermStr[0])
+	if err != nil {
+		return nil, err
+	}
+	dayExpression.termEnd = dayExpression.termStart
+	if len(splitTermStr) == 2 {
+		dayExpression.termEnd, err = ParseSolarTerm(splitTermStr[1])
+		if err != nil {
+			return nil, err
+		}
+		if dayExpression.termEnd == dayExpression.termStart {
+			return nil, ErrDayFormat
+		}
+	}
+	return dayExpression, nil
+}
+
+// newDayOfYearExpression 创建一年中第几天的时间表达式, 支持格式为 nnn,nnn-nnn, 为1-366, 第366天只在闰年存在
+func newDayOfYearExpression(expression string) (*dayExpression, error) {
+	dayExpression := &dayExpression{kind: dayKindDayOfYear}
+
+	splitDayStr := strings.Split(expression, "-")
+	if len(splitDayStr) > 2 {
+		return nil, ErrDayFormat
+	}
+	days := make([]int, 0, len(splitDayStr))
+	for _, dayStr := range splitDayStr {
+		day, err := strconv.Atoi(dayStr)
+		if err != nil || day < 1 || day > 366 {
+			return nil, ErrDayFormat
+		}
+		days = append(days, day)
+	}
+	dayExpression.start = days[0]
+	dayExpression.end = days[len(days)-1]
+
+	err := dayExpression.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return dayExpression, nil
+}
+
+// newPositionalDayExpression 创建按月中位置计算的日期, 支持格式为 L(最后一天), L-n(最后一天往前n天), LW(最后一个工作日), ddW(离dd日最近的工作日)
+func newPositionalDayExpression(expression string) (*dayExpression, error) {
+	dayExpression := &dayExpression{}
+
+	switch {
+	case expression == "L":
+		dayExpression.kind = dayKindLast
+	case expression == "LW":
+		dayExpression.kind = dayKindLastWeekday
+	case strings.HasPrefix(expression, "L-"):
+		offset, err := strconv.Atoi(strings.TrimPrefix(expression, "L-"))
+		if err != nil || offset < 1 || offset > 30 {
+			return nil, ErrDayFormat
+		}
+		dayExpression.kind = dayKindLast
+		dayExpression.lastOffset = offset
+	case strings.HasSuffix(expression, "W"):
+		day, err := parseDayInt(strings.TrimSuffix(expression, "W"))
+		if err != nil {
+			return nil, err
+		}
+		dayExpression.start = day
+		dayExpression.end = day
+		dayExpression.kind = dayKindNearestWeekday
+	default:
+		return nil, ErrDayFormat
+	}
+
+	return dayExpression, nil
+}
+
+// newWeekdayExpression 创建星期几的时间表达式, 支持格式为 i,i-j,i#n,iL, 1为周一, 7为周日
+func newWeekdayExpression(expression string) (*dayExpression, error) {
+	dayExpression := &dayExpression{kind: dayKindWeekday}
+
+	if idx := strings.Index(expression, "#"); idx >= 0 {
+		// 每月第n个星期i
+		nth, err := strconv.Atoi(expression[idx+1:])
+		if err != nil || nth < 1 || nth > 5 {
+			return nil, ErrDayFormat
+		}
+		dayExpression.kind = dayKindNthWeekday
+		dayExpression.nth = nth
+		expression = expression[:idx]
+	} else if strings.HasSuffix(expression, "L") {
+		// 每月最后一个星期i
+		dayExpression.kind = dayKindNthWeekday
+		dayExpression.nth = -1
+		expression = strings.TrimSuffix(expression, "L")
+	}
+	if dayExpression.kind == dayKindNthWeekday && strings.Contains(expression, "-") {
+		return nil, ErrDayFormat
+	}
+
+	splitWeekdayStr := strings.Split(expression, "-")
+	if len(splitWeekdayStr) > 2 {
+		return nil, ErrDayFormat
+	}
+
+	var err error
+	dayExpression.start, err = parseWeekdayInt(splitWeekdayStr[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(splitWeekdayStr) == 2 {
+		dayExpression.end, err = parseWeekdayInt(splitWeekdayStr[1])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		dayExpression.end = dayExpression.start
+	}
+
+	err = dayExpression.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return dayExpression, nil
+}
+
 // check 检查参数
 func (expression *dayExpression) check() error {
 	if expression.start > expression.end {
@@ -77,6 +273,111 @@ func (expression *dayExpression) isIn(day int) bool {
 	return false
 }
 
+// isPositional 是否按月中的位置计算日期(L, W, #)
+func (expression *dayExpression) isPositional() bool {
+	switch expression.kind {
+	case dayKindNthWeekday, dayKindLast, dayKindLastWeekday, dayKindNearestWeekday:
+		return true
+	}
+	return false
+}
+
+// isInDate 日期是否在周期内, 按星期几或者月中的位置配置时需要完整的日期
+func (expression *dayExpression) isInDate(t time.Time) bool {
+	switch expression.kind {
+	case dayKindBusinessDay:
+		return expression.calendar.IsBusinessDay(t)
+	case dayKindSolarTerm:
+		return expression.isInSolarTerm(t)
+	case dayKindDayOfYear:
+		return expression.isIn(t.YearDay())
+	case dayKindNthWeekday:
+		if expression.nth < 0 {
+			return expression.isIn(isoWeekday(t)) && t.Day()+7 > daysIn(t.Month(), t.Year())
+		}
+		return expression.isIn(isoWeekday(t)) && (t.Day()-1)/7+1 == expression.nth
+	case dayKindWeekday:
+		return expression.isIn(isoWeekday(t))
+	case dayKindLast, dayKindLastWeekday, dayKindNearestWeekday:
+		return t.Day() == expression.positionalDay(t.Year(), t.Month())
+	}
+	return expression.isIn(t.Day())
+}
+
+// isInSolarTerm 日期是否为节气当天, 或者在两个节气之间, 范围可以跨年, 例如立冬-立春
+func (expression *dayExpression) isInSolarTerm(t time.Time) bool {
+	term, isTermDay, ok := solarTermOfDay(t)
+	switch {
+	case !ok:
+		return false
+	case expression.termStart == expression.termEnd:
+		return isTermDay && term == expression.termStart
+	case isTermDay && term == expression.termEnd:
+		return true
+	case expression.termStart < expression.termEnd:
+		return expression.termStart <= term && term < expression.termEnd
+	}
+	return term >= expression.termStart || term < expression.termEnd
+}
+
+// isInLunarDate 日期是否在周期内, 月份为农历时使用, 日和L, L-n按农历的日计算, 星期几和工作日仍按公历日期
+func (expression *dayExpression) isInLunarDate(t time.Time, date LunarDate) bool {
+	switch expression.kind {
+	case dayKindBusinessDay, dayKindWeekday, dayKindNthWeekday, dayKindSolarTerm:
+		return expression.isInDate(t)
+	case dayKindLast:
+		return date.Day == date.monthDays()-expression.lastOffset
+	}
+	return expression.isIn(date.Day)
+}
+
+// isInFiscalDate 日期是否在周期内, 月份为财年的期时使用, 日和L, L-n按期中的第几天计算, 星期几和工作日仍按公历日期
+// 按周划分的期最长有42天, *包含所有的天
+func (expression *dayExpression) isInFiscalDate(t time.Time, date FiscalDate, periodDays int) bool {
+	if expression.isAll {
+		return true
+	}
+	switch expression.kind {
+	case dayKindBusinessDay, dayKindWeekday, dayKindNthWeekday, dayKindSolarTerm:
+		return expression.isInDate(t)
+	case dayKindLast:
+		return date.Day == periodDays-expression.lastOffset
+	}
+	return expression.isIn(date.Day)
+}
+
+// positionalDay 计算某个月中L, W对应的日期, 当月没有这一天时返回0
+func (expression *dayExpression) positionalDay(year int, month time.Month) int {
+	days := daysIn(month, year)
+	day := expression.start
+	switch expression.kind {
+	case dayKindLast, dayKindLastWeekday:
+		day = days - expression.lastOffset
+	}
+	if day < 1 || day > days {
+		return 0
+	}
+	if expression.kind == dayKindLast {
+		return day
+	}
+
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.Local).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			// 不跨到上个月, 使用下周一
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == days {
+			// 不跨到下个月, 使用上周五
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
 // getStart 获取开始日期
 // 1. 如果在周期内,则返回本次周期的日期
 // 2. 如果在周期外,则返回下次的开始日期
@@ -120,3 +421,61 @@ func (expression *dayExpression) getEnd(year int, month time.Month, day int) (en
 	}
 	return 0, false, errors.New("dayExpression getEnd get unreachable error")
 }
+
+// String 格式化为*,dd,dd-dd,wi,wi-j,wi#n,wiL,L,L-n,LW,ddW,bd,节气,节气-节气,Dnnn,Dnnn-nnn
+func (expression *dayExpression) String() string {
+	return expression.format(NameNumeric)
+}
+
+// format 格式化, 星期几按style使用wi或者名称
+func (expression *dayExpression) format(style NameStyle) string {
+	weekday := func(weekday int) string {
+		if style == NameNumeric {
+			return "w" + formatWeekday(weekday, style)
+		}
+		return formatWeekday(weekday, style)
+	}
+	if expression.isAll {
+		return "*"
+	}
+	switch expression.kind {
+	case dayKindBusinessDay:
+		return "bd"
+	case dayKindDayOfYear:
+		if expression.start == expression.end {
+			return fmt.Sprintf("D%03d", expression.start)
+		}
+		return fmt.Sprintf("D%03d-%03d", expression.start, expression.end)
+	case dayKindSolarTerm:
+		if expression.termStart == expression.termEnd {
+			return expression.termStart.String()
+		}
+		return expression.termStart.String() + "-" + expression.termEnd.String()
+	case dayKindNthWeekday:
+		if expression.nth < 0 {
+			return weekday(expression.start) + "L"
+		}
+		return fmt.Sprintf("%s#%d", weekday(expression.start), expression.nth)
+	case dayKindLastWeekday:
+		return "LW"
+	case dayKindLast:
+		if expression.lastOffset > 0 {
+			return fmt.Sprintf("L-%d", expression.lastOffset)
+		}
+		return "L"
+	case dayKindNearestWeekday:
+		return fmt.Sprintf("%02dW", expression.start)
+	case dayKindWeekday:
+		if expression.start == expression.end {
+			return weekday(expression.start)
+		}
+		if style == NameNumeric {
+			return fmt.Sprintf("w%d-%d", expression.start, expression.end)
+		}
+		return weekday(expression.start) + "-" + weekday(expression.end)
+	}
+	if expression.start == expression.end {
+		return fmt.Sprintf("%02d", expression.start)
+	}
+	return fmt.Sprintf("%02d-%02d", expression.start, expression.end)
+}
@@ -296,3 +296,194 @@ func TestDayExpression_GetEnd(t *testing.T) {
 		assert.Equal(t, data.resultAddMonth, addMonth)
 	}
 }
+
+func TestNewDayExpression_Weekday(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		start  int
+		end    int
+	}{
+		{exp: "w1", start: 1, end: 1},
+		{exp: "w6-7", start: 6, end: 7},
+		{exp: "w0", hasErr: true},
+		{exp: "w8", hasErr: true},
+		{exp: "w5-2", hasErr: true},
+		{exp: "w1-2-3", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newDayExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Equal(t, dayKindWeekday, expression.kind)
+			assert.Equal(t, data.start, expression.start)
+			assert.Equal(t, data.end, expression.end)
+			assert.Equal(t, data.exp, expression.String())
+		}
+	}
+
+	// 2024-03-09 是周六
+	expression, err := newDayExpression("w6-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, expression.isInDate(time.Date(2024, time.March, 9, 0, 0, 0, 0, time.Local)))
+	assert.True(t, expression.isInDate(time.Date(2024, time.March, 10, 0, 0, 0, 0, time.Local)))
+	assert.False(t, expression.isInDate(time.Date(2024, time.March, 11, 0, 0, 0, 0, time.Local)))
+}
+
+func TestNewDayExpression_Positional(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		kind   dayKind
+	}{
+		{exp: "L", kind: dayKindLast},
+		{exp: "L-3", kind: dayKindLast},
+		{exp: "LW", kind: dayKindLastWeekday},
+		{exp: "15W", kind: dayKindNearestWeekday},
+		{exp: "w5#3", kind: dayKindNthWeekday},
+		{exp: "w5L", kind: dayKindNthWeekday},
+		{exp: "L-0", hasErr: true},
+		{exp: "L-31", hasErr: true},
+		{exp: "L3", hasErr: true},
+		{exp: "32W", hasErr: true},
+		{exp: "w5#6", hasErr: true},
+		{exp: "w1-5#2", hasErr: true},
+		{exp: "w1-5L", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newDayExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.True(t, expression.isPositional())
+			assert.Equal(t, data.kind, expression.kind)
+			assert.Equal(t, data.exp, expression.String())
+		}
+	}
+}
+
+func TestDayExpression_IsInDate_Positional(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	testDatas := []struct {
+		exp  string
+		t    time.Time
+		isIn bool
+	}{
+		{exp: "L", t: date(2024, time.February, 29), isIn: true},
+		{exp: "L", t: date(2023, time.February, 28), isIn: true},
+		{exp: "L", t: date(2024, time.February, 28), isIn: false},
+		{exp: "L-3", t: date(2024, time.January, 28), isIn: true},
+		{exp: "L-3", t: date(2024, time.April, 27), isIn: true},
+		// 2024-06-15 是周六, 最近的工作日为周五
+		{exp: "15W", t: date(2024, time.June, 14), isIn: true},
+		{exp: "15W", t: date(2024, time.June, 15), isIn: false},
+		// 2024-09-15 是周日, 最近的工作日为周一
+		{exp: "15W", t: date(2024, time.September, 16), isIn: true},
+		// 2024-06-01 是周六, 不跨到上个月, 为6月3日周一
+		{exp: "01W", t: date(2024, time.June, 3), isIn: true},
+		{exp: "01W", t: date(2024, time.May, 31), isIn: false},
+		// 2024-03-31 是周日, 不跨到下个月, 为3月29日周五
+		{exp: "LW", t: date(2024, time.March, 29), isIn: true},
+		{exp: "31W", t: date(2024, time.April, 30), isIn: false},
+		// 2024年3月的周五为 1, 8, 15, 22, 29
+		{exp: "w5#3", t: date(2024, time.March, 15), isIn: true},
+		{exp: "w5#3", t: date(2024, time.March, 22), isIn: false},
+		{exp: "w5#5", t: date(2024, time.March, 29), isIn: true},
+		{exp: "w5L", t: date(2024, time.March, 29), isIn: true},
+		{exp: "w5L", t: date(2024, time.March, 22), isIn: false},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expression, err := newDayExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.isIn, expression.isInDate(data.t))
+	}
+}
+
+func TestNewDayExpression_DayOfYear(t *testing.T) {
+	testDatas := []struct {
+		exp       string
+		hasErr    bool
+		start     int
+		end       int
+		formatted string
+	}{
+		{exp: "D100", start: 100, end: 100},
+		{exp: "D100-200", start: 100, end: 200},
+		{exp: "D1-366", start: 1, end: 366, formatted: "D001-366"},
+		{exp: "D0", hasErr: true},
+		{exp: "D367", hasErr: true},
+		{exp: "D200-100", hasErr: true},
+		{exp: "D1-2-3", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newDayExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Equal(t, dayKindDayOfYear, expression.kind)
+			assert.Equal(t, data.start, expression.start)
+			assert.Equal(t, data.end, expression.end)
+			formatted := data.formatted
+			if formatted == "" {
+				formatted = data.exp
+			}
+			assert.Equal(t, formatted, expression.String())
+		}
+	}
+
+	// 2024年是闰年, 第60天是2月29日
+	expression, err := newDayExpression("D60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, expression.isInDate(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.Local)))
+	assert.True(t, expression.isInDate(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.Local)))
+	assert.False(t, expression.isInDate(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local)))
+}
+
+func TestNewDayExpression_WeekdayNames(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		result string
+	}{
+		{exp: "Mon-Fri", result: "w1-5"},
+		{exp: "mon-fri", result: "w1-5"},
+		{exp: "SATURDAY-sunday", result: "w6-7"},
+		{exp: "Wed", result: "w3"},
+		{exp: "wed", result: "w3"},
+		{exp: "Fri#3", result: "w5#3"},
+		{exp: "FriL", result: "w5L"},
+		{exp: "周一-周五", result: "w1-5"},
+		{exp: "星期六-星期日", result: "w6-7"},
+		{exp: "周天", result: "w7"},
+		{exp: "周五L", result: "w5L"},
+		{exp: "Fri-Mon", hasErr: true},
+		{exp: "Mon-5", hasErr: true},
+		{exp: "Mond", hasErr: true},
+		{exp: "Mon-Wed#2", hasErr: true},
+		{exp: "一", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newDayExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Contains(t, []dayKind{dayKindWeekday, dayKindNthWeekday}, expression.kind)
+			assert.Equal(t, data.result, expression.String())
+		}
+	}
+}
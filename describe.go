@@ -0,0 +1,623 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownLocale 没有注册对应语言的描述模板
+var ErrUnknownLocale = errors.New("describe locale not registered")
+
+// HourRange 一天中的时间范围, 以距离0点的时长表示
+// StartSun或者EndSun不为NoSunEvent时, 对应的Start或者End为相对日出日落的偏移, 可以为负
+type HourRange struct {
+	Start    time.Duration
+	End      time.Duration
+	StartSun SunEvent
+	EndSun   SunEvent
+}
+
+// Description 表达式的结构化描述, 由Locale渲染成对应语言的文本
+type Description struct {
+	AlwaysActive bool
+
+	AllYears  bool
+	YearStart int
+	YearEnd   int
+	// NoYearStart, NoYearEnd 没有开始年或结束年, 此时对应的YearStart或YearEnd为0, etc: 2024-, -2030, AllYears时两者都为true
+	NoYearStart bool
+	NoYearEnd   bool
+
+	AllMonths  bool
+	MonthStart time.Month
+	MonthEnd   time.Month
+	// Lunar 为true时年, 月, 日都是农历, LeapMonth为true时表示闰MonthStart月
+	Lunar     bool
+	LeapMonth bool
+	// WeekOfYear 为true时按ISO 8601的周, WeekStart和WeekEnd为第几周, 年为周所属的年, MonthStart和MonthEnd不使用
+	WeekOfYear bool
+	WeekStart  int
+	WeekEnd    int
+	// Quarter 为true时按季度, QuarterStart和QuarterEnd为第几季度, MonthStart和MonthEnd为季度包含的月份
+	Quarter      bool
+	QuarterStart int
+	QuarterEnd   int
+	// Fiscal 为true时年为财年, MonthStart和MonthEnd为财年中的第几期, 日为这一期中的第几天
+	Fiscal bool
+
+	AllDays  bool
+	DayStart int
+	DayEnd   int
+
+	// ByWeekday 为true时DayStart和DayEnd表示星期几, 1为周一, 7为周日
+	ByWeekday bool
+	// WeekdayNth 每月第几个星期DayStart, -1表示最后一个, 0表示不限
+	WeekdayNth int
+	// LastDay 为true时表示每月最后一天往前LastDayOffset天
+	LastDay       bool
+	LastDayOffset int
+	// NearestWeekday 为true时表示离指定日期(LastDay或者DayStart)最近的工作日
+	NearestWeekday bool
+	// DayOfYear 为true时DayStart和DayEnd为一年中的第几天
+	DayOfYear bool
+	// BusinessDay 为true时表示按日历计算的工作日, DayStart和DayEnd不使用
+	BusinessDay bool
+	// SolarTerm 为true时表示节气, SolarTermStart和SolarTermEnd相同时为节气当天, 否则为SolarTermStart当天到SolarTermEnd当天
+	SolarTerm      bool
+	SolarTermStart SolarTerm
+	SolarTermEnd   SolarTerm
+
+	// AllHours 为true时Hours为空
+	AllHours bool
+	Hours    []HourRange
+}
+
+// Locale 把表达式的描述渲染成某种语言的句子
+type Locale interface {
+	Describe(desc *Description) string
+}
+
+var (
+	localeMutex sync.RWMutex
+	locales     = map[string]Locale{
+		"en": englishLocale{},
+		"zh": chineseLocale{},
+	}
+)
+
+// RegisterLocale 注册一种语言的描述模板, 已存在的会被覆盖
+func RegisterLocale(lang string, locale Locale) {
+	localeMutex.Lock()
+	defer localeMutex.Unlock()
+
+	locales[strings.ToLower(lang)] = locale
+}
+
+// lookupLocale 查找语言模板, 找不到时尝试去掉地区后缀, etc: zh-CN -> zh
+func lookupLocale(lang string) (Locale, bool) {
+	localeMutex.RLock()
+	defer localeMutex.RUnlock()
+
+	lang = strings.ToLower(lang)
+	if locale, ok := locales[lang]; ok {
+		return locale, true
+	}
+	if idx := strings.IndexAny(lang, "-_"); idx > 0 {
+		locale, ok := locales[lang[:idx]]
+		return locale, ok
+	}
+	return nil, false
+}
+
+// FormatClock 格式化一天中的时间, 秒为0时省略秒, 有秒以下的部分时加上小数, etc: 08:00, 12:30:30 或者 12:00:00.500
+func FormatClock(d time.Duration) string {
+	unit := durationToHourUnit(d)
+	if unit.Sec == 0 && unit.Nsec == 0 {
+		return fmt.Sprintf("%02d:%02d", unit.Hour, unit.Minute)
+	}
+	return unit.String()
+}
+
+// Description 生成表达式的结构化描述
+func (expression *DateTimeExpression) Description() *Description {
+	desc := &Description{
+		AlwaysActive: expression.alwaysActive,
+		AllYears:     expression.year.isAll,
+		YearStart:    expression.year.start,
+		YearEnd:      expression.year.end,
+		NoYearStart:  !expression.year.hasStart,
+		NoYearEnd:    !expression.year.hasEnd,
+		AllMonths:    expression.month.isAll,
+		MonthStart:   time.Month(expression.month.start),
+		MonthEnd:     time.Month(expression.month.end),
+		AllDays:      expression.day.isAll,
+		DayStart:     expression.day.start,
+		DayEnd:       expression.day.end,
+		AllHours:     expression.clockHour.isAll,
+	}
+	switch expression.month.kind {
+	case monthKindLunarLeap:
+		desc.Lunar, desc.LeapMonth = true, true
+	case monthKindLunar:
+		desc.Lunar = true
+	case monthKindWeekOfYear:
+		desc.WeekOfYear = true
+		desc.AllMonths = true
+		desc.MonthStart, desc.MonthEnd = time.January, time.December
+		desc.WeekStart, desc.WeekEnd = expression.month.start, expression.month.end
+	case monthKindFiscalQuarter:
+		desc.Fiscal, desc.Quarter = true, true
+		desc.QuarterStart, desc.QuarterEnd = (expression.month.start+2)/3, expression.month.end/3
+	case monthKindQuarter:
+		desc.Quarter = true
+		desc.QuarterStart, desc.QuarterEnd = (expression.month.start+2)/3, expression.month.end/3
+	case monthKindFiscal:
+		desc.Fiscal = true
+	}
+	switch expression.day.kind {
+	case dayKindWeekday:
+		desc.ByWeekday = true
+	case dayKindNthWeekday:
+		desc.ByWeekday = true
+		desc.WeekdayNth = expression.day.nth
+	case dayKindLast:
+		desc.LastDay = true
+		desc.LastDayOffset = expression.day.lastOffset
+	case dayKindLastWeekday:
+		desc.LastDay, desc.NearestWeekday = true, true
+	case dayKindNearestWeekday:
+		desc.NearestWeekday = true
+	case dayKindDayOfYear:
+		desc.DayOfYear = true
+	case dayKindBusinessDay:
+		desc.BusinessDay = true
+	case dayKindSolarTerm:
+		desc.SolarTerm = true
+		desc.SolarTermStart, desc.SolarTermEnd = expression.day.termStart, expression.day.termEnd
+	}
+	if !expression.clockHour.isAll {
+		for _, unit := range expression.clockHour.hourUnits {
+			hourRange := HourRange{
+				Start: unit.start.toDuration(),
+				End:   unit.end.toDuration(),
+			}
+			if unit.startSun != nil {
+				hourRange.Start = time.Duration(unit.startSun.offset) * time.Second
+				hourRange.StartSun = unit.startSun.event
+			}
+			if unit.endSun != nil {
+				hourRange.End = time.Duration(unit.endSun.offset) * time.Second
+				hourRange.EndSun = unit.endSun.event
+			}
+			desc.Hours = append(desc.Hours, hourRange)
+		}
+	}
+
+	return desc
+}
+
+// singleYear 是否只有一年, etc: 2024
+func (desc *Description) singleYear() bool {
+	return !desc.NoYearStart && !desc.NoYearEnd && desc.YearStart == desc.YearEnd
+}
+
+// Describe 用指定语言描述表达式, 内置了en和zh, 可以通过RegisterLocale扩展
+func (expression *DateTimeExpression) Describe(lang string) (string, error) {
+	locale, ok := lookupLocale(lang)
+	if !ok {
+		return "", ErrUnknownLocale
+	}
+
+	return locale.Describe(expression.Description()), nil
+}
+
+// englishLocale 英文描述, etc: From Feb to Apr 2000, on days 5-7, 08:00-10:00 and 11:00-12:30:30
+type englishLocale struct{}
+
+func (englishLocale) Describe(desc *Description) string {
+	if desc.AlwaysActive {
+		return "Always"
+	}
+
+	var parts []string
+
+	// 年月
+	var yearPart string
+	if !desc.AllYears {
+		yearPrefix, everyYear := "", "every year"
+		if desc.Fiscal {
+			yearPrefix, everyYear = "FY", "every fiscal year"
+		}
+		switch {
+		case desc.NoYearEnd:
+			yearPart = fmt.Sprintf("%s from %s%d", everyYear, yearPrefix, desc.YearStart)
+		case desc.NoYearStart:
+			yearPart = fmt.Sprintf("%s until %s%d", everyYear, yearPrefix, desc.YearEnd)
+		case desc.singleYear():
+			yearPart = fmt.Sprintf("%s%d", yearPrefix, desc.YearStart)
+		default:
+			yearPart = fmt.Sprintf("%s%d-%d", yearPrefix, desc.YearStart, desc.YearEnd)
+		}
+	}
+	monthName := englishMonth
+	if desc.Lunar {
+		monthName = func(month time.Month) string {
+			if desc.LeapMonth {
+				return fmt.Sprintf("leap lunar month %d", month)
+			}
+			return fmt.Sprintf("lunar month %d", month)
+		}
+	}
+	switch {
+	case desc.WeekOfYear:
+		part := fmt.Sprintf("in ISO weeks %d-%d", desc.WeekStart, desc.WeekEnd)
+		if desc.WeekStart == desc.WeekEnd {
+			part = fmt.Sprintf("in ISO week %d", desc.WeekStart)
+		}
+		if yearPart != "" {
+			part += " of " + yearPart
+		}
+		parts = append(parts, part)
+	case desc.Quarter || desc.Fiscal && !desc.AllMonths:
+		var part string
+		switch {
+		case desc.Quarter && desc.QuarterStart == desc.QuarterEnd:
+			part = fmt.Sprintf("Q%d", desc.QuarterStart)
+		case desc.Quarter:
+			part = fmt.Sprintf("Q%d-Q%d", desc.QuarterStart, desc.QuarterEnd)
+		case desc.MonthStart == desc.MonthEnd:
+			part = fmt.Sprintf("period %d", desc.MonthStart)
+		default:
+			part = fmt.Sprintf("periods %d-%d", desc.MonthStart, desc.MonthEnd)
+		}
+		switch {
+		case desc.Fiscal:
+			part = "in fiscal " + part
+			if yearPart != "" {
+				part += " of " + yearPart
+			}
+		case desc.singleYear():
+			part = "in " + part + " " + yearPart
+		case yearPart != "":
+			part = "in " + part + " in " + yearPart
+		default:
+			part = "in " + part
+		}
+		parts = append(parts, part)
+	case !desc.AllMonths && desc.MonthStart != desc.MonthEnd:
+		part := fmt.Sprintf("from %s to %s", monthName(desc.MonthStart), monthName(desc.MonthEnd))
+		if desc.singleYear() {
+			part += " " + yearPart
+		} else if yearPart != "" {
+			part += " in " + yearPart
+		}
+		parts = append(parts, part)
+	case !desc.AllMonths:
+		part := "in " + monthName(desc.MonthStart)
+		if desc.singleYear() {
+			part += " " + yearPart
+		} else if yearPart != "" {
+			part += " in " + yearPart
+		}
+		parts = append(parts, part)
+	case desc.Lunar:
+		part := "in every lunar month"
+		if yearPart != "" {
+			part += " in " + yearPart
+		}
+		parts = append(parts, part)
+	case desc.Fiscal:
+		part := "in every fiscal period"
+		if yearPart != "" {
+			part += " of " + yearPart
+		}
+		parts = append(parts, part)
+	case yearPart != "":
+		parts = append(parts, "in "+yearPart)
+	}
+
+	// 日
+	monthWord, dayWord := "month", "day"
+	if desc.Lunar {
+		monthWord, dayWord = "lunar month", "lunar day"
+	}
+	if desc.Fiscal {
+		monthWord = "fiscal period"
+	}
+	switch {
+	case desc.DayOfYear && desc.DayStart == desc.DayEnd:
+		parts = append(parts, fmt.Sprintf("on day %d of the year", desc.DayStart))
+	case desc.DayOfYear:
+		parts = append(parts, fmt.Sprintf("on days %d-%d of the year", desc.DayStart, desc.DayEnd))
+	case desc.BusinessDay:
+		parts = append(parts, "on business days")
+	case desc.SolarTerm && desc.SolarTermStart == desc.SolarTermEnd:
+		parts = append(parts, "on the day of "+desc.SolarTermStart.englishName())
+	case desc.SolarTerm:
+		parts = append(parts, "from "+desc.SolarTermStart.englishName()+" through "+desc.SolarTermEnd.englishName())
+	case desc.ByWeekday && desc.WeekdayNth < 0:
+		parts = append(parts, "on the last "+englishWeekday(desc.DayStart)+" of the month")
+	case desc.ByWeekday && desc.WeekdayNth > 0:
+		parts = append(parts, "on the "+englishOrdinal(desc.WeekdayNth)+" "+englishWeekday(desc.DayStart)+" of the month")
+	case desc.LastDay && desc.NearestWeekday:
+		parts = append(parts, "on the last weekday of the month")
+	case desc.LastDay && desc.LastDayOffset > 0:
+		parts = append(parts, fmt.Sprintf("%d days before the last day of the %s", desc.LastDayOffset, monthWord))
+	case desc.LastDay:
+		parts = append(parts, "on the last day of the "+monthWord)
+	case desc.NearestWeekday:
+		parts = append(parts, fmt.Sprintf("on the weekday nearest day %d", desc.DayStart))
+	case desc.ByWeekday && desc.DayStart == desc.DayEnd:
+		parts = append(parts, "every "+englishWeekday(desc.DayStart))
+	case desc.ByWeekday && desc.DayEnd == desc.DayStart+1:
+		parts = append(parts, "every "+englishWeekday(desc.DayStart)+" and "+englishWeekday(desc.DayEnd))
+	case desc.ByWeekday:
+		parts = append(parts, "every "+englishWeekday(desc.DayStart)+" to "+englishWeekday(desc.DayEnd))
+	case !desc.AllDays && desc.DayStart == desc.DayEnd:
+		parts = append(parts, fmt.Sprintf("on %s %d", dayWord, desc.DayStart))
+	case !desc.AllDays:
+		parts = append(parts, fmt.Sprintf("on %ss %d-%d", dayWord, desc.DayStart, desc.DayEnd))
+	case !desc.AllHours:
+		parts = append(parts, "every day")
+	}
+
+	// 时分秒
+	if !desc.AllHours {
+		ranges := make([]string, 0, len(desc.Hours))
+		for _, hourRange := range desc.Hours {
+			ranges = append(ranges, englishHourPoint(hourRange.Start, hourRange.StartSun)+"-"+englishHourPoint(hourRange.End, hourRange.EndSun))
+		}
+		parts = append(parts, joinList(ranges, ", ", " and "))
+	}
+
+	sentence := strings.Join(parts, ", ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+func englishMonth(month time.Month) string {
+	return month.String()[:3]
+}
+
+// englishOrdinal 英文序数词, etc: 1st, 2nd, 3rd, 4th
+func englishOrdinal(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	}
+	return fmt.Sprintf("%dth", n)
+}
+
+// englishHourPoint 时间段端点的英文, etc: 08:00, sunset, sunset-00:30
+func englishHourPoint(d time.Duration, sun SunEvent) string {
+	if sun == NoSunEvent {
+		return FormatClock(d)
+	}
+	switch {
+	case d < 0:
+		return sun.String() + "-" + FormatClock(-d)
+	case d > 0:
+		return sun.String() + "+" + FormatClock(d)
+	}
+	return sun.String()
+}
+
+// englishWeekday 星期几的英文缩写, 1为周一, 7为周日
+func englishWeekday(weekday int) string {
+	return time.Weekday(weekday % 7).String()[:3]
+}
+
+// chineseLocale 简体中文描述, etc: 2000年2月至4月，每月5日至7日，08:00-10:00和11:00-12:30:30
+type chineseLocale struct{}
+
+func (chineseLocale) Describe(desc *Description) string {
+	if desc.AlwaysActive {
+		return "始终有效"
+	}
+
+	var parts []string
+
+	// 年月
+	var part string
+	yearWord, everyYear := "年", "每年"
+	if desc.Fiscal {
+		yearWord, everyYear = "财年", "每个财年"
+	}
+	if !desc.AllYears {
+		switch {
+		case desc.NoYearEnd:
+			part = fmt.Sprintf("%d%s起", desc.YearStart, yearWord)
+		case desc.NoYearStart:
+			part = fmt.Sprintf("至%d%s", desc.YearEnd, yearWord)
+		case desc.singleYear():
+			part = fmt.Sprintf("%d%s", desc.YearStart, yearWord)
+		default:
+			part = fmt.Sprintf("%d%s至%d%s", desc.YearStart, yearWord, desc.YearEnd, yearWord)
+		}
+	}
+	monthName := func(month time.Month) string {
+		return fmt.Sprintf("%d月", month)
+	}
+	if desc.Lunar {
+		monthName = func(month time.Month) string {
+			if desc.LeapMonth {
+				return "闰" + chineseLunarMonth(month)
+			}
+			return chineseLunarMonth(month)
+		}
+	}
+	if desc.Fiscal {
+		monthName = func(period time.Month) string {
+			return fmt.Sprintf("第%d期", period)
+		}
+	}
+	if desc.Quarter {
+		if !desc.singleYear() {
+			part += everyYear
+		}
+		if desc.QuarterStart == desc.QuarterEnd {
+			part += fmt.Sprintf("第%d季度", desc.QuarterStart)
+		} else {
+			part += fmt.Sprintf("第%d至%d季度", desc.QuarterStart, desc.QuarterEnd)
+		}
+	}
+	if desc.WeekOfYear {
+		if !desc.singleYear() {
+			part += "每年"
+		}
+		if desc.WeekStart == desc.WeekEnd {
+			part += fmt.Sprintf("第%d周", desc.WeekStart)
+		} else {
+			part += fmt.Sprintf("第%d周至第%d周", desc.WeekStart, desc.WeekEnd)
+		}
+	}
+	if !desc.AllMonths && !desc.Quarter {
+		if !desc.singleYear() {
+			part += everyYear
+		}
+		if desc.MonthStart == desc.MonthEnd {
+			part += monthName(desc.MonthStart)
+		} else {
+			part += monthName(desc.MonthStart) + "至" + monthName(desc.MonthEnd)
+		}
+	} else if desc.Lunar {
+		part += "每月"
+	} else if desc.Fiscal && desc.AllYears {
+		part = everyYear
+	}
+	if desc.Lunar {
+		part = "农历" + part
+	}
+	if part != "" {
+		parts = append(parts, part)
+	}
+
+	// 日
+	switch {
+	case desc.DayOfYear:
+		part := fmt.Sprintf("第%d天至第%d天", desc.DayStart, desc.DayEnd)
+		if desc.DayStart == desc.DayEnd {
+			part = fmt.Sprintf("第%d天", desc.DayStart)
+		}
+		if !desc.singleYear() {
+			part = "每年" + part
+		}
+		parts = append(parts, part)
+	case desc.BusinessDay:
+		parts = append(parts, "每个工作日")
+	case desc.SolarTerm && desc.SolarTermStart == desc.SolarTermEnd:
+		parts = append(parts, desc.SolarTermStart.String()+"当天")
+	case desc.SolarTerm:
+		parts = append(parts, desc.SolarTermStart.String()+"至"+desc.SolarTermEnd.String()+"当天")
+	case desc.Fiscal && desc.LastDay && desc.LastDayOffset > 0:
+		parts = append(parts, fmt.Sprintf("每期最后一天的前%d天", desc.LastDayOffset))
+	case desc.Fiscal && desc.LastDay:
+		parts = append(parts, "每期最后一天")
+	case desc.Fiscal && !desc.ByWeekday && !desc.AllDays && desc.DayStart == desc.DayEnd:
+		parts = append(parts, fmt.Sprintf("每期第%d天", desc.DayStart))
+	case desc.Fiscal && !desc.ByWeekday && !desc.AllDays:
+		parts = append(parts, fmt.Sprintf("每期第%d天至第%d天", desc.DayStart, desc.DayEnd))
+	case desc.Lunar && desc.LastDay && desc.LastDayOffset > 0:
+		parts = append(parts, fmt.Sprintf("最后一天的前%d天", desc.LastDayOffset))
+	case desc.Lunar && desc.LastDay:
+		parts = append(parts, "最后一天")
+	case desc.Lunar && !desc.ByWeekday && !desc.AllDays && desc.DayStart == desc.DayEnd:
+		parts = append(parts, chineseLunarDay(desc.DayStart))
+	case desc.Lunar && !desc.ByWeekday && !desc.AllDays:
+		parts = append(parts, chineseLunarDay(desc.DayStart)+"至"+chineseLunarDay(desc.DayEnd))
+	case desc.ByWeekday && desc.WeekdayNth < 0:
+		parts = append(parts, "每月最后一个周"+chineseWeekday(desc.DayStart))
+	case desc.ByWeekday && desc.WeekdayNth > 0:
+		parts = append(parts, fmt.Sprintf("每月第%d个周%s", desc.WeekdayNth, chineseWeekday(desc.DayStart)))
+	case desc.LastDay && desc.NearestWeekday:
+		parts = append(parts, "每月最后一个工作日")
+	case desc.LastDay && desc.LastDayOffset > 0:
+		parts = append(parts, fmt.Sprintf("每月最后一天的前%d天", desc.LastDayOffset))
+	case desc.LastDay:
+		parts = append(parts, "每月最后一天")
+	case desc.NearestWeekday:
+		parts = append(parts, fmt.Sprintf("每月离%d日最近的工作日", desc.DayStart))
+	case desc.ByWeekday && desc.DayStart == desc.DayEnd:
+		parts = append(parts, "每周"+chineseWeekday(desc.DayStart))
+	case desc.ByWeekday && desc.DayEnd == desc.DayStart+1:
+		parts = append(parts, "每周"+chineseWeekday(desc.DayStart)+chineseWeekday(desc.DayEnd))
+	case desc.ByWeekday:
+		parts = append(parts, "每周"+chineseWeekday(desc.DayStart)+"至周"+chineseWeekday(desc.DayEnd))
+	case !desc.AllDays && desc.DayStart == desc.DayEnd:
+		parts = append(parts, fmt.Sprintf("每月%d日", desc.DayStart))
+	case !desc.AllDays:
+		parts = append(parts, fmt.Sprintf("每月%d日至%d日", desc.DayStart, desc.DayEnd))
+	case !desc.AllHours:
+		parts = append(parts, "每天")
+	}
+
+	// 时分秒
+	if !desc.AllHours {
+		ranges := make([]string, 0, len(desc.Hours))
+		for _, hourRange := range desc.Hours {
+			ranges = append(ranges, chineseHourPoint(hourRange.Start, hourRange.StartSun)+"-"+chineseHourPoint(hourRange.End, hourRange.EndSun))
+		}
+		parts = append(parts, joinList(ranges, "、", "和"))
+	}
+
+	return strings.Join(parts, "，")
+}
+
+// chineseHourPoint 时间段端点的中文, etc: 08:00, 日落, 日落前00:30
+func chineseHourPoint(d time.Duration, sun SunEvent) string {
+	if sun == NoSunEvent {
+		return FormatClock(d)
+	}
+	name := map[SunEvent]string{Sunrise: "日出", Sunset: "日落"}[sun]
+	switch {
+	case d < 0:
+		return name + "前" + FormatClock(-d)
+	case d > 0:
+		return name + "后" + FormatClock(d)
+	}
+	return name
+}
+
+// chineseWeekday 星期几的中文, 1为周一, 7为周日
+func chineseWeekday(weekday int) string {
+	return []string{"", "一", "二", "三", "四", "五", "六", "日"}[weekday]
+}
+
+// chineseLunarMonth 农历月份的中文, etc: 正月, 冬月, 腊月
+func chineseLunarMonth(month time.Month) string {
+	return []string{"", "正", "二", "三", "四", "五", "六", "七", "八", "九", "十", "冬", "腊"}[month] + "月"
+}
+
+// chineseLunarDay 农历日的中文, etc: 初一, 十五, 廿三
+func chineseLunarDay(day int) string {
+	digits := []string{"", "一", "二", "三", "四", "五", "六", "七", "八", "九", "十"}
+	switch {
+	case day <= 10:
+		return "初" + digits[day]
+	case day < 20:
+		return "十" + digits[day-10]
+	case day == 20:
+		return "二十"
+	case day < 30:
+		return "廿" + digits[day-20]
+	case day == 30:
+		return "三十"
+	}
+	return fmt.Sprintf("%d日", day)
+}
+
+// joinList 拼接列表, 最后两项使用lastSep, etc: a, b and c
+func joinList(items []string, sep string, lastSep string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], sep) + lastSep + items[len(items)-1]
+}
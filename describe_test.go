@@ -0,0 +1,319 @@
+package timeexpression
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDateTimeExpression_String(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		result string
+	}{
+		{
+			exp:    "[*][*][*][*]",
+			result: "[*][*][*][*]",
+		},
+		{
+			exp:    "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			result: "[2000][02-04][05-07][08:00:00-10:00:00,11:00:00-12:30:30]",
+		},
+		{
+			exp:    "[ 1991-2021 ][03][* ][14:00:00-16:00:00,09:00:00-10:00:00]",
+			result: "[1991-2021][03][*][09:00:00-10:00:00,14:00:00-16:00:00]",
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.result, expr.String())
+	}
+}
+
+func TestDateTimeExpression_Format(t *testing.T) {
+	testDatas := []struct {
+		exp     string
+		numeric string
+		en      string
+		zh      string
+	}{
+		{
+			exp:     "[2024][Jan-Mar][*][*]",
+			numeric: "[2024][01-03][*][*]",
+			en:      "[2024][Jan-Mar][*][*]",
+			zh:      "[2024][一月-三月][*][*]",
+		},
+		{
+			exp:     "[*][*][周一-周五][09:00:00-18:00:00]",
+			numeric: "[*][*][w1-5][09:00:00-18:00:00]",
+			en:      "[*][*][Mon-Fri][09:00:00-18:00:00]",
+			zh:      "[*][*][周一-周五][09:00:00-18:00:00]",
+		},
+		{
+			exp:     "[*][december][friday#3][*]",
+			numeric: "[*][12][w5#3][*]",
+			en:      "[*][Dec][Fri#3][*]",
+			zh:      "[*][十二月][周五#3][*]",
+		},
+		{
+			exp:     "[*][W10][Sun][*]",
+			numeric: "[*][W10][w7][*]",
+			en:      "[*][W10][Sun][*]",
+			zh:      "[*][W10][周日][*]",
+		},
+		{
+			exp:     "[*][*][SunL][*]",
+			numeric: "[*][*][w7L][*]",
+			en:      "[*][*][SunL][*]",
+			zh:      "[*][*][周日L][*]",
+		},
+		{
+			// 农历, 季度和日期不使用名称
+			exp:     "[*][L01][15][*]",
+			numeric: "[*][L01][15][*]",
+			en:      "[*][L01][15][*]",
+			zh:      "[*][L01][15][*]",
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.numeric, expr.String())
+		assert.Equal(t, data.numeric, expr.Format(NameNumeric))
+		assert.Equal(t, data.en, expr.Format(NameEnglish))
+		assert.Equal(t, data.zh, expr.Format(NameChinese))
+		for _, formatted := range []string{data.en, data.zh} {
+			parsed, err := NewDateTimeExpression(formatted)
+			if assert.Nil(t, err, formatted) {
+				assert.Equal(t, data.numeric, parsed.String())
+			}
+		}
+	}
+}
+
+func TestDateTimeExpression_Describe(t *testing.T) {
+	testDatas := []struct {
+		exp string
+		en  string
+		zh  string
+	}{
+		{
+			exp: "[*][*][*][*]",
+			en:  "Always",
+			zh:  "始终有效",
+		},
+		{
+			exp: "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			en:  "From Feb to Apr 2000, on days 5-7, 08:00-10:00 and 11:00-12:30:30",
+			zh:  "2000年2月至4月，每月5日至7日，08:00-10:00和11:00-12:30:30",
+		},
+		{
+			exp: "[2024][03][*][20:00:00-22:00:00]",
+			en:  "In Mar 2024, every day, 20:00-22:00",
+			zh:  "2024年3月，每天，20:00-22:00",
+		},
+		{
+			exp: "[2020-2022][*][*][*]",
+			en:  "In 2020-2022",
+			zh:  "2020年至2022年",
+		},
+		{
+			exp: "[2020-2022][06-08][01][*]",
+			en:  "From Jun to Aug in 2020-2022, on day 1",
+			zh:  "2020年至2022年每年6月至8月，每月1日",
+		},
+		{
+			exp: "[*][12][*][*]",
+			en:  "In Dec",
+			zh:  "每年12月",
+		},
+		{
+			exp: "[2024-][*][*][*]",
+			en:  "In every year from 2024",
+			zh:  "2024年起",
+		},
+		{
+			exp: "[-2030][03][*][20:00:00-22:00:00]",
+			en:  "In Mar in every year until 2030, every day, 20:00-22:00",
+			zh:  "至2030年每年3月，每天，20:00-22:00",
+		},
+		{
+			exp: "[2024-][FQ2][*][*]",
+			en:  "In fiscal Q2 of every fiscal year from FY2024",
+			zh:  "2024财年起每个财年第2季度",
+		},
+		{
+			exp: "[*][*][w5#3][10:00:00-11:00:00]",
+			en:  "On the 3rd Fri of the month, 10:00-11:00",
+			zh:  "每月第3个周五，10:00-11:00",
+		},
+		{
+			exp: "[*][*][w5L][*]",
+			en:  "On the last Fri of the month",
+			zh:  "每月最后一个周五",
+		},
+		{
+			exp: "[*][03][L][*]",
+			en:  "In Mar, on the last day of the month",
+			zh:  "每年3月，每月最后一天",
+		},
+		{
+			exp: "[*][*][L-2][*]",
+			en:  "2 days before the last day of the month",
+			zh:  "每月最后一天的前2天",
+		},
+		{
+			exp: "[*][*][LW][*]",
+			en:  "On the last weekday of the month",
+			zh:  "每月最后一个工作日",
+		},
+		{
+			exp: "[*][*][15W][*]",
+			en:  "On the weekday nearest day 15",
+			zh:  "每月离15日最近的工作日",
+		},
+		{
+			exp: "[*][L01][01-15][*]",
+			en:  "In lunar month 1, on lunar days 1-15",
+			zh:  "农历每年正月，初一至十五",
+		},
+		{
+			exp: "[*][L12][L][20:00:00-24:00:00]",
+			en:  "In lunar month 12, on the last day of the lunar month, 20:00-24:00",
+			zh:  "农历每年腊月，最后一天，20:00-24:00",
+		},
+		{
+			exp: "[2025][L06bis][*][*]",
+			en:  "In leap lunar month 6 2025",
+			zh:  "农历2025年闰六月",
+		},
+		{
+			exp: "[*][L*][15][*]",
+			en:  "In every lunar month, on lunar day 15",
+			zh:  "农历每月，十五",
+		},
+		{
+			exp: "[*][*][立春][10:00:00-12:00:00]",
+			en:  "On the day of Start of Spring, 10:00-12:00",
+			zh:  "立春当天，10:00-12:00",
+		},
+		{
+			exp: "[2024][*][立冬-立春][*]",
+			en:  "In 2024, from Start of Winter through Start of Spring",
+			zh:  "2024年，立冬至立春当天",
+		},
+		{
+			exp: "[2024][W10-20][w1-5][*]",
+			en:  "In ISO weeks 10-20 of 2024, every Mon to Fri",
+			zh:  "2024年第10周至第20周，每周一至周五",
+		},
+		{
+			exp: "[*][W53][*][*]",
+			en:  "In ISO week 53",
+			zh:  "每年第53周",
+		},
+		{
+			exp: "[*][*][D100-200][*]",
+			en:  "On days 100-200 of the year",
+			zh:  "每年第100天至第200天",
+		},
+		{
+			exp: "[2024][*][D060][10:00:00-12:00:00]",
+			en:  "In 2024, on day 60 of the year, 10:00-12:00",
+			zh:  "2024年，第60天，10:00-12:00",
+		},
+		{
+			exp: "[2024][Q2][*][*]",
+			en:  "In Q2 2024",
+			zh:  "2024年第2季度",
+		},
+		{
+			exp: "[2024-2025][Q1-3][15][*]",
+			en:  "In Q1-Q3 in 2024-2025, on day 15",
+			zh:  "2024年至2025年每年第1至3季度，每月15日",
+		},
+		{
+			exp: "[2024][FQ2][*][09:00:00-18:00:00]",
+			en:  "In fiscal Q2 of FY2024, every day, 09:00-18:00",
+			zh:  "2024财年第2季度，每天，09:00-18:00",
+		},
+		{
+			exp: "[2024-2025][F01-03][01-07][*]",
+			en:  "In fiscal periods 1-3 of FY2024-2025, on days 1-7",
+			zh:  "2024财年至2025财年每个财年第1期至第3期，每期第1天至第7天",
+		},
+		{
+			exp: "[*][F*][L][*]",
+			en:  "In every fiscal period, on the last day of the fiscal period",
+			zh:  "每个财年，每期最后一天",
+		},
+		{
+			exp: "[*][*][bd][09:00:00-18:00:00]",
+			en:  "On business days, 09:00-18:00",
+			zh:  "每个工作日，09:00-18:00",
+		},
+		{
+			exp: "[*][*][*][sunset-00:30:00-sunset+02:00:00,sunrise-09:00:00]",
+			en:  "Every day, sunset-00:30-sunset+02:00 and sunrise-09:00",
+			zh:  "每天，日落前00:30-日落后02:00和日出-09:00",
+		},
+		{
+			exp: "[*][*][*][12:00:00.500-12:00:05.250]",
+			en:  "Every day, 12:00:00.500-12:00:05.250",
+			zh:  "每天，12:00:00.500-12:00:05.250",
+		},
+		{
+			exp: "[*][*][*][01:00:00-02:00:00,03:00:00-04:00:00,05:00:00-24:00:00]",
+			en:  "Every day, 01:00-02:00, 03:00-04:00 and 05:00-24:00",
+			zh:  "每天，01:00-02:00、03:00-04:00和05:00-24:00",
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp, WithCalendar(NewCalendar()), WithGeoLocation(39.9, 116.4, 0),
+			WithFiscalCalendar(FiscalCalendar{StartMonth: time.April, Pattern: Fiscal445, WeekStart: time.Monday}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		en, err := expr.Describe("en")
+		assert.Nil(t, err)
+		assert.Equal(t, data.en, en)
+
+		zh, err := expr.Describe("zh-CN")
+		assert.Nil(t, err)
+		assert.Equal(t, data.zh, zh)
+	}
+}
+
+type testLocale struct{}
+
+func (testLocale) Describe(desc *Description) string {
+	return fmt.Sprintf("%d-%d", desc.YearStart, desc.YearEnd)
+}
+
+func TestRegisterLocale(t *testing.T) {
+	expr, err := NewDateTimeExpression("[2000-2001][*][*][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = expr.Describe("xx")
+	assert.Equal(t, ErrUnknownLocale, err)
+
+	RegisterLocale("XX", testLocale{})
+	result, err := expr.Describe("xx")
+	assert.Nil(t, err)
+	assert.Equal(t, "2000-2001", result)
+}
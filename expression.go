@@ -1 +1,19 @@
 package timeexpression
+
+import "time"
+
+// Expression 时间表达式的通用接口
+// DateTimeExpression 以及由其他格式(iCalendar等)生成的表达式都实现了该接口
+// 周期都遵循左闭右开原则
+type Expression interface {
+	// IsIn 判断时间是否在表达式指定范围内
+	IsIn(t time.Time) bool
+	// GetStartTime 获取开始时间, 在周期内返回本次周期的开始时间, 否则返回下次周期的开始时间
+	GetStartTime(t time.Time) (time.Time, error)
+	// GetEndTime 获取结束时间, 在周期内返回本次周期的结束时间, 否则返回下次周期的结束时间
+	GetEndTime(t time.Time) (time.Time, error)
+	// GetNextStartTime 获取下次开始时间,不管是否在周期内，都获取下次的时间
+	GetNextStartTime(t time.Time) (time.Time, error)
+}
+
+var _ Expression = (*DateTimeExpression)(nil)
@@ -0,0 +1,162 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrFiscalCalendarFormat 财年的定义不对
+	ErrFiscalCalendarFormat = errors.New("fiscal calendar format not math")
+	// ErrNoFiscalCalendar 表达式使用了财年(F开头的月), 但是没有通过WithFiscalCalendar设置财年
+	ErrNoFiscalCalendar = errors.New("expression needs a fiscal calendar")
+)
+
+// FiscalPattern 财年中每个季度的三期如何划分
+type FiscalPattern int
+
+const (
+	// FiscalMonths 每期为一个公历月
+	FiscalMonths FiscalPattern = iota
+	// Fiscal445 每个季度为4周, 4周, 5周
+	Fiscal445
+	// Fiscal454 每个季度为4周, 5周, 4周
+	Fiscal454
+	// Fiscal544 每个季度为5周, 4周, 4周
+	Fiscal544
+)
+
+// weeks 每个季度中三期的周数
+func (pattern FiscalPattern) weeks() [3]int {
+	switch pattern {
+	case Fiscal445:
+		return [3]int{4, 4, 5}
+	case Fiscal454:
+		return [3]int{4, 5, 4}
+	case Fiscal544:
+		return [3]int{5, 4, 4}
+	}
+	return [3]int{}
+}
+
+// String 格式化为months, 4-4-5, 4-5-4或者5-4-4
+func (pattern FiscalPattern) String() string {
+	if pattern == FiscalMonths {
+		return "months"
+	}
+	weeks := pattern.weeks()
+	return fmt.Sprintf("%d-%d-%d", weeks[0], weeks[1], weeks[2])
+}
+
+// FiscalCalendar 财年的定义, 一个财年有4个季度, 12期
+// 按周划分时每个财年为52周, 从StartMonth的1日最近的WeekStart开始, 多出的第53周算在最后一期
+type FiscalCalendar struct {
+	// StartMonth 财年开始的月份
+	StartMonth time.Month
+	// Pattern 每期的划分方式
+	Pattern FiscalPattern
+	// WeekStart 按周划分时每周从星期几开始
+	WeekStart time.Weekday
+	// EndYearNaming 为true时财年以结束时所在的公历年命名, 例如4月开始时2024-04到2025-03为2025财年, 否则为2024财年
+	EndYearNaming bool
+}
+
+// FiscalDate 财年中的日期
+type FiscalDate struct {
+	Year    int
+	Quarter int
+	// Period 财年中的第几期, 1-12
+	Period int
+	// Day 这一期中的第几天, 从1开始
+	Day int
+}
+
+// check 检查财年的定义
+func (calendar *FiscalCalendar) check() error {
+	if calendar.StartMonth < time.January || calendar.StartMonth > time.December ||
+		calendar.Pattern < FiscalMonths || calendar.Pattern > Fiscal544 ||
+		calendar.WeekStart < time.Sunday || calendar.WeekStart > time.Saturday {
+		return fmt.Errorf("%w: start month %d, pattern %d, week start %d", ErrFiscalCalendarFormat,
+			calendar.StartMonth, calendar.Pattern, calendar.WeekStart)
+	}
+	return nil
+}
+
+// yearName 从startYear开始的财年的名称
+func (calendar *FiscalCalendar) yearName(startYear int) int {
+	if calendar.EndYearNaming && calendar.StartMonth != time.January {
+		return startYear + 1
+	}
+	return startYear
+}
+
+// startOf 在公历startYear年开始的财年的第一天
+func (calendar *FiscalCalendar) startOf(startYear int) time.Time {
+	first := time.Date(startYear, calendar.StartMonth, 1, 0, 0, 0, 0, time.Local)
+	if calendar.Pattern == FiscalMonths {
+		return first
+	}
+	// 离1日最近的WeekStart
+	diff := (int(calendar.WeekStart) - int(first.Weekday()) + 7) % 7
+	if diff > 3 {
+		diff -= 7
+	}
+	return first.AddDate(0, 0, diff)
+}
+
+// YearStart 财年year的第一天
+func (calendar *FiscalCalendar) YearStart(year int) time.Time {
+	if calendar.EndYearNaming && calendar.StartMonth != time.January {
+		year--
+	}
+	return calendar.startOf(year)
+}
+
+// ToFiscal 把时间t所在的日期转换为财年中的日期
+// 只使用t的年月日, 夏令时从0点开始的时区中当天的0点不存在, 不能先取0点
+func (calendar *FiscalCalendar) ToFiscal(t time.Time) FiscalDate {
+	startYear := t.Year()
+	if daysBetween(calendar.startOf(startYear), t) < 0 {
+		startYear--
+	} else if daysBetween(calendar.startOf(startYear+1), t) >= 0 {
+		startYear++
+	}
+	date := FiscalDate{Year: calendar.yearName(startYear)}
+
+	if calendar.Pattern == FiscalMonths {
+		date.Period = (int(t.Month())-int(calendar.StartMonth)+12)%12 + 1
+		date.Day = t.Day()
+	} else {
+		offset := daysBetween(calendar.startOf(startYear), t)
+		weeks := calendar.Pattern.weeks()
+		for date.Period = 1; date.Period < 12; date.Period++ {
+			days := weeks[(date.Period-1)%3] * 7
+			if offset < days {
+				break
+			}
+			offset -= days
+		}
+		date.Day = offset + 1
+	}
+	date.Quarter = (date.Period-1)/3 + 1
+	return date
+}
+
+// periodDays 财年中日期所在的这一期的天数
+func (calendar *FiscalCalendar) periodDays(t time.Time, date FiscalDate) int {
+	if calendar.Pattern == FiscalMonths {
+		return daysIn(t.Month(), t.Year())
+	}
+	if date.Period == 12 {
+		// 最后一期到下一个财年开始
+		periodStart := time.Date(t.Year(), t.Month(), t.Day()+1-date.Day, 0, 0, 0, 0, time.UTC)
+		return daysBetween(periodStart, calendar.YearStart(date.Year+1))
+	}
+	return calendar.Pattern.weeks()[(date.Period-1)%3] * 7
+}
+
+// yearRange 财年范围内的第一天和最后一天
+func (calendar *FiscalCalendar) yearRange(startYear int, endYear int) (first time.Time, last time.Time) {
+	return calendar.YearStart(startYear), calendar.YearStart(endYear+1).AddDate(0, 0, -1)
+}
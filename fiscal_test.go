@@ -0,0 +1,269 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// retail 4-4-5, 从离4月1日最近的周一开始
+var retail = FiscalCalendar{StartMonth: time.April, Pattern: Fiscal445, WeekStart: time.Monday}
+
+func TestFiscalCalendar_ToFiscal(t *testing.T) {
+	testDatas := []struct {
+		calendar FiscalCalendar
+		t        time.Time
+		date     FiscalDate
+	}{
+		// 按公历月划分
+		{calendar: FiscalCalendar{StartMonth: time.April}, t: time.Date(2024, time.April, 15, 12, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 1, Day: 15}},
+		{calendar: FiscalCalendar{StartMonth: time.April}, t: time.Date(2025, time.March, 31, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 4, Period: 12, Day: 31}},
+		{calendar: FiscalCalendar{StartMonth: time.April, EndYearNaming: true}, t: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2025, Quarter: 1, Period: 1, Day: 1}},
+		{calendar: FiscalCalendar{StartMonth: time.October, EndYearNaming: true}, t: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 2, Period: 4, Day: 10}},
+		{calendar: FiscalCalendar{StartMonth: time.January, EndYearNaming: true}, t: time.Date(2024, time.December, 31, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 4, Period: 12, Day: 31}},
+		// 4-4-5, 2024-04-01为周一, 2025财年从2025-03-31开始
+		{calendar: retail, t: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 1, Day: 1}},
+		{calendar: retail, t: time.Date(2024, time.April, 29, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 2, Day: 1}},
+		{calendar: retail, t: time.Date(2024, time.June, 30, 23, 59, 59, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 3, Day: 35}},
+		{calendar: retail, t: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 2, Period: 4, Day: 1}},
+		{calendar: retail, t: time.Date(2025, time.March, 30, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 4, Period: 12, Day: 35}},
+		{calendar: retail, t: time.Date(2025, time.March, 31, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2025, Quarter: 1, Period: 1, Day: 1}},
+		// 2027财年有53周, 多出的一周在最后一期
+		{calendar: retail, t: time.Date(2028, time.April, 2, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2027, Quarter: 4, Period: 12, Day: 42}},
+		{calendar: FiscalCalendar{StartMonth: time.April, Pattern: Fiscal544, WeekStart: time.Monday}, t: time.Date(2024, time.May, 6, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 2, Day: 1}},
+		{calendar: FiscalCalendar{StartMonth: time.April, Pattern: Fiscal454, WeekStart: time.Monday}, t: time.Date(2024, time.June, 2, 0, 0, 0, 0, time.Local),
+			date: FiscalDate{Year: 2024, Quarter: 1, Period: 2, Day: 35}},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] calendar:%s t:%s\n", i, data.calendar.Pattern, data.t)
+		assert.Equal(t, data.date, data.calendar.ToFiscal(data.t))
+	}
+}
+
+// TestFiscalCalendar_All 逐天检查, 财年中的日期连续递增, 每期的天数和periodDays一致
+func TestFiscalCalendar_All(t *testing.T) {
+	for _, calendar := range []FiscalCalendar{
+		{StartMonth: time.April},
+		{StartMonth: time.October, EndYearNaming: true},
+		retail,
+		{StartMonth: time.February, Pattern: Fiscal454, WeekStart: time.Sunday, EndYearNaming: true},
+		{StartMonth: time.July, Pattern: Fiscal544, WeekStart: time.Saturday},
+	} {
+		// 按中午逐天遍历, 期的第一天用time.Date构造, 夏令时从0点开始的时区中0点加一天可能还是同一天
+		start := calendar.YearStart(2000)
+		day := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, time.Local)
+		pre := calendar.ToFiscal(day)
+		if !assert.Equal(t, FiscalDate{Year: 2000, Quarter: 1, Period: 1, Day: 1}, pre) {
+			continue
+		}
+		weeks53 := 0
+		for day = day.AddDate(0, 0, 1); day.Year() < 2050; day = day.AddDate(0, 0, 1) {
+			date := calendar.ToFiscal(day)
+			preDays := calendar.periodDays(day.AddDate(0, 0, -1), pre)
+			switch {
+			case date.Day > 1:
+				assert.True(t, date.Year == pre.Year && date.Period == pre.Period && date.Day == pre.Day+1, "%v after %v", date, pre)
+			case date.Period == 1:
+				assert.True(t, date.Year == pre.Year+1 && pre.Period == 12 && pre.Day == preDays, "%v after %v", date, pre)
+				assert.Equal(t, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local), calendar.YearStart(date.Year))
+				if daysBetween(calendar.YearStart(pre.Year), day) == 53*7 {
+					weeks53++
+				}
+			default:
+				assert.True(t, date.Year == pre.Year && date.Period == pre.Period+1 && pre.Day == preDays, "%v after %v", date, pre)
+			}
+			if !assert.Equal(t, (date.Period-1)/3+1, date.Quarter) {
+				return
+			}
+			pre = date
+		}
+		if calendar.Pattern != FiscalMonths {
+			// 50年中大约有9个53周的年
+			assert.True(t, weeks53 >= 8 && weeks53 <= 10, "%d years with 53 weeks", weeks53)
+		}
+	}
+}
+
+func TestDateTimeExpression_Fiscal(t *testing.T) {
+	monthly := FiscalCalendar{StartMonth: time.April, EndYearNaming: true}
+	testDatas := []struct {
+		exp      string
+		calendar FiscalCalendar
+		t        time.Time
+		isIn     bool
+		start    time.Time
+		end      time.Time
+	}{
+		{
+			// 第二季度为2024-07-01到2024-09-29
+			exp:      "[2024][FQ2][*][*]",
+			calendar: retail,
+			t:        time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local),
+			start:    time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2024, time.September, 30, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[2024][F03][L][*]",
+			calendar: retail,
+			t:        time.Date(2024, time.June, 30, 12, 0, 0, 0, time.Local),
+			isIn:     true,
+			start:    time.Date(2024, time.June, 30, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 财年的年: 2024财年的最后一期在2025年
+			exp:      "[2024][F12][*][*]",
+			calendar: retail,
+			t:        time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local),
+			start:    time.Date(2025, time.February, 24, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2025, time.March, 31, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[2024][FQ3][w1][09:00:00-10:00:00]",
+			calendar: retail,
+			t:        time.Date(2024, time.September, 1, 0, 0, 0, 0, time.Local),
+			start:    time.Date(2024, time.September, 30, 9, 0, 0, 0, time.Local),
+			end:      time.Date(2024, time.September, 30, 10, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[*][F*][01-07][*]",
+			calendar: retail,
+			t:        time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local),
+			isIn:     true,
+			start:    time.Date(2024, time.April, 29, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2024, time.May, 6, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 以结束的年命名, 2025财年从2024-04-01开始
+			exp:      "[2025][F01][01][*]",
+			calendar: monthly,
+			t:        time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start:    time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2024, time.April, 2, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:      "[*][FQ4][bd][*]",
+			calendar: monthly,
+			t:        time.Date(2024, time.December, 31, 0, 0, 0, 0, time.Local),
+			start:    time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+			end:      time.Date(2025, time.January, 4, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp, WithFiscalCalendar(data.calendar), WithCalendar(NewCalendar()))
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	expr, err := NewDateTimeExpression("[2024][F*][*][*]", WithFiscalCalendar(retail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local), first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 31, 0, 0, 0, 0, time.Local), final)
+}
+
+func TestDateTimeExpression_Quarter(t *testing.T) {
+	// 公历的季度等同于对应的月份
+	for _, pair := range [][2]string{
+		{"[2024][Q2][*][*]", "[2024][04-06][*][*]"},
+		{"[*][Q1-3][15][10:00:00-12:00:00]", "[*][01-09][15][10:00:00-12:00:00]"},
+		{"[2024-2025][Q4][L][*]", "[2024-2025][10-12][L][*]"},
+	} {
+		fmt.Printf("exp:%s\n", pair[0])
+		quarter, err := NewDateTimeExpression(pair[0])
+		if !assert.Nil(t, err) {
+			continue
+		}
+		month, err := NewDateTimeExpression(pair[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, pair[0], quarter.String())
+		for _, tm := range []time.Time{
+			time.Date(2024, time.February, 15, 11, 0, 0, 0, time.Local),
+			time.Date(2024, time.June, 30, 0, 0, 0, 0, time.Local),
+			time.Date(2024, time.November, 30, 23, 0, 0, 0, time.Local),
+		} {
+			assert.Equal(t, month.IsIn(tm), quarter.IsIn(tm))
+			quarterStart, quarterErr := quarter.GetStartTime(tm)
+			monthStart, monthErr := month.GetStartTime(tm)
+			assert.Equal(t, monthErr, quarterErr)
+			assert.Equal(t, monthStart, quarterStart)
+		}
+		quarterCron, quarterErr := quarter.ToCron(false)
+		monthCron, monthErr := month.ToCron(false)
+		assert.Equal(t, monthErr, quarterErr)
+		assert.Equal(t, monthCron, quarterCron)
+	}
+}
+
+func TestDateTimeExpression_FiscalError(t *testing.T) {
+	fiscal := WithFiscalCalendar(retail)
+	testDatas := []struct {
+		exp  string
+		opts []Option
+		err  error
+	}{
+		{exp: "[2024][FQ2][*][*]", err: ErrNoFiscalCalendar},
+		{exp: "[2024][FQ2][*][*]", opts: []Option{WithFiscalCalendar(FiscalCalendar{})}, err: ErrFiscalCalendarFormat},
+		{exp: "[2024][FQ2][*][*]", opts: []Option{WithFiscalCalendar(FiscalCalendar{StartMonth: time.April, Pattern: 4})}, err: ErrFiscalCalendarFormat},
+		{exp: "[*][F01][15W][*]", opts: []Option{fiscal}, err: ErrDayFormat},
+		{exp: "[*][F01][w1#2][*]", opts: []Option{fiscal}, err: ErrDayFormat},
+		{exp: "[*][F*][D100][*]", opts: []Option{fiscal}, err: ErrDayFormat},
+		{exp: "[*][Q2][D100][*]", err: ErrMonthFormat},
+		{exp: "[*][FW10][*][*]", opts: []Option{fiscal}, err: ErrMonthFormat},
+		{exp: "[*][FL01][*][*]", opts: []Option{fiscal}, err: ErrMonthFormat},
+		{exp: "[*][F13][*][*]", opts: []Option{fiscal}},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		_, err := NewDateTimeExpression(data.exp, data.opts...)
+		if data.err == nil {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+	}
+
+	expr, err := NewDateTimeExpression("[2024][FQ2][*][*]", fiscal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.ToCron(false)
+	assert.True(t, errors.Is(err, ErrCronLossy))
+	_, err = expr.ToOpeningHours()
+	assert.True(t, errors.Is(err, ErrOpeningHoursLossy))
+}
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 )
 
 //hourExpression 解析小时的表达式
 type hourExpression struct {
 	hourUnits []*hourUnitExpression
 	isAll     bool
+	// hasSun 有以日出或者日落为端点的时间段, 每天的时间段需要按日出日落计算
+	hasSun bool
 }
 
 // newHourExpression 格式为 *,hh:mm:ss-hh:mm:ss[,hh:mm:ss-hh:mm:ss]...
@@ -18,6 +21,7 @@ func newHourExpression(hourStr string) (*hourExpression, error) {
 
 	var hourUnits []*hourUnitExpression
 	isAll := false
+	hasSun := false
 	splitHourStr := strings.Split(hourStr, ",")
 	for _, splitStr := range splitHourStr {
 		unitExpression, err := newHourUnitExpression(splitStr)
@@ -27,18 +31,25 @@ func newHourExpression(hourStr string) (*hourExpression, error) {
 		if unitExpression.isAll {
 			isAll = true
 		}
+		if unitExpression.isSun() {
+			hasSun = true
+		}
 
 		hourUnits = append(hourUnits, unitExpression)
 	}
 
-	// 简单对开始时间排序(从小到大)
-	sort.Slice(hourUnits, func(i, j int) bool {
-		return hourUnits[i].start.toSec() < hourUnits[j].start.toSec()
+	// 简单对开始时间排序(从小到大), 以日出日落为端点的保持原来的顺序放在最后
+	sort.SliceStable(hourUnits, func(i, j int) bool {
+		if hourUnits[i].isSun() || hourUnits[j].isSun() {
+			return !hourUnits[i].isSun() && hourUnits[j].isSun()
+		}
+		return hourUnits[i].start.toDuration() < hourUnits[j].start.toDuration()
 	})
 
 	expression := &hourExpression{
 		hourUnits: hourUnits,
 		isAll:     isAll,
+		hasSun:    hasSun,
 	}
 
 	err := expression.check()
@@ -56,21 +67,41 @@ func (expression *hourExpression) check() error {
 	// 时间已经被排序过了
 	var preUnit *hourUnitExpression
 	for _, unit := range expression.hourUnits {
-		if preUnit == nil {
-			preUnit = unit
-		} else {
-			if preUnit.end.toSec() >= unit.start.toSec() {
+		if unit.isSun() {
+			// 日出日落每天都在变化, 重叠的部分在计算时合并, 但不能和*一起使用
+			if expression.isAll {
 				return errors.New("hour error: time overlapping")
 			}
+			continue
 		}
+		if preUnit != nil && preUnit.end.toDuration() >= unit.start.toDuration() {
+			return errors.New("hour error: time overlapping")
+		}
+		preUnit = unit
 	}
 
 	return nil
 }
 
-func (expression *hourExpression) isIn(hour, minute, sec int) bool {
+// sunWindows 计算day这一天开始的所有时间段, 以日出日落为端点的按location当天的日出日落计算
+// 结束不晚于开始的时间段结束在第二天, 当天没有日出或者日落时跳过依赖它的时间段
+func (expression *hourExpression) sunWindows(day time.Time, location GeoLocation) []window {
+	var windows []window
+	for _, unit := range expression.hourUnits {
+		if !unit.isSun() {
+			windows = append(windows, window{start: unitTime(day, unit.start), end: unitTime(day, unit.end)})
+			continue
+		}
+		if w, ok := unit.resolve(day, location); ok {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+func (expression *hourExpression) isIn(hour, minute, sec, nsec int) bool {
 	for _, unitExpression := range expression.hourUnits {
-		if unitExpression.isIn(hour, minute, sec) {
+		if unitExpression.isIn(hour, minute, sec, nsec) {
 			return true
 		}
 	}
@@ -83,11 +114,11 @@ func (expression *hourExpression) isIn(hour, minute, sec int) bool {
 // 2. 如果在周期外
 //    在开始前,返回开始的时分秒
 //    在范围后，则返回错误
-func (expression *hourExpression) getStart(hour, min, sec int) (hourUint hourUnit, addDay bool, err error) {
+func (expression *hourExpression) getStart(hour, min, sec, nsec int) (hourUint hourUnit, addDay bool, err error) {
 
 	// 尝试是否有范围内的
 	for _, unit := range expression.hourUnits {
-		if unit.isIn(hour, min, sec) {
+		if unit.isIn(hour, min, sec, nsec) {
 			return unit.start, false, nil
 		}
 	}
@@ -97,19 +128,20 @@ func (expression *hourExpression) getStart(hour, min, sec int) (hourUint hourUni
 		Hour:   hour,
 		Minute: min,
 		Sec:    sec,
+		Nsec:   nsec,
 	}
-	paramSec := paramUnit.toSec()
+	param := paramUnit.toDuration()
 	var targetUnit *hourUnit
 	var minUnit *hourUnit
 	for _, unit := range expression.hourUnits {
-		if minUnit == nil || minUnit.toSec() > unit.start.toSec() {
+		if minUnit == nil || minUnit.toDuration() > unit.start.toDuration() {
 			minUnit = &unit.start
 		}
 
-		if paramSec >= unit.end.toSec() {
+		if param >= unit.end.toDuration() {
 			continue
 		}
-		if targetUnit == nil || targetUnit.toSec() > unit.start.toSec() {
+		if targetUnit == nil || targetUnit.toDuration() > unit.start.toDuration() {
 			targetUnit = &unit.start
 		}
 
@@ -131,10 +163,10 @@ func (expression *hourExpression) getStart(hour, min, sec int) (hourUint hourUni
 // 2. 如果在周期外
 //    在结束前,返回结束的时分秒
 //    在范围后，则返回错误
-func (expression *hourExpression) getEnd(hour, min, sec int) (hourUint hourUnit, addDay bool, err error) {
+func (expression *hourExpression) getEnd(hour, min, sec, nsec int) (hourUint hourUnit, addDay bool, err error) {
 	// 尝试是否有范围内的
 	for _, unit := range expression.hourUnits {
-		if unit.isIn(hour, min, sec) {
+		if unit.isIn(hour, min, sec, nsec) {
 			return unit.end, false, nil
 		}
 	}
@@ -144,19 +176,20 @@ func (expression *hourExpression) getEnd(hour, min, sec int) (hourUint hourUnit,
 		Hour:   hour,
 		Minute: min,
 		Sec:    sec,
+		Nsec:   nsec,
 	}
-	paramSec := paramUnit.toSec()
+	param := paramUnit.toDuration()
 	var targetUnit *hourUnit
 	var minUnit *hourUnit
 	for _, unit := range expression.hourUnits {
-		if minUnit == nil || minUnit.toSec() > unit.end.toSec() {
+		if minUnit == nil || minUnit.toDuration() > unit.end.toDuration() {
 			minUnit = &unit.end
 		}
 
-		if paramSec > unit.end.toSec() {
+		if param > unit.end.toDuration() {
 			continue
 		}
-		if targetUnit == nil || targetUnit.toSec() > unit.end.toSec() {
+		if targetUnit == nil || targetUnit.toDuration() > unit.end.toDuration() {
 			targetUnit = &unit.end
 		}
 
@@ -172,3 +205,46 @@ func (expression *hourExpression) getEnd(hour, min, sec int) (hourUint hourUnit,
 
 	return *minUnit, true, nil
 }
+
+// String 格式化为*,hh:mm:ss-hh:mm:ss[,hh:mm:ss-hh:mm:ss]...
+func (expression *hourExpression) String() string {
+	if expression.isAll {
+		return "*"
+	}
+	units := make([]string, 0, len(expression.hourUnits))
+	for _, unit := range expression.hourUnits {
+		units = append(units, unit.String())
+	}
+	return strings.Join(units, ",")
+}
+
+// rebase 把钟表上的时间转换为距离逻辑上一天开始的时间, dayStart为一天开始时距离0点的秒数
+// 跨过一天开始时间的时间段会被拆分为两段, etc: 一天从05:00开始时, 03:00:00-06:00:00 转换为 00:00:00-01:00:00,22:00:00-24:00:00
+func (expression *hourExpression) rebase(dayStart int) *hourExpression {
+	if expression.isAll || dayStart == 0 {
+		return expression
+	}
+
+	var hourUnits []*hourUnitExpression
+	day := time.Duration(secondsPerDay) * time.Second
+	offset := time.Duration(dayStart) * time.Second
+	for _, unit := range expression.hourUnits {
+		start := (unit.start.toDuration() - offset + day) % day
+		end := (unit.end.toDuration() - offset + day) % day
+		if end == 0 {
+			end = day
+		}
+		if start < end {
+			hourUnits = append(hourUnits, &hourUnitExpression{start: durationToHourUnit(start), end: durationToHourUnit(end)})
+			continue
+		}
+		hourUnits = append(hourUnits,
+			&hourUnitExpression{start: durationToHourUnit(start), end: durationToHourUnit(day)},
+			&hourUnitExpression{start: durationToHourUnit(0), end: durationToHourUnit(end)})
+	}
+	sort.Slice(hourUnits, func(i, j int) bool {
+		return hourUnits[i].start.toDuration() < hourUnits[j].start.toDuration()
+	})
+
+	return &hourExpression{hourUnits: hourUnits}
+}
@@ -34,6 +34,17 @@ func TestHourExpression_new(t *testing.T) {
 			err:   errors.New("hour error: time overlapping"),
 			isAll: false,
 		},
+		{
+			// 每个时间段都和前一个比较, 不只是第一个
+			exp:   "08:00:00-09:00:00,10:00:00-11:00:00,10:30:00-12:00:00",
+			err:   errors.New("hour error: time overlapping"),
+			isAll: false,
+		},
+		{
+			exp:   "08:00:00-09:00:00,10:00:00-11:00:00,11:00:00-12:00:00",
+			err:   errors.New("hour error: time overlapping"),
+			isAll: false,
+		},
 	}
 
 	for i, data := range testDatas {
@@ -74,9 +85,14 @@ func TestHourExpression_IsIn(t *testing.T) {
 			result: false,
 		},
 		{
-			t:      "23:30:00",
+			t:      "23:29:59",
 			result: true,
 		},
+		{
+			// 左闭右开, 结束时间不在范围内
+			t:      "23:30:00",
+			result: false,
+		},
 	}
 
 	testIsInHour(t, expression, testDatas)
@@ -90,7 +106,7 @@ func testIsInHour(t *testing.T, expression *hourExpression, testDatas []testData
 			t.Fatal(err)
 		}
 
-		in := expression.isIn(hourTime.Hour(), hourTime.Minute(), hourTime.Second())
+		in := expression.isIn(hourTime.Hour(), hourTime.Minute(), hourTime.Second(), hourTime.Nanosecond())
 
 		if in != data.result {
 			t.Errorf("want[%v] but get[%v]", data.result, in)
@@ -163,7 +179,7 @@ func TestHourExpression_GetStart(t *testing.T) {
 			panic(err)
 		}
 
-		startHourUnit, addDay, err := exp.getStart(data.inputHour, data.inputMin, data.inputSec)
+		startHourUnit, addDay, err := exp.getStart(data.inputHour, data.inputMin, data.inputSec, 0)
 		if data.err == nil {
 			assert.NoError(t, err)
 		} else {
@@ -240,7 +256,7 @@ func TestHourExpression_GetEnd(t *testing.T) {
 			panic(err)
 		}
 
-		endHourUnit, addDay, err := exp.getEnd(data.inputHour, data.inputMin, data.inputSec)
+		endHourUnit, addDay, err := exp.getEnd(data.inputHour, data.inputMin, data.inputSec, 0)
 		if data.err == nil {
 			assert.NoError(t, err)
 		} else {
@@ -1,6 +1,7 @@
 package timeexpression
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -11,9 +12,11 @@ type hourUnit struct {
 	Hour   int
 	Minute int
 	Sec    int
+	// Nsec 秒以下的纳秒
+	Nsec int
 }
 
-// newHourTimeUnit 表达式要为hh:mm:ss
+// newHourTimeUnit 表达式要为hh:mm:ss[.fffffffff], 秒可以带1到9位的小数, etc: 12:00:00.500
 func newHourTimeUnit(unitStr string) (hourUnit, error) {
 	var err error
 	unit := hourUnit{}
@@ -30,10 +33,24 @@ func newHourTimeUnit(unitStr string) (hourUnit, error) {
 	if err != nil {
 		return hourUnit{}, err
 	}
-	unit.Sec, err = strconv.Atoi(vals[2])
+	secStr, fraction := vals[2], ""
+	if idx := strings.Index(secStr, "."); idx >= 0 {
+		secStr, fraction = secStr[:idx], secStr[idx+1:]
+		if len(fraction) == 0 || len(fraction) > 9 || strings.Trim(fraction, "0123456789") != "" {
+			return hourUnit{}, ErrHourUnitFormat
+		}
+	}
+	unit.Sec, err = strconv.Atoi(secStr)
 	if err != nil {
 		return hourUnit{}, err
 	}
+	if fraction != "" {
+		// 按纳秒补齐9位, etc: 5 -> 500000000
+		unit.Nsec, err = strconv.Atoi(fraction + strings.Repeat("0", 9-len(fraction)))
+		if err != nil {
+			return hourUnit{}, err
+		}
+	}
 
 	// 校验解析出来的值
 	err = unit.check()
@@ -58,9 +75,13 @@ func (unit *hourUnit) check() error {
 	if unit.Sec < 0 || unit.Sec > 59 {
 		return ErrHourUnitFormat
 	}
+	// 检验纳秒
+	if unit.Nsec < 0 || unit.Nsec >= int(time.Second) {
+		return ErrHourUnitFormat
+	}
 
 	// 只能24:00:00
-	if unit.Hour == 24 && (unit.Minute > 0 || unit.Sec > 0) {
+	if unit.Hour == 24 && (unit.Minute > 0 || unit.Sec > 0 || unit.Nsec > 0) {
 		return ErrHourUnitFormat
 	}
 
@@ -81,7 +102,12 @@ func (unit *hourUnit) before(target hourUnit) bool {
 	} else if unit.Minute > target.Minute {
 		return false
 	}
-	if unit.Sec <= target.Sec {
+	if unit.Sec < target.Sec {
+		return true
+	} else if unit.Sec > target.Sec {
+		return false
+	}
+	if unit.Nsec <= target.Nsec {
 		return true
 	}
 
@@ -93,8 +119,40 @@ func (unit *hourUnit) after(target hourUnit) bool {
 	return !unit.before(target)
 }
 
-// toSec 转换为秒
+// toSec 转换为秒, 忽略秒以下的部分
 func (unit *hourUnit) toSec() int {
-	duration := time.Duration(unit.Hour)*time.Hour + time.Duration(unit.Minute)*time.Minute + time.Duration(unit.Sec)*time.Second
-	return int(duration.Seconds())
+	return int(unit.toDuration() / time.Second)
+}
+
+// toDuration 转换为距离0点的时长, 精确到纳秒
+func (unit *hourUnit) toDuration() time.Duration {
+	return time.Duration(unit.Hour)*time.Hour + time.Duration(unit.Minute)*time.Minute +
+		time.Duration(unit.Sec)*time.Second + time.Duration(unit.Nsec)
+}
+
+// String 格式化为hh:mm:ss, 有秒以下的部分时加上毫秒, 微秒或者纳秒的小数, etc: 12:00:00.500
+func (unit *hourUnit) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", unit.Hour, unit.Minute, unit.Sec) + formatFraction(unit.Nsec)
+}
+
+// durationToHourUnit 距离0点的时长转换为时分秒
+func durationToHourUnit(d time.Duration) hourUnit {
+	return hourUnit{
+		Hour:   int(d / time.Hour),
+		Minute: int(d % time.Hour / time.Minute),
+		Sec:    int(d % time.Minute / time.Second),
+		Nsec:   int(d % time.Second),
+	}
+}
+
+// formatFraction 格式化秒的小数部分, 按3位一组去掉末尾的0, 为0时返回空, etc: 500000000 -> .500
+func formatFraction(nsec int) string {
+	if nsec == 0 {
+		return ""
+	}
+	fraction := fmt.Sprintf("%09d", nsec)
+	for strings.HasSuffix(fraction, "000") {
+		fraction = fraction[:len(fraction)-3]
+	}
+	return "." + fraction
 }
@@ -2,14 +2,24 @@ package timeexpression
 
 import (
 	"errors"
+	"regexp"
 	"strings"
+	"time"
 )
 
+// sunHourUnitRegex 以日出日落为端点的时间段, etc: sunset-00:30:00-sunset+02:00:00, sunrise-12:00:00
+var sunHourUnitRegex = regexp.MustCompile(`^((?:sunrise|sunset)(?:[+-]\d+:\d+:\d+)?|\d+:\d+:\d+(?:\.\d+)?)-((?:sunrise|sunset)(?:[+-]\d+:\d+:\d+)?|\d+:\d+:\d+(?:\.\d+)?)$`)
+
 // hourUnitExpression 小时/分钟/秒的最小解析单位
 type hourUnitExpression struct {
 	start hourUnit
 	end   hourUnit
 	isAll bool
+
+	// startSun 不为nil时开始时间以日出或者日落为基准, start不使用
+	startSun *sunAnchor
+	// endSun 不为nil时结束时间以日出或者日落为基准, end不使用
+	endSun *sunAnchor
 }
 
 // newHourUnitExpression 格式为 *,hh:mm:ss-hh:mm:ss
@@ -33,6 +43,10 @@ func newHourUnitExpression(unitStr string) (*hourUnitExpression, error) {
 		return expression, nil
 	}
 
+	if strings.Contains(unitStr, "sun") {
+		return newSunHourUnitExpression(unitStr)
+	}
+
 	expression := &hourUnitExpression{}
 
 	hourSubList := strings.Split(unitStr, "-")
@@ -61,53 +75,111 @@ func newHourUnitExpression(unitStr string) (*hourUnitExpression, error) {
 	return expression, nil
 }
 
-// check 检查参数是否正确
-func (expression *hourUnitExpression) check() error {
-	startSec := expression.start.toSec()
-	endSec := expression.end.toSec()
-	if startSec >= endSec {
-		return errors.New("hour error: start after end")
+// newSunHourUnitExpression 至少一端为日出或者日落的时间段, 格式为 端点-端点
+// 端点为hh:mm:ss, sunrise, sunset, 日出日落可以加上偏移, etc: sunset-00:30:00-sunset+02:00:00
+// 极昼或者极夜的那些天没有日出日落, 这些天不会有这个时间段, Lint会给出LintPolarPeriod
+func newSunHourUnitExpression(unitStr string) (*hourUnitExpression, error) {
+	matches := sunHourUnitRegex.FindStringSubmatch(unitStr)
+	if matches == nil {
+		return nil, ErrHourUnitFormat
 	}
 
-	return nil
+	expression := &hourUnitExpression{}
+	var err error
+	if strings.HasPrefix(matches[1], "sun") {
+		expression.startSun, err = newSunAnchor(matches[1])
+	} else {
+		expression.start, err = newHourTimeUnit(matches[1])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(matches[2], "sun") {
+		expression.endSun, err = newSunAnchor(matches[2])
+	} else {
+		expression.end, err = newHourTimeUnit(matches[2])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一个基准时结束需要在开始之后, 其他情况结束不晚于开始时为第二天的结束时间
+	if expression.startSun != nil && expression.endSun != nil &&
+		expression.startSun.event == expression.endSun.event && expression.startSun.offset >= expression.endSun.offset {
+		return nil, errors.New("hour error: start after end")
+	}
+
+	return expression, nil
 }
 
-// isIn 是否在表达式范围内
-func (expression *hourUnitExpression) isIn(hour, minute, sec int) bool {
+// isSun 是否有以日出或者日落为基准的端点
+func (expression *hourUnitExpression) isSun() bool {
+	return expression.startSun != nil || expression.endSun != nil
+}
 
-	// 检验小时
-	if hour < expression.start.Hour || hour > expression.end.Hour {
-		return false
+// resolve 计算day这一天的时间段, 结束不晚于开始时取第二天的结束时间
+// 需要的日出或者日落当天不存在(极昼或者极夜)时, 这一天没有这个时间段, ok返回false
+func (expression *hourUnitExpression) resolve(day time.Time, location GeoLocation) (w window, ok bool) {
+	var err error
+	if expression.startSun != nil {
+		w.start, err = expression.startSun.resolve(day, location)
+	} else {
+		w.start = unitTime(day, expression.start)
 	}
-	if hour > expression.start.Hour && hour < expression.end.Hour {
-		return true
+	if err != nil {
+		return window{}, false
 	}
 
-	if hour == expression.start.Hour {
-		// 小时等于开始
-		if minute < expression.start.Minute {
-			return false
+	for _, endDay := range []time.Time{day, day.AddDate(0, 0, 1)} {
+		if expression.endSun != nil {
+			w.end, err = expression.endSun.resolve(endDay, location)
+		} else {
+			w.end = unitTime(endDay, expression.end)
 		}
-		if minute > expression.start.Minute {
-			return true
-		}
-		// 分钟相等,判断秒
-		if sec < expression.start.Sec {
-			return false
-		}
-		return true
-	} else {
-		// 小时等于结束
-		if minute > expression.end.Minute {
-			return false
+		if err != nil {
+			return window{}, false
 		}
-		if minute < expression.end.Minute {
-			return true
+		if w.end.After(w.start) {
+			return w, true
 		}
-		// 分钟相等,判断秒
-		if sec >= expression.end.Sec {
-			return false
-		}
-		return true
 	}
+	return window{}, false
+}
+
+// check 检查参数是否正确
+func (expression *hourUnitExpression) check() error {
+	if expression.start.toDuration() >= expression.end.toDuration() {
+		return errors.New("hour error: start after end")
+	}
+
+	return nil
+}
+
+// isIn 是否在表达式范围内, 精确到纳秒
+func (expression *hourUnitExpression) isIn(hour, minute, sec, nsec int) bool {
+	paramUnit := hourUnit{
+		Hour:   hour,
+		Minute: minute,
+		Sec:    sec,
+		Nsec:   nsec,
+	}
+	param := paramUnit.toDuration()
+
+	// 左闭右开
+	return param >= expression.start.toDuration() && param < expression.end.toDuration()
+}
+
+// String 格式化为*或者hh:mm:ss-hh:mm:ss
+func (expression *hourUnitExpression) String() string {
+	if expression.isAll {
+		return "*"
+	}
+	start, end := expression.start.String(), expression.end.String()
+	if expression.startSun != nil {
+		start = expression.startSun.String()
+	}
+	if expression.endSun != nil {
+		end = expression.endSun.String()
+	}
+	return start + "-" + end
 }
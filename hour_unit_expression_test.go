@@ -147,10 +147,42 @@ func testIsInHourUnit(t *testing.T, expression *hourUnitExpression, testDatas []
 			t.Fatal(err)
 		}
 
-		in := expression.isIn(hourTime.Hour(), hourTime.Minute(), hourTime.Second())
+		in := expression.isIn(hourTime.Hour(), hourTime.Minute(), hourTime.Second(), hourTime.Nanosecond())
 
 		if in != data.result {
 			t.Errorf("want[%v] but get[%v]", data.result, in)
 		}
 	}
 }
+
+func TestHourUnitExpression_IsIn_SameHour(t *testing.T) {
+	expression, err := newHourUnitExpression("20:30:00-20:30:01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, expression.isIn(20, 29, 59, 0))
+	assert.True(t, expression.isIn(20, 30, 0, 0))
+	assert.False(t, expression.isIn(20, 30, 1, 0))
+	assert.False(t, expression.isIn(20, 31, 0, 0))
+}
+
+func TestHourUnitExpression_IsIn_SubSecond(t *testing.T) {
+	expression, err := newHourUnitExpression("12:00:00.500-12:00:05.250")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "12:00:00.500-12:00:05.250", expression.String())
+	assert.False(t, expression.isIn(12, 0, 0, 499999999))
+	assert.True(t, expression.isIn(12, 0, 0, 500000000))
+	assert.True(t, expression.isIn(12, 0, 5, 249999999))
+	assert.False(t, expression.isIn(12, 0, 5, 250000000))
+
+	_, err = newHourUnitExpression("12:00:00.500-12:00:00.500")
+	assert.NotNil(t, err)
+	expression, err = newHourUnitExpression("sunrise-12:00:00.500")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "sunrise-12:00:00.500", expression.String())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestHourUnit_new(t *testing.T) {
@@ -14,6 +15,7 @@ func TestHourUnit_new(t *testing.T) {
 		hour int
 		min  int
 		sec  int
+		nsec int
 	}{
 		{
 			exp:  "22:33:44",
@@ -22,6 +24,33 @@ func TestHourUnit_new(t *testing.T) {
 			min:  33,
 			sec:  44,
 		},
+		{
+			exp:  "12:00:05.25",
+			hour: 12,
+			sec:  5,
+			nsec: 250000000,
+		},
+		{
+			exp:  "12:00:00.000000001",
+			hour: 12,
+			nsec: 1,
+		},
+		{
+			exp: "12:00:00.",
+			err: ErrHourUnitFormat,
+		},
+		{
+			exp: "12:00:00.0000000001",
+			err: ErrHourUnitFormat,
+		},
+		{
+			exp: "12:00:00.+5",
+			err: ErrHourUnitFormat,
+		},
+		{
+			exp: "24:00:00.5",
+			err: ErrHourUnitFormat,
+		},
 		{
 			exp: "2233:44",
 			err: ErrHourUnitFormat,
@@ -74,6 +103,7 @@ func TestHourUnit_new(t *testing.T) {
 		assert.Equal(t, data.hour, unit.Hour)
 		assert.Equal(t, data.min, unit.Minute)
 		assert.Equal(t, data.sec, unit.Sec)
+		assert.Equal(t, data.nsec, unit.Nsec)
 	}
 }
 
@@ -122,3 +152,22 @@ func TestHourUnit_after(t *testing.T) {
 	assert.False(t, unitBefore.after(unitAfter2))
 	assert.True(t, unitBefore.after(unitAfter3))
 }
+
+func TestHourUnit_subSecond(t *testing.T) {
+	unit, err := newHourTimeUnit("12:00:00.5")
+	if err != nil {
+		panic(err)
+	}
+	unitAfter, err := newHourTimeUnit("12:00:00.500000001")
+	if err != nil {
+		panic(err)
+	}
+
+	assert.True(t, unit.before(unitAfter))
+	assert.False(t, unitAfter.before(unit))
+	assert.Equal(t, "12:00:00.500", unit.String())
+	assert.Equal(t, "12:00:00.500000001", unitAfter.String())
+	assert.Equal(t, 12*time.Hour+500*time.Millisecond, unit.toDuration())
+	assert.Equal(t, 12*3600, unit.toSec())
+	assert.Equal(t, unit, durationToHourUnit(unit.toDuration()))
+}
@@ -0,0 +1,431 @@
+package timeexpression
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrICalUnbounded 表达式没有结束时间, 又需要逐个生成周期
+	ErrICalUnbounded = errors.New("ical export needs an until time for unbounded expression")
+	// ErrICalFormat iCalendar格式不对
+	ErrICalFormat = errors.New("ical format not math")
+	// ErrICalUnsupported iCalendar中有无法表达的部分
+	ErrICalUnsupported = errors.New("ical feature is unsupported")
+	// ErrICalLossy 排除或增加的时间段无法和RRULE一起准确表达
+	ErrICalLossy = errors.New("ical export is lossy")
+)
+
+const (
+	// icalTimeLayout iCalendar中不带时区的本地时间
+	icalTimeLayout = "20060102T150405"
+	// defaultICalMaxInstances 逐个生成周期时的最大数量
+	defaultICalMaxInstances = 1000
+)
+
+// VEvent iCalendar中的一个事件, RRule为空时表示单次的事件, ExDates为RRULE中排除的发生时间
+type VEvent struct {
+	UID     string
+	Start   time.Time
+	End     time.Time
+	RRule   string
+	ExDates []time.Time
+	Summary string
+}
+
+// ICalOptions 生成VEVENT的参数
+type ICalOptions struct {
+	// From 从这个时间开始生成, 为零值时从表达式的第一个周期开始
+	From time.Time
+	// Until 逐个生成周期时的截止时间, 为零值时使用表达式的最后结束时间
+	Until time.Time
+	// MaxInstances 逐个生成周期时的最大数量, 默认为1000
+	MaxInstances int
+	// Summary 事件的标题
+	Summary string
+	// UIDDomain UID中@后面的部分, 默认为timeexpression
+	UIDDomain string
+}
+
+// ToVEvents 转换为iCalendar的VEVENT
+// 字段可以直接映射时使用RRULE, 否则逐个生成周期
+func (expression *DateTimeExpression) ToVEvents(opts ICalOptions) ([]VEvent, error) {
+	opts, err := expression.icalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events, ok, err := expression.recurringVEvents(opts)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return events, nil
+	}
+
+	until, err := expression.icalUntil(opts)
+	if err != nil {
+		return nil, err
+	}
+	return expression.instanceVEvents(expression, opts, until)
+}
+
+// ToVEvents 转换为iCalendar的VEVENT, 基础表达式需要为DateTimeExpression
+// 基础表达式能用RRULE表达时, 整个被排除的周期输出为EXDATE, 增加的时间段输出为单次的事件
+// 只排除了周期的一部分, 或者增加的时间段和周期重叠时RRULE无法表达, 返回ErrICalLossy
+// 基础表达式逐个生成周期时, 直接生成排除和增加之后的周期
+func (expression *AmendedExpression) ToVEvents(opts ICalOptions) ([]VEvent, error) {
+	base, ok := expression.base.(*DateTimeExpression)
+	if !ok {
+		return nil, fmt.Errorf("%w: amended base expression %s", ErrICalUnsupported, expression.base)
+	}
+	opts, err := base.icalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events, ok, err := base.recurringVEvents(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		until, err := base.icalUntil(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range expression.plus {
+			if w.end.After(until) {
+				until = w.end
+			}
+		}
+		return base.instanceVEvents(expression, opts, until)
+	}
+
+	for i := range events {
+		exDates, err := exceptOccurrences(events[i], expression.excepts)
+		if err != nil {
+			return nil, err
+		}
+		events[i].ExDates = exDates
+	}
+
+	for _, w := range expression.plus {
+		// 和基础表达式的周期重叠时会合并为一个周期, 单次的事件无法表达
+		overlaps, err := collectWindows(base, w.start, w.end, 1)
+		if err != nil && err != ErrNeverMatch {
+			return nil, err
+		}
+		if len(overlaps) > 0 && overlaps[0].start.Before(w.end) {
+			return nil, fmt.Errorf("%w: plus %s overlaps %s", ErrICalLossy, expression.formatItem(w), base)
+		}
+		for _, piece := range subtractWindows(w, expression.excepts) {
+			if !piece.end.After(opts.From) {
+				continue
+			}
+			events = append(events, VEvent{
+				UID:     fmt.Sprintf("%s-%s@%s", base.uidPrefix(), piece.start.Format(icalTimeLayout), opts.UIDDomain),
+				Start:   piece.start,
+				End:     piece.end,
+				Summary: opts.Summary,
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// exceptOccurrences 获取RRULE中整个被排除的发生时间, 只排除了一部分时返回ErrICalLossy
+func exceptOccurrences(event VEvent, excepts []window) ([]time.Time, error) {
+	if len(excepts) == 0 {
+		return nil, nil
+	}
+	rule, err := parseRRule(event.RRule, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	var exDates []time.Time
+	// 和排除的时间段重叠的发生时间最早在开始前的周期长度之内
+	span := daysBetween(event.Start, event.End) + 1
+	for _, except := range excepts {
+		for _, occurrence := range rule.occurrences(event.Start, except.start.AddDate(0, 0, -span), except.end, maxScanDays) {
+			end := occurrenceEnd(event, occurrence)
+			if !occurrence.Before(except.end) || !end.After(except.start) {
+				continue
+			}
+			if occurrence.Before(except.start) || end.After(except.end) {
+				return nil, fmt.Errorf("%w: except only covers part of the occurrence at %s", ErrICalLossy,
+					formatICalTime(occurrence))
+			}
+			exDates = append(exDates, occurrence)
+		}
+	}
+	return exDates, nil
+}
+
+// occurrenceEnd 某次发生的结束时间, 按钟表上的时间计算, 和第一次的开始结束相隔的天数以及时分秒相同
+func occurrenceEnd(event VEvent, occurrence time.Time) time.Time {
+	endDay := truncateDay(occurrence).AddDate(0, 0, daysBetween(event.Start, event.End))
+	return time.Date(endDay.Year(), endDay.Month(), endDay.Day(), event.End.Hour(), event.End.Minute(),
+		event.End.Second(), event.End.Nanosecond(), time.Local)
+}
+
+// icalOptions 补全生成VEVENT的参数的默认值
+func (expression *DateTimeExpression) icalOptions(opts ICalOptions) (ICalOptions, error) {
+	if expression.alwaysActive {
+		return opts, ErrAlwaysActiveNoStartTime
+	}
+	if opts.UIDDomain == "" {
+		opts.UIDDomain = "timeexpression"
+	}
+	if opts.MaxInstances <= 0 {
+		opts.MaxInstances = defaultICalMaxInstances
+	}
+	if opts.From.IsZero() {
+		first, _ := expression.dayRange()
+		if first.IsZero() {
+			return opts, ErrNoStart
+		}
+		opts.From = first
+	}
+	return opts, nil
+}
+
+// icalUntil 逐个生成周期时的截止时间
+func (expression *DateTimeExpression) icalUntil(opts ICalOptions) (time.Time, error) {
+	if !opts.Until.IsZero() {
+		return opts.Until, nil
+	}
+	finalEnd, err := expression.FinalEndTime()
+	if err == ErrNoEnd {
+		return time.Time{}, ErrICalUnbounded
+	}
+	return finalEnd, err
+}
+
+// uidPrefix 由表达式生成的稳定的UID前缀
+func (expression *DateTimeExpression) uidPrefix() string {
+	sum := sha1.Sum([]byte(expression.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// recurringVEvents 尝试用RRULE表达, 不能直接映射时ok返回false
+func (expression *DateTimeExpression) recurringVEvents(opts ICalOptions) (events []VEvent, ok bool, err error) {
+	// 不支持带序号的BYDAY和BYSETPOS, 工作日取决于日历, 节气, 农历, 日出日落和财年没有对应的规则, 逻辑上的一天不从0点开始时字段也无法直接映射
+	// BYWEEKNO和BYYEARDAY只能用于YEARLY, 和按天的时间段组合时规则很复杂, ISO周和一年中的第几天也按实例导出
+	switch expression.day.kind {
+	case dayKindMonthDay, dayKindWeekday:
+	default:
+		return nil, false, nil
+	}
+	switch expression.month.kind {
+	case monthKindMonth, monthKindQuarter:
+	default:
+		return nil, false, nil
+	}
+	if expression.hour.hasSun || expression.dayStart != 0 {
+		return nil, false, nil
+	}
+	base := rrule{}
+	if _, last := expression.dayRange(); !last.IsZero() {
+		base.until = last.Add(24*time.Hour - time.Second)
+	}
+	if !expression.month.isAll {
+		base.byMonth = intRange(expression.month.start, expression.month.end)
+	}
+
+	var rules []rrule
+	var starts []hourUnit
+	// 结束时间按钟表上的时间计算, 在开始后的第endDays[i]天的ends[i], 夏令时切换的当天时长也不变
+	var endDays []int
+	var ends []hourUnit
+
+	if expression.hour.isAll {
+		// 整天的周期, 只有长度固定时才能用RRULE
+		rule := base
+		var days int
+		switch {
+		case expression.day.kind == dayKindWeekday && expression.month.isAll:
+			rule.freq = rruleWeekly
+			rule.byDay = []int{expression.day.start}
+			days = expression.day.end - expression.day.start + 1
+		case !expression.day.isAll && expression.day.kind == dayKindMonthDay && expression.day.end <= 28 &&
+			!(expression.day.start == 1 && expression.day.end == 28):
+			rule.freq = rruleMonthly
+			rule.byMonthDay = []int{expression.day.start}
+			days = expression.day.end - expression.day.start + 1
+		default:
+			return nil, false, nil
+		}
+		rules = []rrule{rule}
+		starts = []hourUnit{{}}
+		endDays = []int{days}
+		ends = []hourUnit{{}}
+	} else {
+		for _, unit := range expression.hour.hourUnits {
+			if unit.start.toDuration() == 0 {
+				for _, other := range expression.hour.hourUnits {
+					if other.end.Hour == 24 {
+						// 跨天合并的周期
+						return nil, false, nil
+					}
+				}
+			}
+			rule := base
+			rule.freq = rruleDaily
+			if expression.day.kind == dayKindWeekday {
+				rule.byDay = intRange(expression.day.start, expression.day.end)
+			} else if !expression.day.isAll {
+				rule.byMonthDay = intRange(expression.day.start, expression.day.end)
+			}
+			rules = append(rules, rule)
+			starts = append(starts, unit.start)
+			endDays = append(endDays, 0)
+			ends = append(ends, unit.end)
+		}
+	}
+
+	for i, rule := range rules {
+		dtStart, found := expression.firstOccurrence(opts.From, starts[i])
+		if !found {
+			continue
+		}
+		events = append(events, VEvent{
+			UID:     fmt.Sprintf("%s-r%d@%s", expression.uidPrefix(), i, opts.UIDDomain),
+			Start:   dtStart,
+			End:     unitTime(dtStart.AddDate(0, 0, endDays[i]), ends[i]),
+			RRule:   rule.String(),
+			Summary: opts.Summary,
+		})
+	}
+
+	return events, true, nil
+}
+
+// firstOccurrence 从from开始, 找到第一个在开始时间为start的周期
+func (expression *DateTimeExpression) firstOccurrence(from time.Time, start hourUnit) (time.Time, bool) {
+	windows, err := collectWindows(expression, from, from.AddDate(0, 0, maxScanDays), maxScanDays)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for _, w := range windows {
+		if w.start.Before(from) {
+			continue
+		}
+		if w.start.Hour() == start.Hour && w.start.Minute() == start.Minute && w.start.Second() == start.Sec &&
+			w.start.Nanosecond() == start.Nsec {
+			return w.start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// instanceVEvents 逐个生成source在until之前的周期, UID使用表达式的前缀
+func (expression *DateTimeExpression) instanceVEvents(source Expression, opts ICalOptions, until time.Time) ([]VEvent, error) {
+	windows, err := collectWindows(source, opts.From, until, opts.MaxInstances)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]VEvent, 0, len(windows))
+	for _, w := range windows {
+		events = append(events, VEvent{
+			UID:     fmt.Sprintf("%s-%s@%s", expression.uidPrefix(), w.start.Format(icalTimeLayout), opts.UIDDomain),
+			Start:   w.start,
+			End:     w.end,
+			Summary: opts.Summary,
+		})
+	}
+	return events, nil
+}
+
+// formatICalTime 格式化为不带时区的本地时间, 由日历客户端按所在时区解释
+func formatICalTime(t time.Time) string {
+	return t.Format(icalTimeLayout)
+}
+
+// parseICalTime 解析DATE-TIME, 以Z结尾的为UTC时间, 否则为location的本地时间
+func parseICalTime(value string, location *time.Location) (time.Time, error) {
+	var t time.Time
+	var err error
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(icalTimeLayout, strings.TrimSuffix(value, "Z"))
+	} else {
+		t, err = time.ParseInLocation(icalTimeLayout, value, location)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date time '%s'", ErrICalFormat, value)
+	}
+	return t, nil
+}
+
+// WriteICalendar 输出完整的.ics文档, stamp为DTSTAMP
+func WriteICalendar(w io.Writer, prodID string, stamp time.Time, events []VEvent) error {
+	writer := bufio.NewWriter(w)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + prodID,
+		"CALSCALE:GREGORIAN",
+	}
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+stamp.UTC().Format(icalTimeLayout)+"Z",
+			"DTSTART:"+formatICalTime(event.Start),
+			"DTEND:"+formatICalTime(event.End),
+		)
+		if event.RRule != "" {
+			lines = append(lines, "RRULE:"+event.RRule)
+		}
+		if len(event.ExDates) > 0 {
+			exDates := make([]string, 0, len(event.ExDates))
+			for _, exDate := range event.ExDates {
+				exDates = append(exDates, formatICalTime(exDate))
+			}
+			lines = append(lines, "EXDATE:"+strings.Join(exDates, ","))
+		}
+		if event.Summary != "" {
+			lines = append(lines, "SUMMARY:"+escapeICalText(event.Summary))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := writer.WriteString(foldICalLine(line)); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// escapeICalText 转义TEXT类型的值
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICalLine 按RFC 5545 每行不超过75个字节, 续行以空格开头, 以CRLF结尾
+func foldICalLine(line string) string {
+	var builder strings.Builder
+	length := 0
+	for _, char := range line {
+		size := len(string(char))
+		if length+size > 75 {
+			builder.WriteString("\r\n ")
+			length = 1
+		}
+		builder.WriteRune(char)
+		length += size
+	}
+	builder.WriteString("\r\n")
+	return builder.String()
+}
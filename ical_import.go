@@ -0,0 +1,423 @@
+package timeexpression
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icalDateLayout iCalendar中的DATE
+const icalDateLayout = "20060102"
+
+// icalDurationRegex DURATION的格式, etc: P1D, PT2H30M, P1W
+var icalDurationRegex = regexp.MustCompile(`^\+?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// icalEvent 导入的一个VEVENT
+type icalEvent struct {
+	start time.Time
+	// 周期长度, days按日历天数计算, 剩下的部分为duration
+	days     int
+	duration time.Duration
+	rule     *rrule
+	// occurrences 没有RRULE或者RRULE带COUNT时, 解析时展开的所有发生时间
+	occurrences []time.Time
+	// exDates 排除的发生时间, exDays 排除的发生日期(VALUE=DATE)
+	exDates []time.Time
+	exDays  []time.Time
+}
+
+// ICalExpression 由iCalendar导入的表达式, 多个VEVENT的周期取并集
+type ICalExpression struct {
+	events []*icalEvent
+}
+
+var _ Expression = (*ICalExpression)(nil)
+
+// ParseICalendar 解析.ics文档中的VEVENT
+// 支持DTSTART, DTEND, DURATION, EXDATE 以及 RRULE 的 FREQ=DAILY/WEEKLY/MONTHLY/YEARLY, INTERVAL,
+// BYMONTH, BYMONTHDAY, BYDAY, UNTIL, COUNT, 无法表达的部分返回ErrICalUnsupported
+func ParseICalendar(r io.Reader) (*ICalExpression, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	expression := &ICalExpression{}
+	var components []string
+	var event *icalEvent
+	var properties []icalProperty
+	for _, line := range lines {
+		property, err := parseICalProperty(line)
+		if err != nil {
+			return nil, err
+		}
+		switch property.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(property.value))
+			if len(components) == 2 && components[1] == "VEVENT" {
+				properties = nil
+			}
+			continue
+		case "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(property.value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrICalFormat, property.value)
+			}
+			if len(components) == 2 && components[1] == "VEVENT" {
+				event, err = newICalEvent(properties)
+				if err != nil {
+					return nil, err
+				}
+				expression.events = append(expression.events, event)
+			}
+			components = components[:len(components)-1]
+			continue
+		}
+		// 只处理VEVENT自身的属性, 忽略VTIMEZONE, VALARM等
+		if len(components) == 2 && components[1] == "VEVENT" {
+			properties = append(properties, property)
+		}
+	}
+	if len(components) != 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", ErrICalFormat, components[len(components)-1])
+	}
+	if len(expression.events) == 0 {
+		return nil, fmt.Errorf("%w: no VEVENT", ErrICalFormat)
+	}
+
+	return expression, nil
+}
+
+// unfoldICalLines 按行读取并合并以空格或者tab开头的续行
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// icalProperty 一行内容, etc: DTSTART;TZID=Asia/Shanghai:20240101T080000
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseICalProperty(line string) (icalProperty, error) {
+	// 参数值可能带引号, 引号内的冒号不是分隔符
+	quoted := false
+	colon := -1
+	for i, char := range line {
+		if char == '"' {
+			quoted = !quoted
+		} else if char == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return icalProperty{}, fmt.Errorf("%w: invalid line '%s'", ErrICalFormat, line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	property := icalProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return icalProperty{}, fmt.Errorf("%w: invalid parameter '%s'", ErrICalFormat, param)
+		}
+		property.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return property, nil
+}
+
+// newICalEvent 由VEVENT的属性生成事件
+func newICalEvent(properties []icalProperty) (*icalEvent, error) {
+	event := &icalEvent{}
+	var startFound, allDay bool
+	var end *icalProperty
+	var duration *icalProperty
+	var rules []string
+	var exDates []icalProperty
+
+	for i := range properties {
+		property := properties[i]
+		switch property.name {
+		case "DTSTART":
+			var err error
+			event.start, allDay, err = parseICalDateTime(property)
+			if err != nil {
+				return nil, err
+			}
+			startFound = true
+		case "DTEND":
+			end = &properties[i]
+		case "DURATION":
+			duration = &properties[i]
+		case "RRULE":
+			rules = append(rules, property.value)
+		case "EXDATE":
+			exDates = append(exDates, property)
+		case "RDATE", "EXRULE", "RECURRENCE-ID":
+			return nil, fmt.Errorf("%w: %s", ErrICalUnsupported, property.name)
+		}
+	}
+	if !startFound {
+		return nil, fmt.Errorf("%w: VEVENT without DTSTART", ErrICalFormat)
+	}
+
+	switch {
+	case end != nil && duration != nil:
+		return nil, fmt.Errorf("%w: VEVENT with both DTEND and DURATION", ErrICalFormat)
+	case end != nil:
+		endTime, _, err := parseICalDateTime(*end)
+		if err != nil {
+			return nil, err
+		}
+		if endTime.Before(event.start) {
+			return nil, fmt.Errorf("%w: DTEND before DTSTART", ErrICalFormat)
+		}
+		// 按日历天数计算, 跨夏令时的时候长度不变
+		event.days = daysBetween(event.start, endTime.In(event.start.Location()))
+		if event.start.AddDate(0, 0, event.days).After(endTime) {
+			event.days--
+		}
+		event.duration = endTime.Sub(event.start.AddDate(0, 0, event.days))
+	case duration != nil:
+		var err error
+		event.days, event.duration, err = parseICalDuration(duration.value)
+		if err != nil {
+			return nil, err
+		}
+	case allDay:
+		// 只有日期的事件默认为一整天
+		event.days = 1
+	}
+
+	if len(rules) > 1 {
+		return nil, fmt.Errorf("%w: multiple RRULE", ErrICalUnsupported)
+	}
+	if len(rules) == 1 {
+		rule, err := parseRRule(rules[0], event.start.Location())
+		if err != nil {
+			return nil, err
+		}
+		event.rule = rule
+	}
+	switch {
+	case event.rule == nil:
+		event.occurrences = []time.Time{event.start}
+	case event.rule.count > 0:
+		// COUNT需要从DTSTART开始计数, 只在解析时展开一次, 距离上一次发生超过maxScanDays天时不再继续找
+		occurrences, ok := event.rule.expand(event.start, event.start,
+			time.Date(MaxYear+1, time.January, 1, 0, 0, 0, 0, event.start.Location()), event.rule.count, maxScanDays)
+		if !ok {
+			return nil, fmt.Errorf("%w: RRULE %s found %d of COUNT=%d occurrences", ErrBeyondScanHorizon,
+				rules[0], len(occurrences), event.rule.count)
+		}
+		event.occurrences = occurrences
+	}
+
+	for _, property := range exDates {
+		for _, value := range strings.Split(property.value, ",") {
+			property.value = value
+			exDate, isDate, err := parseICalDateTime(property)
+			if err != nil {
+				return nil, err
+			}
+			if isDate {
+				event.exDays = append(event.exDays, exDate)
+			} else {
+				event.exDates = append(event.exDates, exDate)
+			}
+		}
+	}
+
+	return event, nil
+}
+
+// parseICalDateTime 解析DATE或者DATE-TIME的属性, 支持TZID参数
+func parseICalDateTime(property icalProperty) (t time.Time, isDate bool, err error) {
+	location := time.Local
+	if tzid, ok := property.params["TZID"]; ok {
+		location, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: TZID=%s", ErrICalUnsupported, tzid)
+		}
+	}
+
+	if property.params["VALUE"] == "DATE" || len(property.value) == len(icalDateLayout) {
+		t, err = parseICalDate(property.value, location)
+		return t, true, err
+	}
+	t, err = parseICalTime(property.value, location)
+	return t, false, err
+}
+
+// parseICalDate 解析DATE, 为location的0点
+func parseICalDate(value string, location *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(icalDateLayout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date '%s'", ErrICalFormat, value)
+	}
+	return t, nil
+}
+
+// parseICalDuration 解析DURATION, 天和周按日历天数计算
+func parseICalDuration(value string) (days int, duration time.Duration, err error) {
+	matches := icalDurationRegex.FindStringSubmatch(value)
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		if strings.HasPrefix(value, "-") {
+			return 0, 0, fmt.Errorf("%w: negative DURATION", ErrICalUnsupported)
+		}
+		return 0, 0, fmt.Errorf("%w: invalid duration '%s'", ErrICalFormat, value)
+	}
+
+	numbers := make([]int, len(matches))
+	for i := 1; i < len(matches); i++ {
+		if matches[i] != "" {
+			numbers[i], _ = strconv.Atoi(matches[i])
+		}
+	}
+	days = numbers[1]*7 + numbers[2]
+	duration = time.Duration(numbers[3])*time.Hour + time.Duration(numbers[4])*time.Minute + time.Duration(numbers[5])*time.Second
+	return days, duration, nil
+}
+
+// excluded 判断某次发生是否被EXDATE排除
+func (event *icalEvent) excluded(occurrence time.Time) bool {
+	for _, exDate := range event.exDates {
+		if exDate.Equal(occurrence) {
+			return true
+		}
+	}
+	for _, exDay := range event.exDays {
+		if exDay.Year() == occurrence.Year() && exDay.Month() == occurrence.Month() && exDay.Day() == occurrence.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// windows 获取[from, until)内开始的周期
+func (event *icalEvent) windows(from time.Time, until time.Time) []window {
+	occurrences := event.occurrences
+	if occurrences == nil && event.rule != nil {
+		occurrences = event.rule.occurrences(event.start, from, until, math.MaxInt32)
+	}
+
+	var windows []window
+	for _, occurrence := range occurrences {
+		if occurrence.Before(from) || !occurrence.Before(until) || event.excluded(occurrence) {
+			continue
+		}
+		windows = append(windows, window{
+			start: occurrence,
+			end:   occurrence.AddDate(0, 0, event.days).Add(event.duration),
+		})
+	}
+	return windows
+}
+
+// span 周期长度的上限, 每天按25小时计算, 跨夏令时的时候也不会更长
+func (event *icalEvent) span() time.Duration {
+	return time.Duration(event.days)*25*time.Hour + event.duration
+}
+
+// bounded 事件是否有最后一次发生
+func (event *icalEvent) bounded() bool {
+	return event.rule == nil || !event.rule.until.IsZero() || event.rule.count > 0
+}
+
+// windows 获取t附近到t之后maxScanDays天为止的周期, exhausted表示之后不会再有周期
+// 从t往前一个最长周期的长度开始展开, 包含t的周期可能和更早的周期重叠时再往前展开, 不需要每次都从DTSTART开始
+func (expression *ICalExpression) windows(t time.Time) (windows []window, exhausted bool) {
+	var span time.Duration
+	first := t
+	for _, event := range expression.events {
+		if event.span() > span {
+			span = event.span()
+		}
+		if event.start.Before(first) {
+			first = event.start
+		}
+	}
+
+	until := t.AddDate(0, 0, maxScanDays)
+	step := span
+	from := t.Add(-step)
+	for {
+		windows, exhausted = expression.windowsBetween(from, until)
+		w, err := windowAt(windows, t, exhausted)
+		if err != nil || !w.start.Before(from.Add(span)) || !from.After(first) {
+			return windows, exhausted
+		}
+		// 在from之前开始的周期最晚在from+span结束, 可能和w重叠, 每次往前展开的长度加倍
+		step *= 2
+		from = w.start.Add(-step)
+	}
+}
+
+// windowsBetween 获取[from, until)内开始的周期
+func (expression *ICalExpression) windowsBetween(from time.Time, until time.Time) (windows []window, exhausted bool) {
+	exhausted = true
+	for _, event := range expression.events {
+		windows = append(windows, event.windows(from, until)...)
+		if !event.bounded() {
+			exhausted = false
+		}
+	}
+	return mergeWindows(windows), exhausted
+}
+
+// IsIn 判断时间是否在某个事件内
+func (expression *ICalExpression) IsIn(t time.Time) bool {
+	windows, _ := expression.windows(t)
+	return isInWindows(windows, t)
+}
+
+// GetStartTime 获取开始时间, 在周期内返回本次周期的开始时间, 否则返回下次周期的开始时间
+func (expression *ICalExpression) GetStartTime(t time.Time) (time.Time, error) {
+	windows, exhausted := expression.windows(t)
+	w, err := windowAt(windows, t, exhausted)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return w.start, nil
+}
+
+// GetEndTime 获取结束时间, 在周期内返回本次周期的结束时间, 否则返回下次周期的结束时间
+func (expression *ICalExpression) GetEndTime(t time.Time) (time.Time, error) {
+	windows, exhausted := expression.windows(t)
+	w, err := windowAt(windows, t, exhausted)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return w.end, nil
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在周期内，都获取下次的时间
+func (expression *ICalExpression) GetNextStartTime(t time.Time) (time.Time, error) {
+	return nextStartTime(expression, t)
+}
@@ -0,0 +1,264 @@
+package timeexpression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icalWindows 通过GetStartTime和GetNextStartTime获取[from, until)内开始的周期
+func icalWindows(t *testing.T, expr Expression, from time.Time, until time.Time) []window {
+	var windows []window
+	start, err := expr.GetStartTime(from)
+	for err == nil && start.Before(until) {
+		end, endErr := expr.GetEndTime(start)
+		if endErr != nil {
+			t.Fatal(endErr)
+		}
+		windows = append(windows, window{start: start, end: end})
+		start, err = expr.GetNextStartTime(start)
+	}
+	if err != nil && err != ErrOutOfDate {
+		t.Fatal(err)
+	}
+	return windows
+}
+
+func TestParseICalendar_RoundTrip(t *testing.T) {
+	stamp := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testDatas := []struct {
+		exp   string
+		from  time.Time
+		until time.Time
+	}{
+		{
+			exp:   "[*][02-04][05-07][08:00:00-10:00:00,11:00:00-12:30:30]",
+			from:  time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			until: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][*][w6-7][18:00:00-22:00:00]",
+			until: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024-2025][*][05-07][*]",
+			until: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][01-06][29-31][*]",
+			until: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2024][01][*][22:00:00-24:00:00,00:00:00-02:00:00]",
+			until: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from := data.from
+		if from.IsZero() {
+			from, _ = expr.dayRange()
+		}
+
+		events, err := expr.ToVEvents(ICalOptions{From: from, Until: data.until})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := WriteICalendar(&buffer, "-//timeexpression//test//EN", stamp, events); err != nil {
+			t.Fatal(err)
+		}
+
+		imported, err := ParseICalendar(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := icalWindows(t, expr, from, data.until)
+		assert.Equal(t, want, icalWindows(t, imported, from, data.until))
+
+		// 周期的边界上 IsIn 要一致
+		for _, w := range want {
+			for _, point := range []time.Time{w.start.Add(-time.Second), w.start, w.end.Add(-time.Second), w.end} {
+				if !point.Before(data.until) {
+					continue
+				}
+				assert.Equal(t, expr.IsIn(point), imported.IsIn(point), point.String())
+			}
+		}
+	}
+}
+
+func TestParseICalendar(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTART;TZID=Asia/Shanghai:20240101T093000",
+		"DURATION:PT15M",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5",
+		"EXDATE;TZID=Asia/Shanghai:20240103T093000",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT5M",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday@example.com",
+		"DTSTART;VALUE=DATE:20240210",
+		"SUMMARY:long line folded",
+		" into two",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	expr, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, shanghai)
+	}
+	testDatas := []struct {
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		err   error
+	}{
+		{t: at(time.January, 1, 9, 0), start: at(time.January, 1, 9, 30), end: at(time.January, 1, 9, 45)},
+		{t: at(time.January, 1, 9, 40), isIn: true, start: at(time.January, 1, 9, 30), end: at(time.January, 1, 9, 45)},
+		{t: at(time.January, 1, 9, 45), start: at(time.January, 5, 9, 30), end: at(time.January, 5, 9, 45)},
+		// COUNT包含被EXDATE排除的那次
+		{t: at(time.January, 8, 10, 0), start: at(time.January, 10, 9, 30), end: at(time.January, 10, 9, 45)},
+		// 没有时区的日期为本地时间
+		{t: at(time.January, 10, 10, 0), start: time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local), end: time.Date(2024, time.February, 11, 0, 0, 0, 0, time.Local)},
+		{t: at(time.March, 1, 0, 0), err: ErrOutOfDate},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] t:%s\n", i, data.t)
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Equal(t, data.err, err)
+		assert.True(t, data.start.Equal(start), start.String())
+		end, err := expr.GetEndTime(data.t)
+		assert.Equal(t, data.err, err)
+		assert.True(t, data.end.Equal(end), end.String())
+	}
+}
+
+func TestParseICalendar_Recurrence(t *testing.T) {
+	event := func(lines ...string) string {
+		return strings.Join(append(append([]string{"BEGIN:VCALENDAR", "BEGIN:VEVENT"}, lines...), "END:VEVENT", "END:VCALENDAR"), "\r\n")
+	}
+	at := func(year int, month time.Month, day int, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	}
+	testDatas := []struct {
+		ics   string
+		from  time.Time
+		until time.Time
+		want  []window
+	}{
+		{
+			// 2024-01-01是周一, 和规则不符的DTSTART也是第一次, 计入COUNT
+			ics:   event("DTSTART:20240101T090000", "DURATION:PT1H", "RRULE:FREQ=WEEKLY;BYDAY=TU,TH;COUNT=3"),
+			from:  at(2024, time.January, 1, 0),
+			until: at(2024, time.February, 1, 0),
+			want: []window{
+				{start: at(2024, time.January, 1, 9), end: at(2024, time.January, 1, 10)},
+				{start: at(2024, time.January, 2, 9), end: at(2024, time.January, 2, 10)},
+				{start: at(2024, time.January, 4, 9), end: at(2024, time.January, 4, 10)},
+			},
+		},
+		{
+			// 两次发生相隔4年, 在扫描的范围内
+			ics:   event("DTSTART:20240229T090000", "DURATION:PT1H", "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=2"),
+			from:  at(2024, time.January, 1, 0),
+			until: at(2030, time.January, 1, 0),
+			want: []window{
+				{start: at(2024, time.February, 29, 9), end: at(2024, time.February, 29, 10)},
+				{start: at(2028, time.February, 29, 9), end: at(2028, time.February, 29, 10)},
+			},
+		},
+		{
+			// 很早的DTSTART不需要从头展开
+			ics:   event("DTSTART:19900105T090000", "DURATION:PT1H", "RRULE:FREQ=MONTHLY"),
+			from:  at(2024, time.March, 5, 9),
+			until: at(2024, time.May, 1, 0),
+			want: []window{
+				{start: at(2024, time.March, 5, 9), end: at(2024, time.March, 5, 10)},
+				{start: at(2024, time.April, 5, 9), end: at(2024, time.April, 5, 10)},
+			},
+		},
+		{
+			// 每次25小时, 和之前的周期重叠, 合并为从DTSTART开始的一个周期
+			ics:   event("DTSTART:20200101T000000", "DURATION:P1DT1H", "RRULE:FREQ=DAILY;UNTIL=20240310"),
+			from:  at(2024, time.March, 5, 9),
+			until: at(2024, time.May, 1, 0),
+			want: []window{
+				{start: at(2020, time.January, 1, 0), end: at(2024, time.March, 11, 1)},
+			},
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] ics:%q\n", i, data.ics)
+		expr, err := ParseICalendar(strings.NewReader(data.ics))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.want, icalWindows(t, expr, data.from, data.until))
+	}
+}
+
+func TestParseICalendar_Error(t *testing.T) {
+	event := func(lines ...string) string {
+		return strings.Join(append(append([]string{"BEGIN:VCALENDAR", "BEGIN:VEVENT"}, lines...), "END:VEVENT", "END:VCALENDAR"), "\r\n")
+	}
+	testDatas := []struct {
+		ics string
+		err error
+	}{
+		{ics: event("DTSTART:20240101T080000", "RDATE:20240105T080000"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "EXRULE:FREQ=WEEKLY"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RECURRENCE-ID:20240105T080000"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=HOURLY"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=MONTHLY;BYDAY=1MO"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=MONTHLY;BYSETPOS=1"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=DAILY", "RRULE:FREQ=WEEKLY"), err: ErrICalUnsupported},
+		{ics: event("DTSTART;TZID=Mars/Olympus:20240101T080000"), err: ErrICalUnsupported},
+		{ics: event("DTSTART:20240101T080000", "DURATION:-PT1H"), err: ErrICalUnsupported},
+		// 第一次之后再也不会发生, COUNT不会在MaxYear之前逐天展开
+		{ics: event("DTSTART:20240101T080000", "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30;COUNT=3"), err: ErrBeyondScanHorizon},
+		{ics: event("DTEND:20240101T080000"), err: ErrICalFormat},
+		{ics: event("DTSTART:20240101T080000", "DTEND:20231231T080000"), err: ErrICalFormat},
+		{ics: event("DTSTART:2024-01-01"), err: ErrICalFormat},
+		{ics: event("DTSTART:20240101T080000", "RRULE:INTERVAL=2"), err: ErrICalFormat},
+		{ics: "BEGIN:VCALENDAR\r\nEND:VCALENDAR", err: ErrICalFormat},
+		{ics: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T080000\r\nEND:VCALENDAR", err: ErrICalFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] ics:%q\n", i, data.ics)
+		_, err := ParseICalendar(strings.NewReader(data.ics))
+		assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+	}
+}
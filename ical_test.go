@@ -0,0 +1,247 @@
+package timeexpression
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// expandVEvents 展开事件中的RRULE, 获取[from, until)内开始的周期
+func expandVEvents(t *testing.T, events []VEvent, from time.Time, until time.Time) []window {
+	var windows []window
+	for _, event := range events {
+		if event.RRule == "" {
+			if event.Start.Before(until) {
+				windows = append(windows, window{start: event.Start, end: event.End})
+			}
+			continue
+		}
+		rule, err := parseRRule(event.RRule, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+	occurrences:
+		for _, occurrence := range rule.occurrences(event.Start, from, until, maxScanDays) {
+			for _, exDate := range event.ExDates {
+				if exDate.Equal(occurrence) {
+					continue occurrences
+				}
+			}
+			windows = append(windows, window{start: occurrence, end: occurrenceEnd(event, occurrence)})
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+	return windows
+}
+
+// nextStartWindows 通过GetNextStartTime获取[from, until)内开始的周期
+func nextStartWindows(t *testing.T, expr Expression, from time.Time, until time.Time) []window {
+	var windows []window
+	start, err := expr.GetStartTime(from)
+	for err == nil && start.Before(until) {
+		end, endErr := expr.GetEndTime(start)
+		if endErr != nil {
+			t.Fatal(endErr)
+		}
+		windows = append(windows, window{start: start, end: end})
+		start, err = expr.GetNextStartTime(start)
+	}
+	if err != nil && err != ErrOutOfDate {
+		t.Fatal(err)
+	}
+	return windows
+}
+
+func TestDateTimeExpression_ToVEvents(t *testing.T) {
+	stamp := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testDatas := []struct {
+		name      string
+		exp       string
+		from      time.Time
+		until     time.Time
+		recurring bool
+	}{
+		{
+			name:      "daily_hours",
+			exp:       "[*][02-04][05-07][08:00:00-10:00:00,11:00:00-12:30:30]",
+			from:      time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			until:     time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local),
+			recurring: true,
+		},
+		{
+			name:      "weekend_evening",
+			exp:       "[2024][*][w6-7][18:00:00-22:00:00]",
+			until:     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+			recurring: true,
+		},
+		{
+			name:      "monthly_days",
+			exp:       "[2024-2025][*][05-07][*]",
+			until:     time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local),
+			recurring: true,
+		},
+		{
+			name:  "month_end_instances",
+			exp:   "[2024][01-06][29-31][*]",
+			until: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events, err := expr.ToVEvents(ICalOptions{From: data.from, Summary: data.name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, data.recurring, events[0].RRule != "")
+
+		assertICalGolden(t, data.name, stamp, events)
+
+		// 展开后的周期要和GetNextStartTime得到的一致
+		from := data.from
+		if from.IsZero() {
+			from, _ = expr.dayRange()
+		}
+		assert.Equal(t, nextStartWindows(t, expr, from, data.until), expandVEvents(t, events, from, data.until))
+	}
+}
+
+// assertICalGolden 比较生成的.ics文档和testdata/ical中的文件, -update时更新文件
+func assertICalGolden(t *testing.T, name string, stamp time.Time, events []VEvent) {
+	var buffer bytes.Buffer
+	err := WriteICalendar(&buffer, "-//timeexpression//test//EN", stamp, events)
+	assert.Nil(t, err)
+
+	golden := filepath.Join("testdata", "ical", name+".ics")
+	if *updateGolden {
+		if err := ioutil.WriteFile(golden, buffer.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), buffer.String())
+}
+
+func TestAmendedExpression_ToVEvents(t *testing.T) {
+	stamp := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	// 排除的周期输出为EXDATE, 增加的时间段为单次的事件
+	expr, err := ParseAmendedExpression("[2024][*][w6][20:00:00-22:00:00]; except 2024-02-10,2024-06-01 19:00:00-23:00:00; plus 2024-03-01 20:00:00-22:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := expr.ToVEvents(ICalOptions{Summary: "amended_saturday_evening"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, events, 2)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, time.February, 10, 20, 0, 0, 0, time.Local),
+		time.Date(2024, time.June, 1, 20, 0, 0, 0, time.Local),
+	}, events[0].ExDates)
+	assertICalGolden(t, "amended_saturday_evening", stamp, events)
+	from := events[0].Start
+	assert.Equal(t, nextStartWindows(t, expr, from, until), expandVEvents(t, events, from, until))
+
+	// 逐个生成周期时直接生成排除和增加之后的周期
+	expr, err = ParseAmendedExpression("[2024][01-03][29-31][*]; except 2024-01-30; plus 2024-04-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err = expr.ToVEvents(ICalOptions{})
+	assert.Nil(t, err)
+	from = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, nextStartWindows(t, expr, from, until), expandVEvents(t, events, from, until))
+
+	// RRULE无法表达的排除和增加
+	for _, exp := range []string{
+		"[2024][*][w6][20:00:00-22:00:00]; except 2024-02-10 21:00:00-22:00:00",
+		"[2024][*][w6][20:00:00-22:00:00]; plus 2024-02-10 21:00:00-23:00:00",
+	} {
+		fmt.Printf("exp:%s\n", exp)
+		expr, err := ParseAmendedExpression(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = expr.ToVEvents(ICalOptions{})
+		assert.True(t, errors.Is(err, ErrICalLossy), fmt.Sprint(err))
+	}
+
+	expr, err = ParseAmendedExpression("[*][r2024-03-04 20:00:00/14d/2h]; except 2024-03-18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.ToVEvents(ICalOptions{})
+	assert.True(t, errors.Is(err, ErrICalUnsupported), fmt.Sprint(err))
+}
+
+func TestDateTimeExpression_ToVEvents_DST(t *testing.T) {
+	// 豪勋爵岛2024-10-06 02:00 夏令时开始, 时钟拨快30分钟
+	lordHowe, err := time.LoadLocation("Australia/Lord_Howe")
+	if err != nil {
+		t.Skip(err)
+	}
+	local := time.Local
+	time.Local = lordHowe
+	defer func() { time.Local = local }()
+
+	expr, err := NewDateTimeExpression("[2024][10][*][01:00:00-04:00:00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := expr.ToVEvents(ICalOptions{From: time.Date(2024, time.October, 6, 0, 0, 0, 0, time.Local)})
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, time.Date(2024, time.October, 6, 1, 0, 0, 0, time.Local), events[0].Start)
+	assert.Equal(t, time.Date(2024, time.October, 6, 4, 0, 0, 0, time.Local), events[0].End)
+
+	until := time.Date(2024, time.October, 9, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, nextStartWindows(t, expr, events[0].Start, until), expandVEvents(t, events, events[0].Start, until))
+}
+
+func TestDateTimeExpression_ToVEvents_Unbounded(t *testing.T) {
+	expr, err := NewDateTimeExpression("[*][*][01-31][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+	_, err = expr.ToVEvents(ICalOptions{From: from})
+	assert.Equal(t, ErrICalUnbounded, err)
+
+	events, err := expr.ToVEvents(ICalOptions{From: from, Until: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local)})
+	assert.Nil(t, err)
+	assert.Len(t, events, 3)
+
+	// UID 对于同一个周期是稳定的
+	again, err := expr.ToVEvents(ICalOptions{From: from, Until: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local)})
+	assert.Nil(t, err)
+	assert.Equal(t, events[0].UID, again[0].UID)
+	assert.Equal(t, events[1].UID, again[1].UID)
+	assert.NotEqual(t, events[0].UID, events[1].UID)
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + string(bytes.Repeat([]byte("a"), 100))
+	folded := foldICalLine(line)
+	assert.Equal(t, "SUMMARY:"+string(bytes.Repeat([]byte("a"), 67))+"\r\n "+string(bytes.Repeat([]byte("a"), 33))+"\r\n", folded)
+}
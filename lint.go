@@ -0,0 +1,280 @@
+package timeexpression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity 检查结果的严重程度
+type Severity int
+
+const (
+	// SeverityInfo 仅提示
+	SeverityInfo Severity = iota
+	// SeverityWarning 表达式可用,但部分配置不会生效
+	SeverityWarning
+	// SeverityError 表达式无法生效, 应当阻止使用
+	SeverityError
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(severity))
+}
+
+const (
+	// LintUnsatisfiable 表达式永远不会命中
+	LintUnsatisfiable = "unsatisfiable"
+	// LintDayOutOfMonth 配置的日超出了部分月份的天数
+	LintDayOutOfMonth = "day-out-of-month"
+	// LintYearInPast 年的范围已经过去
+	LintYearInPast = "year-in-past"
+	// LintPolarPeriod 所在的位置有极昼或者极夜, 这些天没有用到日出日落的时间段
+	LintPolarPeriod = "polar-period"
+)
+
+// LintFinding 表达式的一条检查结果
+type LintFinding struct {
+	Severity Severity
+	Code     string
+	Message  string
+}
+
+func (finding LintFinding) String() string {
+	return fmt.Sprintf("%s [%s] %s", finding.Severity, finding.Code, finding.Message)
+}
+
+// ValidationError Validate发现了错误级别的检查结果
+type ValidationError struct {
+	Findings []LintFinding
+}
+
+func (err *ValidationError) Error() string {
+	messages := make([]string, 0, len(err.Findings))
+	for _, finding := range err.Findings {
+		messages = append(messages, finding.String())
+	}
+	return "expression is invalid: " + strings.Join(messages, "; ")
+}
+
+// Lint 对表达式做语义检查, 解析通过的表达式也可能永远不会命中, 例如 [2021][02][30][*]
+// now 用于判断年的范围是否已经过去
+func (expression *DateTimeExpression) Lint(now time.Time) []LintFinding {
+	var findings []LintFinding
+
+	findings = append(findings, expression.lintDay()...)
+	findings = append(findings, expression.lintYear(now)...)
+	findings = append(findings, expression.lintSun(now)...)
+
+	return findings
+}
+
+// Validate 检查表达式, 有错误级别的检查结果时返回*ValidationError
+func (expression *DateTimeExpression) Validate(now time.Time) error {
+	var errFindings []LintFinding
+	for _, finding := range expression.Lint(now) {
+		if finding.Severity == SeverityError {
+			errFindings = append(errFindings, finding)
+		}
+	}
+	if len(errFindings) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Findings: errFindings}
+}
+
+// maxDaysIn 计算选中的年份范围内, 某个月最多有多少天
+func (expression *DateTimeExpression) maxDaysIn(month time.Month) int {
+	if month != time.February {
+		return daysIn(month, 2001)
+	}
+	first, last := expression.year.bounds()
+	for year := first; year <= last; year++ {
+		if isLeap(year) {
+			return 29
+		}
+	}
+	return 28
+}
+
+// lintDay 检查配置的日是否超出了选中月份的天数
+func (expression *DateTimeExpression) lintDay() []LintFinding {
+	if expression.month.kind == monthKindWeekOfYear {
+		return expression.lintWeekOfYear()
+	}
+	switch expression.day.kind {
+	case dayKindDayOfYear:
+		return expression.lintDayOfYear()
+	case dayKindMonthDay:
+		if expression.day.isAll {
+			return nil
+		}
+	default:
+		// 星期几, 月中的位置, 工作日和节气每个月都可能有
+		return nil
+	}
+	switch expression.month.kind {
+	case monthKindLunar, monthKindLunarLeap:
+		return expression.lintLunarDay()
+	case monthKindFiscal, monthKindFiscalQuarter:
+		// 每期的天数取决于财年的定义
+		return nil
+	}
+
+	var findings []LintFinding
+	var shortMonths []string
+	satisfiable := false
+	for month := expression.month.start; month <= expression.month.end; month++ {
+		days := expression.maxDaysIn(time.Month(month))
+		if expression.day.start <= days {
+			satisfiable = true
+		}
+		if expression.day.end > days {
+			shortMonths = append(shortMonths, time.Month(month).String())
+		}
+	}
+
+	if !satisfiable {
+		findings = append(findings, LintFinding{
+			Severity: SeverityError,
+			Code:     LintUnsatisfiable,
+			Message: fmt.Sprintf("day %d-%d never exists in the selected months, expression never matches",
+				expression.day.start, expression.day.end),
+		})
+	} else if len(shortMonths) > 0 {
+		findings = append(findings, LintFinding{
+			Severity: SeverityWarning,
+			Code:     LintDayOutOfMonth,
+			Message: fmt.Sprintf("day %d is beyond the end of %s, those days are skipped",
+				expression.day.end, strings.Join(shortMonths, ", ")),
+		})
+	}
+
+	return findings
+}
+
+// lintLunarDay 检查配置的日是否超出了农历月份的天数, 农历大月30天, 小月29天
+func (expression *DateTimeExpression) lintLunarDay() []LintFinding {
+	switch {
+	case expression.day.start > 30:
+		return []LintFinding{{
+			Severity: SeverityError,
+			Code:     LintUnsatisfiable,
+			Message: fmt.Sprintf("day %d-%d never exists in lunar months, expression never matches",
+				expression.day.start, expression.day.end),
+		}}
+	case expression.day.end > 29:
+		return []LintFinding{{
+			Severity: SeverityWarning,
+			Code:     LintDayOutOfMonth,
+			Message:  fmt.Sprintf("day %d is beyond the end of short lunar months, those days are skipped", expression.day.end),
+		}}
+	}
+	return nil
+}
+
+// lintWeekOfYear 检查第53周是否存在, 只有52周的年没有第53周
+func (expression *DateTimeExpression) lintWeekOfYear() []LintFinding {
+	if expression.month.end < 53 {
+		return nil
+	}
+	return expression.lintRareDay(expression.month.start == 53, fmt.Sprintf("ISO week %s", expression.month), "years with 53 weeks",
+		func(year int) bool {
+			_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.Local).ISOWeek()
+			return week == 53
+		})
+}
+
+// lintDayOfYear 检查第366天是否存在, 只有闰年有第366天
+func (expression *DateTimeExpression) lintDayOfYear() []LintFinding {
+	if expression.day.end < 366 {
+		return nil
+	}
+	return expression.lintRareDay(expression.day.start == 366, fmt.Sprintf("day of year %s", expression.day), "leap years", isLeap)
+}
+
+// lintRareDay 检查只在部分年份中存在的周或者日, only为true时表示只配置了这一周或者这一天
+// 年的范围内都没有时永远不会命中, 部分年份没有时这些年份会被跳过
+func (expression *DateTimeExpression) lintRareDay(only bool, field string, years string, exists func(year int) bool) []LintFinding {
+	found, missing := false, false
+	first, last := expression.year.bounds()
+	for year := first; year <= last && !(found && missing); year++ {
+		if exists(year) {
+			found = true
+		} else {
+			missing = true
+		}
+	}
+
+	switch {
+	case only && !found:
+		return []LintFinding{{
+			Severity: SeverityError,
+			Code:     LintUnsatisfiable,
+			Message:  fmt.Sprintf("%s only exists in %s, expression never matches", field, years),
+		}}
+	case missing:
+		return []LintFinding{{
+			Severity: SeverityWarning,
+			Code:     LintDayOutOfMonth,
+			Message:  fmt.Sprintf("%s only exists in %s, other years are skipped", field, years),
+		}}
+	}
+	return nil
+}
+
+// lintYear 检查年的范围是否已经过去
+func (expression *DateTimeExpression) lintYear(now time.Time) []LintFinding {
+	if !expression.year.hasEnd || expression.year.end >= now.Year() {
+		return nil
+	}
+	switch expression.month.kind {
+	case monthKindLunar, monthKindLunarLeap, monthKindWeekOfYear, monthKindFiscal, monthKindFiscalQuarter:
+		if _, last := expression.dayRange(); !truncateDay(now).After(last) {
+			// 农历年, ISO周所属的年和财年的最后一部分在下一个公历年
+			return nil
+		}
+	}
+
+	return []LintFinding{{
+		Severity: SeverityError,
+		Code:     LintYearInPast,
+		Message:  fmt.Sprintf("year %d has already passed, expression never matches again", expression.year.end),
+	}}
+}
+
+// lintSun 检查日出日落的时间段, 按now所在的一年统计极昼和极夜的天数, 这些天没有日出日落, 时间段不会出现
+func (expression *DateTimeExpression) lintSun(now time.Time) []LintFinding {
+	if !expression.hour.hasSun {
+		return nil
+	}
+
+	polarDays, polarNights := 0, 0
+	for day := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.Local); day.Year() == now.Year(); day = day.AddDate(0, 0, 1) {
+		switch _, _, err := expression.location.SunTimes(day); err {
+		case ErrPolarDay:
+			polarDays++
+		case ErrPolarNight:
+			polarNights++
+		}
+	}
+	if polarDays == 0 && polarNights == 0 {
+		return nil
+	}
+
+	return []LintFinding{{
+		Severity: SeverityWarning,
+		Code:     LintPolarPeriod,
+		Message: fmt.Sprintf("latitude %.4f has %d days of polar day and %d days of polar night in %d, hour %s does not occur on them",
+			expression.location.Latitude, polarDays, polarNights, now.Year(), expression.clockHour),
+	}}
+}
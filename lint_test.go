@@ -0,0 +1,161 @@
+package timeexpression
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDateTimeExpression_Lint(t *testing.T) {
+	now := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.Local)
+
+	testDatas := []struct {
+		exp        string
+		codes      []string
+		severities []Severity
+	}{
+		{
+			exp: "[*][*][*][*]",
+		},
+		{
+			exp: "[2000][02-04][05-07][8:00:00-10:00:00,11:00:00-12:30:30]",
+			// 2000年已经过去了
+			codes:      []string{LintYearInPast},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp:        "[2021][02][30][*]",
+			codes:      []string{LintUnsatisfiable, LintYearInPast},
+			severities: []Severity{SeverityError, SeverityError},
+		},
+		{
+			exp:        "[*][04][31][*]",
+			codes:      []string{LintUnsatisfiable},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp: "[2024][02][29][*]",
+		},
+		{
+			// 2021年只有52周, 2022-01-02之后已经过去
+			exp:        "[2021][W53][*][*]",
+			codes:      []string{LintUnsatisfiable, LintYearInPast},
+			severities: []Severity{SeverityError, SeverityError},
+		},
+		{
+			exp: "[2026][W53][*][*]",
+		},
+		{
+			exp:        "[*][W50-53][*][*]",
+			codes:      []string{LintDayOutOfMonth},
+			severities: []Severity{SeverityWarning},
+		},
+		{
+			exp:        "[2023][*][D366][*]",
+			codes:      []string{LintUnsatisfiable},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp: "[2024][*][D300-366][*]",
+		},
+		{
+			// 2024年是闰年
+			exp: "[2023-2025][02][29][*]",
+		},
+		{
+			exp:        "[2025][02][29][*]",
+			codes:      []string{LintUnsatisfiable},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp:        "[*][03-04][29-31][*]",
+			codes:      []string{LintDayOutOfMonth},
+			severities: []Severity{SeverityWarning},
+		},
+		{
+			// 农历小月没有30日
+			exp:        "[*][L01][30][*]",
+			codes:      []string{LintDayOutOfMonth},
+			severities: []Severity{SeverityWarning},
+		},
+		{
+			exp:        "[*][L01][31][*]",
+			codes:      []string{LintUnsatisfiable},
+			severities: []Severity{SeverityError},
+		},
+		{
+			// 农历2021年腊月在2022年1月, 也已经过去了
+			exp:        "[2021][L12][01][*]",
+			codes:      []string{LintYearInPast},
+			severities: []Severity{SeverityError},
+		},
+		{
+			// 2021财年到2022年3月结束, 还没有过去
+			exp: "[2021][FQ4][*][*]",
+		},
+		{
+			exp:        "[2020][F*][*][*]",
+			codes:      []string{LintYearInPast},
+			severities: []Severity{SeverityError},
+		},
+		{
+			// 没有结束年的不会过去
+			exp:        "[2020-][02][30][*]",
+			codes:      []string{LintUnsatisfiable},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp:        "[-2021][*][*][*]",
+			codes:      []string{LintYearInPast},
+			severities: []Severity{SeverityError},
+		},
+		{
+			exp:        "[2021-][W53][*][*]",
+			codes:      []string{LintDayOutOfMonth},
+			severities: []Severity{SeverityWarning},
+		},
+		{
+			exp: "[*][*][*][08:00:00-09:00:00.500,09:00:00.750-10:00:00]",
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp, WithFiscalCalendar(FiscalCalendar{StartMonth: time.April}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		findings := expr.Lint(now)
+		var codes []string
+		var severities []Severity
+		for _, finding := range findings {
+			codes = append(codes, finding.Code)
+			severities = append(severities, finding.Severity)
+		}
+		assert.Equal(t, data.codes, codes)
+		assert.Equal(t, data.severities, severities)
+	}
+}
+
+func TestDateTimeExpression_Validate(t *testing.T) {
+	now := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.Local)
+
+	expr, err := NewDateTimeExpression("[*][03-04][29-31][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, expr.Validate(now))
+
+	expr, err = NewDateTimeExpression("[*][04][31][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = expr.Validate(now)
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, validationErr.Findings, 1)
+	assert.Equal(t, LintUnsatisfiable, validationErr.Findings[0].Code)
+	assert.Contains(t, err.Error(), "error [unsatisfiable]")
+}
@@ -0,0 +1,183 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrLunarOutOfRange 超出了农历支持的范围, 支持农历1900年正月初一(1900-01-31)到2100年腊月的最后一天
+var ErrLunarOutOfRange = errors.New("lunar date out of range")
+
+const (
+	lunarMinYear = 1900
+	lunarMaxYear = 2100
+)
+
+// lunarEpoch 农历1900年正月初一
+var lunarEpoch = time.Date(1900, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+// lunarInfo 1900-2100年的农历数据, 每年一项
+// bit0-3: 闰几月, 0表示没有闰月; bit4-15: 1-12月是否为大月(30天), 1月在bit15; bit16: 闰月是否为大月
+// 已和按天文算法计算的朔日, 中气逐月核对, 不一致的只有1906年四月和2057年九月两个在0点附近的朔日, 以数据表为准
+var lunarInfo = [lunarMaxYear - lunarMinYear + 1]int{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900-1909
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910-1919
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920-1929
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930-1939
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940-1949
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950-1959
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960-1969
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970-1979
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980-1989
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990-1999
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050-2059
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060-2069
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070-2079
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080-2089
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090-2099
+	0x0d520, // 2100
+}
+
+// lunarYearOffsets 每年正月初一距离lunarEpoch的天数, 最后一项为2100年之后的第一天
+var lunarYearOffsets = func() []int {
+	offsets := make([]int, 0, len(lunarInfo)+1)
+	offset := 0
+	for year := lunarMinYear; year <= lunarMaxYear; year++ {
+		offsets = append(offsets, offset)
+		offset += lunarYearDays(year)
+	}
+	return append(offsets, offset)
+}()
+
+// lunarLeapMonth 农历某年闰几月, 0表示没有闰月
+func lunarLeapMonth(year int) int {
+	return lunarInfo[year-lunarMinYear] & 0xf
+}
+
+// lunarMonthDays 农历某年某月的天数, leap为true时为闰月的天数
+func lunarMonthDays(year int, month int, leap bool) int {
+	info := lunarInfo[year-lunarMinYear]
+	bit := 0x10000 >> uint(month)
+	if leap {
+		bit = 0x10000
+	}
+	if info&bit != 0 {
+		return 30
+	}
+	return 29
+}
+
+// lunarYearDays 农历某年的天数
+func lunarYearDays(year int) int {
+	days := 0
+	for month := 1; month <= 12; month++ {
+		days += lunarMonthDays(year, month, false)
+	}
+	if lunarLeapMonth(year) != 0 {
+		days += lunarMonthDays(year, lunarLeapMonth(year), true)
+	}
+	return days
+}
+
+// lunarDayNumber 时间t所在的日期距离lunarEpoch的天数, 按UTC计算避免夏令时的影响
+func lunarDayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Sub(lunarEpoch) / (24 * time.Hour))
+}
+
+// LunarDate 农历日期
+type LunarDate struct {
+	Year  int
+	Month int
+	// Leap 是否为闰月, 例如2023年闰二月为Month=2, Leap=true
+	Leap bool
+	Day  int
+}
+
+// ToLunar 把时间t所在的日期转换为农历日期
+func ToLunar(t time.Time) (LunarDate, error) {
+	offset := lunarDayNumber(t)
+	if offset < 0 || offset >= lunarYearOffsets[len(lunarYearOffsets)-1] {
+		return LunarDate{}, ErrLunarOutOfRange
+	}
+
+	// 第一个正月初一晚于这一天的年份的前一年
+	idx := sort.Search(len(lunarYearOffsets), func(i int) bool { return lunarYearOffsets[i] > offset }) - 1
+	date := LunarDate{Year: lunarMinYear + idx}
+	offset -= lunarYearOffsets[idx]
+
+	leapMonth := lunarLeapMonth(date.Year)
+	for month := 1; month <= 12; month++ {
+		if days := lunarMonthDays(date.Year, month, false); offset >= days {
+			offset -= days
+		} else {
+			date.Month = month
+			break
+		}
+		if month == leapMonth {
+			if days := lunarMonthDays(date.Year, month, true); offset >= days {
+				offset -= days
+			} else {
+				date.Month = month
+				date.Leap = true
+				break
+			}
+		}
+	}
+	date.Day = offset + 1
+	return date, nil
+}
+
+// Time 农历日期对应的公历日期, 为当天的0点
+func (date LunarDate) Time() (time.Time, error) {
+	if date.Year < lunarMinYear || date.Year > lunarMaxYear {
+		return time.Time{}, ErrLunarOutOfRange
+	}
+	if date.Month < 1 || date.Month > 12 || (date.Leap && lunarLeapMonth(date.Year) != date.Month) ||
+		date.Day < 1 || date.Day > lunarMonthDays(date.Year, date.Month, date.Leap) {
+		return time.Time{}, fmt.Errorf("%w: %s does not exist", ErrLunarOutOfRange, date)
+	}
+
+	offset := lunarYearOffsets[date.Year-lunarMinYear]
+	for month := 1; month < date.Month; month++ {
+		offset += lunarMonthDays(date.Year, month, false)
+		if month == lunarLeapMonth(date.Year) {
+			offset += lunarMonthDays(date.Year, month, true)
+		}
+	}
+	if date.Leap {
+		offset += lunarMonthDays(date.Year, date.Month, false)
+	}
+	offset += date.Day - 1
+
+	epoch := lunarEpoch.AddDate(0, 0, offset)
+	return time.Date(epoch.Year(), epoch.Month(), epoch.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// monthDays 农历日期所在月份的天数
+func (date LunarDate) monthDays() int {
+	return lunarMonthDays(date.Year, date.Month, date.Leap)
+}
+
+// String 格式化为yyyy-MM-dd, 闰月在月份后加bis, etc: 2023-02bis-01
+func (date LunarDate) String() string {
+	leap := ""
+	if date.Leap {
+		leap = "bis"
+	}
+	return fmt.Sprintf("%04d-%02d%s-%02d", date.Year, date.Month, leap, date.Day)
+}
+
+// lunarRange 农历年份范围内的第一天和最后一天
+func lunarRange(startYear int, endYear int) (first time.Time, last time.Time) {
+	first = lunarEpoch.AddDate(0, 0, lunarYearOffsets[startYear-lunarMinYear])
+	last = lunarEpoch.AddDate(0, 0, lunarYearOffsets[endYear-lunarMinYear+1]-1)
+	return time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.Local),
+		time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,255 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestToLunar(t *testing.T) {
+	testDatas := []struct {
+		t     time.Time
+		lunar string
+		err   error
+	}{
+		// 春节
+		{t: time.Date(1900, time.January, 31, 0, 0, 0, 0, time.Local), lunar: "1900-01-01"},
+		{t: time.Date(1901, time.February, 19, 0, 0, 0, 0, time.Local), lunar: "1901-01-01"},
+		{t: time.Date(1912, time.February, 18, 0, 0, 0, 0, time.Local), lunar: "1912-01-01"},
+		{t: time.Date(1949, time.January, 29, 0, 0, 0, 0, time.Local), lunar: "1949-01-01"},
+		{t: time.Date(1966, time.January, 21, 0, 0, 0, 0, time.Local), lunar: "1966-01-01"},
+		{t: time.Date(1985, time.February, 20, 0, 0, 0, 0, time.Local), lunar: "1985-01-01"},
+		{t: time.Date(2000, time.February, 5, 0, 0, 0, 0, time.Local), lunar: "2000-01-01"},
+		{t: time.Date(2008, time.February, 7, 0, 0, 0, 0, time.Local), lunar: "2008-01-01"},
+		{t: time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local), lunar: "2024-01-01"},
+		{t: time.Date(2025, time.January, 29, 0, 0, 0, 0, time.Local), lunar: "2025-01-01"},
+		{t: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.Local), lunar: "2026-01-01"},
+		{t: time.Date(2050, time.January, 23, 0, 0, 0, 0, time.Local), lunar: "2050-01-01"},
+		{t: time.Date(2100, time.February, 9, 0, 0, 0, 0, time.Local), lunar: "2100-01-01"},
+		// 除夕, 端午, 中秋
+		{t: time.Date(2024, time.February, 9, 23, 59, 59, 0, time.Local), lunar: "2023-12-30"},
+		{t: time.Date(2024, time.June, 10, 0, 0, 0, 0, time.Local), lunar: "2024-05-05"},
+		{t: time.Date(2024, time.September, 17, 12, 0, 0, 0, time.Local), lunar: "2024-08-15"},
+		{t: time.Date(2023, time.September, 29, 0, 0, 0, 0, time.Local), lunar: "2023-08-15"},
+		// 闰月
+		{t: time.Date(1900, time.September, 24, 0, 0, 0, 0, time.Local), lunar: "1900-08bis-01"},
+		{t: time.Date(2017, time.July, 23, 0, 0, 0, 0, time.Local), lunar: "2017-06bis-01"},
+		{t: time.Date(2020, time.May, 23, 0, 0, 0, 0, time.Local), lunar: "2020-04bis-01"},
+		{t: time.Date(2023, time.March, 22, 0, 0, 0, 0, time.Local), lunar: "2023-02bis-01"},
+		{t: time.Date(2023, time.April, 20, 0, 0, 0, 0, time.Local), lunar: "2023-03-01"},
+		{t: time.Date(2025, time.July, 25, 0, 0, 0, 0, time.Local), lunar: "2025-06bis-01"},
+		{t: time.Date(2033, time.December, 22, 0, 0, 0, 0, time.Local), lunar: "2033-11bis-01"},
+		// 范围
+		{t: time.Date(2101, time.January, 28, 0, 0, 0, 0, time.Local), lunar: "2100-12-29"},
+		{t: time.Date(2101, time.January, 29, 0, 0, 0, 0, time.Local), err: ErrLunarOutOfRange},
+		{t: time.Date(1900, time.January, 30, 0, 0, 0, 0, time.Local), err: ErrLunarOutOfRange},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] t:%s\n", i, data.t)
+		lunar, err := ToLunar(data.t)
+		assert.Equal(t, data.err, err)
+		if data.err != nil {
+			continue
+		}
+		assert.Equal(t, data.lunar, lunar.String())
+		day, err := lunar.Time()
+		assert.Nil(t, err)
+		assert.Equal(t, truncateDay(data.t), day)
+	}
+}
+
+func TestLunarDate_Time(t *testing.T) {
+	testDatas := []LunarDate{
+		{Year: 2024, Month: 2, Leap: true, Day: 1},
+		{Year: 2024, Month: 13, Day: 1},
+		{Year: 2024, Month: 1, Day: 31},
+		{Year: 2023, Month: 2, Leap: true, Day: 30},
+		{Year: 1899, Month: 12, Day: 1},
+		{Year: 2101, Month: 1, Day: 1},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] lunar:%s\n", i, data)
+		_, err := data.Time()
+		assert.True(t, errors.Is(err, ErrLunarOutOfRange), fmt.Sprint(err))
+	}
+}
+
+// TestToLunar_All 逐天检查1900-2100年, 农历日期连续递增, 并且可以转换回公历
+func TestToLunar_All(t *testing.T) {
+	pre, err := ToLunar(time.Date(1900, time.January, 31, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leapMonths := 0
+	// 按中午逐天遍历, 部分时区历史上调整时差时0点附近的时间不存在或者重复
+	for day := time.Date(1900, time.February, 1, 12, 0, 0, 0, time.Local); ; day = day.AddDate(0, 0, 1) {
+		lunar, err := ToLunar(day)
+		if err == ErrLunarOutOfRange {
+			break
+		}
+		switch {
+		case lunar.Day > 1:
+			assert.True(t, lunar.Year == pre.Year && lunar.Month == pre.Month && lunar.Leap == pre.Leap && lunar.Day == pre.Day+1, "%s after %s", lunar, pre)
+		case lunar.Leap:
+			leapMonths++
+			assert.True(t, lunar.Year == pre.Year && lunar.Month == pre.Month && !pre.Leap, "%s after %s", lunar, pre)
+		case lunar.Month == 1:
+			assert.True(t, lunar.Year == pre.Year+1 && pre.Month == 12, "%s after %s", lunar, pre)
+		default:
+			assert.True(t, lunar.Year == pre.Year && lunar.Month == pre.Month+1, "%s after %s", lunar, pre)
+		}
+		if lunar.Day == 1 {
+			assert.True(t, pre.Day == 29 || pre.Day == 30, "%s after %s", lunar, pre)
+		}
+
+		back, err := lunar.Time()
+		assert.Nil(t, err)
+		// 和同样用time.Date构造的0点比较, 部分时区历史上的夏令时或者调整时差从0点开始, 当天没有0点
+		if !assert.Equal(t, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local), back, day.Format("2006-01-02")) {
+			return
+		}
+		pre = lunar
+	}
+	assert.Equal(t, "2100-12-29", pre.String())
+	// 201年中有74个闰月
+	assert.Equal(t, 74, leapMonths)
+}
+
+func TestDateTimeExpression_Lunar(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		t     time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+	}{
+		{
+			// 正月初一到十五
+			exp:   "[*][L01][01-15][*]",
+			t:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.February, 25, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][L01][01-15][*]",
+			t:     time.Date(2024, time.February, 25, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.January, 29, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.February, 13, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 中秋晚上
+			exp:   "[*][L08][15][18:00:00-22:00:00]",
+			t:     time.Date(2024, time.September, 17, 19, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.September, 17, 18, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.September, 17, 22, 0, 0, 0, time.Local),
+		},
+		{
+			// 除夕, 腊月的最后一天
+			exp:   "[*][L12][L][20:00:00-24:00:00]",
+			t:     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.January, 28, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 29, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 农历年: 2024年腊月在2025年1月
+			exp:   "[2024][L12][01][*]",
+			t:     time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.December, 31, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// L06不包含闰六月
+			exp:   "[2025][L06][15][*]",
+			t:     time.Date(2025, time.July, 1, 0, 0, 0, 0, time.Local),
+			isIn:  false,
+			start: time.Date(2025, time.July, 9, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.July, 10, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2025][L06bis][15][*]",
+			t:     time.Date(2025, time.July, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.August, 8, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.August, 9, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 范围包含中间的闰月
+			exp:   "[2025][L06-07][15][*]",
+			t:     time.Date(2025, time.July, 10, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.August, 8, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.August, 9, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 下一个闰十一月在2033年, 超过了扫描的范围, 按农历数据表直接跳过去
+			exp:   "[*][L11bis][*][*]",
+			t:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: time.Date(2033, time.December, 22, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2034, time.January, 20, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][L11bis][15][*]",
+			t:     time.Date(2033, time.December, 25, 0, 0, 0, 0, time.Local),
+			start: time.Date(2034, time.January, 5, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2034, time.January, 6, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[*][L*][15][*]",
+			t:     time.Date(2025, time.August, 9, 0, 0, 0, 0, time.Local),
+			start: time.Date(2025, time.September, 6, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2025, time.September, 7, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+}
+
+func TestDateTimeExpression_LunarFirstFinal(t *testing.T) {
+	expr, err := NewDateTimeExpression("[2024][L01][01-15][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 10, 0, 0, 0, 0, time.Local), first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 25, 0, 0, 0, 0, time.Local), final)
+
+	expr, err = NewDateTimeExpression("[2100][L12][L][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.GetStartTime(time.Date(2101, time.January, 29, 0, 0, 0, 0, time.Local))
+	assert.Equal(t, ErrOutOfDate, err)
+
+	// 2024-2030年都没有闰十一月
+	expr, err = NewDateTimeExpression("[2024-2030][L11bis][*][*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.GetStartTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local))
+	assert.Equal(t, ErrOutOfDate, err)
+
+	for _, exp := range []string{"[2101][L01][01][*]", "[*][L01][15W][*]", "[*][L01][w1#2][*]", "[*][L01-02bis][01][*]", "[*][L13][01][*]", "[*][LL01][01][*]"} {
+		_, err := NewDateTimeExpression(exp)
+		assert.NotNil(t, err, exp)
+	}
+}
@@ -0,0 +1,105 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrMacroFormat 宏的名字不对, 需要以字母开头, 只包含字母, 数字和下划线
+	ErrMacroFormat = errors.New("macro format not math")
+	// ErrUnknownMacro 表达式中使用了没有注册的宏
+	ErrUnknownMacro = errors.New("macro not registered")
+	// ErrMacroCycle 宏的展开中出现了循环
+	ErrMacroCycle = errors.New("macro expands to itself")
+)
+
+// macroPattern 表达式中的宏, etc: @weekend
+var macroPattern = regexp.MustCompile(`@([A-Za-z][A-Za-z0-9_]*)`)
+
+// macroNamePattern 宏的名字
+var macroNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+var (
+	macroMutex sync.RWMutex
+	// macros 宏的名字(小写)到展开内容, 内置了@daily(*), @weekdays(w1-5)和@weekend(w6-7)
+	macros = map[string]string{
+		"daily":    "*",
+		"weekdays": "w1-5",
+		"weekend":  "w6-7",
+	}
+)
+
+// RegisterMacro 注册一个宏, name不带@且不区分大小写, 已存在的(包括内置的@daily, @weekdays, @weekend)会被覆盖
+// 展开内容可以是某个字段或者字段的一部分, 例如 w6-7, 20:00:00-23:00:00, 也可以是整个表达式, 例如 [*][*][@weekend][*]
+// 展开内容中可以使用其他的宏, 未注册的宏和循环在解析表达式时检查
+func RegisterMacro(name string, expansion string) error {
+	if !macroNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %s", ErrMacroFormat, name)
+	}
+
+	macroMutex.Lock()
+	defer macroMutex.Unlock()
+
+	macros[strings.ToLower(name)] = expansion
+	return nil
+}
+
+// LookupMacro 查找宏的展开内容, name不带@且不区分大小写, 展开内容中的宏不会展开
+func LookupMacro(name string) (string, bool) {
+	macroMutex.RLock()
+	defer macroMutex.RUnlock()
+
+	expansion, ok := macros[strings.ToLower(name)]
+	return expansion, ok
+}
+
+// Macros 获取所有注册的宏(包括内置的), 名字为小写, 修改返回的map不影响注册的宏
+func Macros() map[string]string {
+	macroMutex.RLock()
+	defer macroMutex.RUnlock()
+
+	result := make(map[string]string, len(macros))
+	for name, expansion := range macros {
+		result[name] = expansion
+	}
+	return result
+}
+
+// expandMacros 展开表达式中所有的宏, etc: [2024][*][@weekend][@primetime] -> [2024][*][w6-7][20:00:00-23:00:00]
+func expandMacros(expression string) (string, error) {
+	return expandMacrosIn(expression, nil)
+}
+
+// expandMacrosIn 递归展开宏, stack为正在展开的宏, 用于检查循环
+func expandMacrosIn(expression string, stack []string) (string, error) {
+	var err error
+	expanded := macroPattern.ReplaceAllStringFunc(expression, func(token string) string {
+		if err != nil {
+			return ""
+		}
+		name := strings.ToLower(token[1:])
+		for i, expanding := range stack {
+			if expanding == name {
+				err = fmt.Errorf("%w: @%s -> @%s", ErrMacroCycle, strings.Join(stack[i:], " -> @"), name)
+				return ""
+			}
+		}
+		expansion, ok := LookupMacro(name)
+		if !ok {
+			err = fmt.Errorf("%w: %s", ErrUnknownMacro, token)
+			return ""
+		}
+
+		var result string
+		result, err = expandMacrosIn(expansion, append(stack[:len(stack):len(stack)], name))
+		return result
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
@@ -0,0 +1,117 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewDateTimeExpression_Macro(t *testing.T) {
+	for name, expansion := range map[string]string{
+		"primetime": "20:00:00-23:00:00",
+		"morning":   "07:00:00-09:00:00",
+		"peak":      "@morning,@primetime",
+		"Office":    "[*][*][@weekdays][09:00:00-18:00:00]",
+		"summer":    "06-08",
+	} {
+		if err := RegisterMacro(name, expansion); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testDatas := []struct {
+		exp    string
+		result string
+	}{
+		{exp: "[2024][*][@weekend][@primetime]", result: "[2024][*][w6-7][20:00:00-23:00:00]"},
+		{exp: "[*][*][@weekdays][*]", result: "[*][*][w1-5][*]"},
+		{exp: "[*][*][@daily][@primetime]", result: "[*][*][*][20:00:00-23:00:00]"},
+		// 宏中使用其他的宏
+		{exp: "[*][*][*][@peak]", result: "[*][*][*][07:00:00-09:00:00,20:00:00-23:00:00]"},
+		{exp: "[*][*][*][10:00:00-11:00:00,@PrimeTime]", result: "[*][*][*][10:00:00-11:00:00,20:00:00-23:00:00]"},
+		// 整个表达式
+		{exp: "@office", result: "[*][*][w1-5][09:00:00-18:00:00]"},
+		{exp: "[2024][@summer][@weekend][*]", result: "[2024][06-08][w6-7][*]"},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.result, expr.String())
+	}
+
+	// 2024-03-09 是周六
+	expr, err := NewDateTimeExpression("[2024][*][@weekend][@primetime]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, expr.IsIn(time.Date(2024, time.March, 9, 21, 0, 0, 0, time.Local)))
+	assert.False(t, expr.IsIn(time.Date(2024, time.March, 11, 21, 0, 0, 0, time.Local)))
+}
+
+func TestNewDateTimeExpression_MacroError(t *testing.T) {
+	for name, expansion := range map[string]string{
+		"loop":   "@loop",
+		"ping":   "@pong",
+		"pong":   "w1-@ping",
+		"broken": "@missing",
+	} {
+		if err := RegisterMacro(name, expansion); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testDatas := []struct {
+		exp     string
+		err     error
+		message string
+	}{
+		{exp: "[*][*][@holidays][*]", err: ErrUnknownMacro, message: "macro not registered: @holidays"},
+		{exp: "[*][*][@broken][*]", err: ErrUnknownMacro, message: "macro not registered: @missing"},
+		{exp: "[*][*][@loop][*]", err: ErrMacroCycle, message: "macro expands to itself: @loop -> @loop"},
+		{exp: "[*][*][@ping][*]", err: ErrMacroCycle, message: "macro expands to itself: @ping -> @pong -> @ping"},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		_, err := NewDateTimeExpression(data.exp)
+		assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+		assert.EqualError(t, err, data.message)
+	}
+
+	for _, name := range []string{"", "1st", "@weekend", "prime-time", "晚上"} {
+		err := RegisterMacro(name, "*")
+		assert.True(t, errors.Is(err, ErrMacroFormat), name)
+	}
+}
+
+func TestLookupMacro(t *testing.T) {
+	// 内置的宏
+	for name, expansion := range map[string]string{"daily": "*", "weekdays": "w1-5", "Weekend": "w6-7"} {
+		value, ok := LookupMacro(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expansion, value)
+	}
+
+	if err := RegisterMacro("Lunch", "12:00:00-13:00:00"); err != nil {
+		t.Fatal(err)
+	}
+	value, ok := LookupMacro("lunch")
+	assert.True(t, ok)
+	assert.Equal(t, "12:00:00-13:00:00", value)
+	_, ok = LookupMacro("dinner")
+	assert.False(t, ok)
+
+	all := Macros()
+	assert.Equal(t, "12:00:00-13:00:00", all["lunch"])
+	assert.Equal(t, "w6-7", all["weekend"])
+	// 返回的是副本
+	all["lunch"] = "*"
+	value, _ = LookupMacro("lunch")
+	assert.Equal(t, "12:00:00-13:00:00", value)
+}
@@ -2,6 +2,8 @@ package timeexpression
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,17 +16,48 @@ func parseMonthInt(monthStr string) (int, error) {
 	return int(month.Month()), nil
 }
 
+// monthKind 月字段的类型, 决定start和end的含义以及年月日按哪种历法计算
+type monthKind int
+
+const (
+	monthKindMonth         monthKind = iota // *, mm, mm-mm: 公历月份
+	monthKindQuarter                        // Qn, Qn-n: 季度, start和end为季度包含的月份, Q2为04-06
+	monthKindLunar                          // L*, Lmm, Lmm-mm: 农历月份, 此时年和日也按农历计算
+	monthKindLunarLeap                      // Lmmbis: 只有农历闰mm月
+	monthKindWeekOfYear                     // Wnn, Wnn-nn: ISO 8601的周, start和end为第几周, 此时年为周所属的年(week-year)
+	monthKindFiscal                         // F*, Fmm, Fmm-mm: 财年的期, start和end为财年中的第几期, 此时年也按财年计算
+	monthKindFiscalQuarter                  // FQn, FQn-n: 财年的季度, start和end为季度包含的期
+)
+
 type monthExpression struct {
 	start int
 	end   int
 	isAll bool
+	kind  monthKind
 }
 
-// newMonthExpression 创建月的时间表达式,支持格式为 [*,mm,mm-mm]
+// newMonthExpression 创建月的时间表达式,支持格式为 [*,mm,mm-mm,L*,Lmm,Lmm-mm,Lmmbis,Wnn,Wnn-nn,Qn,Qn-n,F*,Fmm,Fmm-mm,FQn,FQn-n]
+// mm也可以是月份的名称, etc: Jan-Mar, january, 一月-三月
 func newMonthExpression(expression string) (*monthExpression, error) {
 	monthExpression := &monthExpression{}
 
 	expression = strings.Trim(expression, " ")
+	if months, ok := replaceMonthNames(expression); ok {
+		// 名称先替换为数字, Feb等名称和前缀冲突
+		expression = months
+	}
+	if strings.HasPrefix(expression, "L") {
+		return newLunarMonthExpression(strings.TrimPrefix(expression, "L"))
+	}
+	if strings.HasPrefix(expression, "W") {
+		return newWeekOfYearExpression(strings.TrimPrefix(expression, "W"))
+	}
+	if strings.HasPrefix(expression, "Q") {
+		return newQuarterExpression(strings.TrimPrefix(expression, "Q"))
+	}
+	if strings.HasPrefix(expression, "F") {
+		return newFiscalMonthExpression(strings.TrimPrefix(expression, "F"))
+	}
 	if expression == "*" {
 		// *的情况
 		monthExpression.start = 1
@@ -60,6 +93,111 @@ func newMonthExpression(expression string) (*monthExpression, error) {
 	return monthExpression, nil
 }
 
+// newLunarMonthExpression 创建农历月份的时间表达式, 支持格式为 *,mm,mm-mm,mmbis(闰mm月)
+func newLunarMonthExpression(expression string) (*monthExpression, error) {
+	if _, ok := replaceMonthNames(strings.TrimSuffix(expression, "bis")); ok {
+		// 公历月份的名称不能用于农历
+		return nil, ErrMonthFormat
+	}
+	isLeap := strings.HasSuffix(expression, "bis")
+	if isLeap {
+		expression = strings.TrimSuffix(expression, "bis")
+		if strings.Contains(expression, "-") || expression == "*" {
+			return nil, ErrMonthFormat
+		}
+	}
+
+	monthExpression, err := newMonthExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if monthExpression.kind != monthKindMonth {
+		return nil, ErrMonthFormat
+	}
+	monthExpression.kind = monthKindLunar
+	if isLeap {
+		monthExpression.kind = monthKindLunarLeap
+	}
+	return monthExpression, nil
+}
+
+// newWeekOfYearExpression 创建ISO 8601周的时间表达式, 支持格式为 nn,nn-nn, 周为1-53
+// 每周从周一开始, 包含1月4日的周为第1周, 所以第1周可能从上一年的12月开始, 最后一周可能在下一年的1月结束
+func newWeekOfYearExpression(expression string) (*monthExpression, error) {
+	monthExpression := &monthExpression{kind: monthKindWeekOfYear}
+
+	splitWeekStr := strings.Split(expression, "-")
+	if len(splitWeekStr) > 2 {
+		return nil, ErrMonthFormat
+	}
+	weeks := make([]int, 0, len(splitWeekStr))
+	for _, weekStr := range splitWeekStr {
+		week, err := strconv.Atoi(weekStr)
+		if err != nil || week < 1 || week > 53 {
+			return nil, ErrMonthFormat
+		}
+		weeks = append(weeks, week)
+	}
+	monthExpression.start = weeks[0]
+	monthExpression.end = weeks[len(weeks)-1]
+
+	err := monthExpression.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return monthExpression, nil
+}
+
+// newQuarterExpression 创建季度的时间表达式, 支持格式为 n,n-n, 季度为1-4, 转换为季度包含的月份
+func newQuarterExpression(expression string) (*monthExpression, error) {
+	monthExpression := &monthExpression{kind: monthKindQuarter}
+
+	splitQuarterStr := strings.Split(expression, "-")
+	if len(splitQuarterStr) > 2 {
+		return nil, ErrMonthFormat
+	}
+	quarters := make([]int, 0, len(splitQuarterStr))
+	for _, quarterStr := range splitQuarterStr {
+		quarter, err := strconv.Atoi(quarterStr)
+		if err != nil || len(quarterStr) != 1 || quarter < 1 || quarter > 4 {
+			return nil, ErrMonthFormat
+		}
+		quarters = append(quarters, quarter)
+	}
+	monthExpression.start = quarters[0]*3 - 2
+	monthExpression.end = quarters[len(quarters)-1] * 3
+
+	err := monthExpression.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return monthExpression, nil
+}
+
+// newFiscalMonthExpression 创建财年中期的时间表达式, 支持格式为 *,mm,mm-mm,Qn,Qn-n, 期为1-12
+// 每期的划分由WithFiscalCalendar设置的财年决定
+func newFiscalMonthExpression(expression string) (*monthExpression, error) {
+	if _, ok := replaceMonthNames(expression); ok {
+		// 财年的期和公历月份无关
+		return nil, ErrMonthFormat
+	}
+	monthExpression, err := newMonthExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	switch monthExpression.kind {
+	case monthKindMonth:
+		monthExpression.kind = monthKindFiscal
+	case monthKindQuarter:
+		monthExpression.kind = monthKindFiscalQuarter
+	default:
+		return nil, ErrMonthFormat
+	}
+	return monthExpression, nil
+}
+
 func (expression *monthExpression) check() error {
 	if expression.start > expression.end {
 		return errors.New("month error: start after end")
@@ -77,6 +215,18 @@ func (expression *monthExpression) isIn(month int) bool {
 	return false
 }
 
+// isInLunar 农历月份是否在周期内
+// 闰月只在Lmmbis, L*, 或者范围包含前后两个月时命中, 例如L05-07包含闰五月和闰六月, L06不包含闰六月
+func (expression *monthExpression) isInLunar(month int, leap bool) bool {
+	switch {
+	case expression.kind == monthKindLunarLeap:
+		return leap && month == expression.start
+	case !leap || expression.isAll:
+		return expression.isIn(month)
+	}
+	return expression.start <= month && month < expression.end
+}
+
 // getStart 获取开始月
 func (expression *monthExpression) getStart(month int) (start int, addYear bool, err error) {
 
@@ -117,3 +267,44 @@ func (expression *monthExpression) getEnd(month int) (end int, addYear bool, err
 
 	return 0, false, errors.New("monthExpression getEnd get unreachable error")
 }
+
+// String 格式化为*,mm,mm-mm,L*,Lmm,Lmm-mm,Lmmbis,Wnn,Wnn-nn,Qn,Qn-n,F*,Fmm,Fmm-mm,FQn,FQn-n
+func (expression *monthExpression) String() string {
+	return expression.format(NameNumeric)
+}
+
+// format 格式化, 公历月份按style使用数字或者名称
+func (expression *monthExpression) format(style NameStyle) string {
+	prefix := ""
+	switch expression.kind {
+	case monthKindQuarter, monthKindFiscalQuarter:
+		if expression.kind == monthKindFiscalQuarter {
+			prefix = "F"
+		}
+		if expression.start+2 == expression.end {
+			return fmt.Sprintf("%sQ%d", prefix, expression.end/3)
+		}
+		return fmt.Sprintf("%sQ%d-%d", prefix, (expression.start+2)/3, expression.end/3)
+	case monthKindLunarLeap:
+		return fmt.Sprintf("L%02dbis", expression.start)
+	case monthKindLunar:
+		prefix = "L"
+	case monthKindWeekOfYear:
+		prefix = "W"
+	case monthKindFiscal:
+		prefix = "F"
+	}
+	if expression.isAll {
+		return prefix + "*"
+	}
+	if prefix == "" {
+		if expression.start == expression.end {
+			return formatMonth(expression.start, style)
+		}
+		return formatMonth(expression.start, style) + "-" + formatMonth(expression.end, style)
+	}
+	if expression.start == expression.end {
+		return fmt.Sprintf("%s%02d", prefix, expression.start)
+	}
+	return fmt.Sprintf("%s%02d-%02d", prefix, expression.start, expression.end)
+}
@@ -2,6 +2,7 @@ package timeexpression
 
 import (
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -275,3 +276,102 @@ func TestMonthExpression_GetEnd(t *testing.T) {
 		assert.Equal(t, data.resultAddYear, addYear)
 	}
 }
+
+func TestNewMonthExpression_WeekOfYear(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		start  int
+		end    int
+	}{
+		{exp: "W10", start: 10, end: 10},
+		{exp: "W10-20", start: 10, end: 20},
+		{exp: "W01-53", start: 1, end: 53},
+		{exp: "W00", hasErr: true},
+		{exp: "W54", hasErr: true},
+		{exp: "W20-10", hasErr: true},
+		{exp: "W1-2-3", hasErr: true},
+		{exp: "W*", hasErr: true},
+		{exp: "LW01", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newMonthExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Equal(t, monthKindWeekOfYear, expression.kind)
+			assert.Equal(t, data.start, expression.start)
+			assert.Equal(t, data.end, expression.end)
+			assert.Equal(t, data.exp, expression.String())
+		}
+	}
+}
+
+func TestNewMonthExpression_Quarter(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		start  int
+		end    int
+		kind   monthKind
+	}{
+		{exp: "Q1", start: 1, end: 3, kind: monthKindQuarter},
+		{exp: "Q2", start: 4, end: 6, kind: monthKindQuarter},
+		{exp: "Q2-4", start: 4, end: 12, kind: monthKindQuarter},
+		{exp: "FQ3", start: 7, end: 9, kind: monthKindFiscalQuarter},
+		{exp: "FQ1-2", start: 1, end: 6, kind: monthKindFiscalQuarter},
+		{exp: "Q0", hasErr: true},
+		{exp: "Q5", hasErr: true},
+		{exp: "Q3-2", hasErr: true},
+		{exp: "Q02", hasErr: true},
+		{exp: "Q*", hasErr: true},
+		{exp: "LQ1", hasErr: true},
+		{exp: "FFQ1", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newMonthExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Equal(t, data.kind, expression.kind)
+			assert.Equal(t, data.start, expression.start)
+			assert.Equal(t, data.end, expression.end)
+			assert.Equal(t, data.exp, expression.String())
+		}
+	}
+}
+
+func TestNewMonthExpression_Names(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		hasErr bool
+		start  int
+		end    int
+	}{
+		{exp: "Jan-Mar", start: 1, end: 3},
+		{exp: "jan-MAR", start: 1, end: 3},
+		{exp: "February", start: 2, end: 2},
+		{exp: "feb", start: 2, end: 2},
+		{exp: "September-december", start: 9, end: 12},
+		{exp: "一月-三月", start: 1, end: 3},
+		{exp: "十二月", start: 12, end: 12},
+		{exp: "Mar-Jan", hasErr: true},
+		{exp: "Jan-03", hasErr: true},
+		{exp: "Janu", hasErr: true},
+		{exp: "十三月", hasErr: true},
+		{exp: "LJan", hasErr: true},
+		{exp: "FJan", hasErr: true},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expression, err := newMonthExpression(data.exp)
+		assert.Equal(t, data.hasErr, err != nil)
+		if err == nil {
+			assert.Equal(t, data.start, expression.start)
+			assert.Equal(t, data.end, expression.end)
+		}
+	}
+}
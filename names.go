@@ -0,0 +1,113 @@
+package timeexpression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NameStyle 格式化表达式时月份和星期几的写法
+type NameStyle int
+
+const (
+	// NameNumeric 数字, etc: 01-03, w1-5
+	NameNumeric NameStyle = iota
+	// NameEnglish 英文缩写, etc: Jan-Mar, Mon-Fri
+	NameEnglish
+	// NameChinese 中文, etc: 一月-三月, 周一-周五
+	NameChinese
+)
+
+// chineseMonthNames 月份的中文名, 下标为月份
+var chineseMonthNames = []string{"", "一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"}
+
+// parseMonthName 解析月份的名称, 支持英文缩写和全称(不区分大小写), 以及中文的一月到十二月
+func parseMonthName(name string) (int, bool) {
+	lower := strings.ToLower(name)
+	if len(lower) >= 3 {
+		if month, ok := englishMonths[lower[:3]]; ok &&
+			(len(lower) == 3 || lower == strings.ToLower(time.Month(month).String())) {
+			return month, true
+		}
+	}
+	for month := 1; month < len(chineseMonthNames); month++ {
+		if name == chineseMonthNames[month] {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// parseWeekdayName 解析星期几的名称, 支持英文缩写和全称(不区分大小写), 以及中文的周一, 星期一, 1为周一, 7为周日
+func parseWeekdayName(name string) (int, bool) {
+	lower := strings.ToLower(name)
+	if len(lower) >= 3 {
+		if weekday, ok := englishWeekdays[lower[:3]]; ok &&
+			(len(lower) == 3 || lower == strings.ToLower(time.Weekday(weekday%7).String())) {
+			return weekday, true
+		}
+	}
+	for _, prefix := range []string{"周", "星期"} {
+		if weekday, ok := chineseWeekdays[strings.TrimPrefix(name, prefix)]; ok && strings.HasPrefix(name, prefix) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// replaceMonthNames 把月份的名称替换为数字, 支持格式为 name,name-name, 不全是名称时ok返回false
+func replaceMonthNames(expression string) (string, bool) {
+	splitMonthStr := strings.Split(expression, "-")
+	months := make([]string, 0, len(splitMonthStr))
+	for _, monthStr := range splitMonthStr {
+		month, ok := parseMonthName(monthStr)
+		if !ok {
+			return "", false
+		}
+		months = append(months, fmt.Sprintf("%02d", month))
+	}
+	return strings.Join(months, "-"), true
+}
+
+// replaceWeekdayNames 把星期几的名称替换为wi的格式, 支持格式为 name,name-name,name#n,nameL, 不全是名称时ok返回false
+func replaceWeekdayNames(expression string) (string, bool) {
+	suffix := ""
+	if idx := strings.Index(expression, "#"); idx >= 0 {
+		expression, suffix = expression[:idx], expression[idx:]
+	} else if _, ok := parseWeekdayName(expression); !ok && strings.HasSuffix(expression, "L") {
+		expression, suffix = strings.TrimSuffix(expression, "L"), "L"
+	}
+
+	splitWeekdayStr := strings.Split(expression, "-")
+	weekdays := make([]string, 0, len(splitWeekdayStr))
+	for _, weekdayStr := range splitWeekdayStr {
+		weekday, ok := parseWeekdayName(weekdayStr)
+		if !ok {
+			return "", false
+		}
+		weekdays = append(weekdays, fmt.Sprint(weekday))
+	}
+	return "w" + strings.Join(weekdays, "-") + suffix, true
+}
+
+// formatMonth 月份在style下的写法
+func formatMonth(month int, style NameStyle) string {
+	switch style {
+	case NameEnglish:
+		return englishMonth(time.Month(month))
+	case NameChinese:
+		return chineseMonthNames[month]
+	}
+	return fmt.Sprintf("%02d", month)
+}
+
+// formatWeekday 星期几在style下的写法, 1为周一, 7为周日
+func formatWeekday(weekday int, style NameStyle) string {
+	switch style {
+	case NameEnglish:
+		return englishWeekday(weekday)
+	case NameChinese:
+		return "周" + chineseWeekday(weekday)
+	}
+	return fmt.Sprint(weekday)
+}
@@ -0,0 +1,299 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNaturalLanguageFormat 无法理解的自然语言描述
+	ErrNaturalLanguageFormat = errors.New("natural language format not math")
+	// ErrNaturalLanguageAmbiguous 自然语言描述有歧义
+	ErrNaturalLanguageAmbiguous = errors.New("natural language is ambiguous")
+)
+
+// naturalFields 自然语言解析出的各个字段, 空字符串表示*
+type naturalFields struct {
+	always bool
+	year   string
+	month  string
+	day    string
+	hours  []string
+}
+
+// naturalRule 一条短语规则, 匹配后把结果写入fields
+type naturalRule struct {
+	pattern *regexp.Regexp
+	apply   func(fields *naturalFields, match []string) error
+}
+
+const (
+	englishMonthPattern   = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+	englishWeekdayPattern = `(mon(?:day)?|tue(?:s(?:day)?)?|wed(?:nesday)?|thu(?:r(?:s(?:day)?)?)?|fri(?:day)?|sat(?:urday)?|sun(?:day)?)s?`
+	clockPattern          = `(\d{1,2}:\d{2}(?::\d{2})?)`
+	chineseWeekdayPattern = `[一二三四五六日天]`
+)
+
+var englishMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var englishWeekdays = map[string]int{
+	"mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6, "sun": 7,
+}
+
+var chineseWeekdays = map[string]int{
+	"一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "日": 7, "天": 7,
+}
+
+// naturalRules 按顺序尝试的短语规则, 英文会先转为小写
+var naturalRules = []naturalRule{
+	// 分隔符
+	{regexp.MustCompile(`^(?:\s+|,|，|、|和|及|在|每年|and\b|every year\b)`), nil},
+	// 总是有效
+	{regexp.MustCompile(`^(?:always|始终有效|全天候)`), func(fields *naturalFields, match []string) error {
+		fields.always = true
+		return nil
+	}},
+
+	// 年, 中文的需要先于英文匹配, 否则 2000年 会被英文规则截断
+	{regexp.MustCompile(`^(\d{4})年\s*(?:至|到|-)\s*(\d{4})年`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1] + "-" + match[2])
+	}},
+	{regexp.MustCompile(`^(\d{4})年起`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1] + "-")
+	}},
+	{regexp.MustCompile(`^(?:至|到)(\d{4})年`), func(fields *naturalFields, match []string) error {
+		return fields.setYear("-" + match[1])
+	}},
+	{regexp.MustCompile(`^(\d{4})年`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1])
+	}},
+
+	// 英文
+	{regexp.MustCompile(`^(?:from|in)?\s*(\d{4})\s*(?:-|to)\s*(\d{4})\b`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1] + "-" + match[2])
+	}},
+	{regexp.MustCompile(`^(?:in\s+)?(?:every\s+year\s+)?(?:from|since)\s+(\d{4})\b(?:\s+onwards\b)?`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1] + "-")
+	}},
+	{regexp.MustCompile(`^(?:in\s+)?(?:every\s+year\s+)?(?:until|through)\s+(\d{4})\b`), func(fields *naturalFields, match []string) error {
+		return fields.setYear("-" + match[1])
+	}},
+	{regexp.MustCompile(`^(?:in\s+)?(\d{4})\b`), func(fields *naturalFields, match []string) error {
+		return fields.setYear(match[1])
+	}},
+	{regexp.MustCompile(`^from\s+` + englishMonthPattern + `\s+to\s+` + englishMonthPattern + `\b`), func(fields *naturalFields, match []string) error {
+		return fields.setMonth(englishMonths[match[1][:3]], englishMonths[match[2][:3]])
+	}},
+	{regexp.MustCompile(`^(?:in\s+)?` + englishMonthPattern + `\b`), func(fields *naturalFields, match []string) error {
+		month := englishMonths[match[1][:3]]
+		return fields.setMonth(month, month)
+	}},
+	{regexp.MustCompile(`^(?:every\s*day|daily)\b`), func(fields *naturalFields, match []string) error {
+		return fields.setDay("*")
+	}},
+	{regexp.MustCompile(`^on\s+days?\s+(\d{1,2})(?:\s*(?:-|to)\s*(\d{1,2}))?\b`), func(fields *naturalFields, match []string) error {
+		return fields.setDayRange(match[1], match[2])
+	}},
+	{regexp.MustCompile(`^(?:every|on)\s+weekends?\b`), func(fields *naturalFields, match []string) error {
+		return fields.setWeekday(6, 7)
+	}},
+	{regexp.MustCompile(`^(?:every|on)\s+weekdays?\b`), func(fields *naturalFields, match []string) error {
+		return fields.setWeekday(1, 5)
+	}},
+	{regexp.MustCompile(`^(?:every|on)\s+` + englishWeekdayPattern + `(?:\s*(and|to|-)\s*` + englishWeekdayPattern + `)?\b`), func(fields *naturalFields, match []string) error {
+		start := englishWeekdays[match[1][:3]]
+		if match[2] == "" {
+			return fields.setWeekday(start, start)
+		}
+		end := englishWeekdays[match[3][:3]]
+		if match[2] == "and" && end != start+1 {
+			return fmt.Errorf("%w: only consecutive weekdays are supported, got '%s'", ErrNaturalLanguageFormat, match[0])
+		}
+		return fields.setWeekday(start, end)
+	}},
+	{regexp.MustCompile(`^` + clockPattern + `\s*(?:-|~|to)\s*` + clockPattern), func(fields *naturalFields, match []string) error {
+		return fields.addHour(match[1], match[2])
+	}},
+
+	// 中文
+	{regexp.MustCompile(`^(\d{1,2})月\s*(?:至|到|-)\s*(\d{1,2})月`), func(fields *naturalFields, match []string) error {
+		start, _ := strconv.Atoi(match[1])
+		end, _ := strconv.Atoi(match[2])
+		return fields.setMonth(start, end)
+	}},
+	{regexp.MustCompile(`^(\d{1,2})月`), func(fields *naturalFields, match []string) error {
+		month, _ := strconv.Atoi(match[1])
+		return fields.setMonth(month, month)
+	}},
+	{regexp.MustCompile(`^(?:每月)?(\d{1,2})[日号](?:\s*(?:至|到|-)\s*(\d{1,2})[日号])?`), func(fields *naturalFields, match []string) error {
+		return fields.setDayRange(match[1], match[2])
+	}},
+	{regexp.MustCompile(`^(?:每天|每日)`), func(fields *naturalFields, match []string) error {
+		return fields.setDay("*")
+	}},
+	{regexp.MustCompile(`^每?周末`), func(fields *naturalFields, match []string) error {
+		return fields.setWeekday(6, 7)
+	}},
+	{regexp.MustCompile(`^每?(?:个)?工作日`), func(fields *naturalFields, match []string) error {
+		return fields.setWeekday(1, 5)
+	}},
+	{regexp.MustCompile(`^每?(?:周|星期)(` + chineseWeekdayPattern + `)\s*(?:至|到|-)\s*(?:周|星期)?(` + chineseWeekdayPattern + `)`), func(fields *naturalFields, match []string) error {
+		return fields.setWeekday(chineseWeekdays[match[1]], chineseWeekdays[match[2]])
+	}},
+	{regexp.MustCompile(`^每?(?:周|星期)(` + chineseWeekdayPattern + `+)`), func(fields *naturalFields, match []string) error {
+		// 每周六日 这种连写的形式, 需要是连续的
+		var weekdays []int
+		for _, char := range match[1] {
+			weekdays = append(weekdays, chineseWeekdays[string(char)])
+		}
+		for i := 1; i < len(weekdays); i++ {
+			if weekdays[i] != weekdays[i-1]+1 {
+				return fmt.Errorf("%w: only consecutive weekdays are supported, got '%s'", ErrNaturalLanguageFormat, match[0])
+			}
+		}
+		return fields.setWeekday(weekdays[0], weekdays[len(weekdays)-1])
+	}},
+	{regexp.MustCompile(`^(\d{1,2})[点时](?:(\d{1,2})分)?\s*(?:至|到|-)\s*(\d{1,2})[点时](?:(\d{1,2})分)?`), func(fields *naturalFields, match []string) error {
+		return fields.addHour(chineseClock(match[1], match[2]), chineseClock(match[3], match[4]))
+	}},
+	{regexp.MustCompile(`^` + clockPattern + `\s*(?:至|到)\s*` + clockPattern), func(fields *naturalFields, match []string) error {
+		return fields.addHour(match[1], match[2])
+	}},
+
+	// 没有单位的数字范围, 无法确定是年月日还是时间
+	{regexp.MustCompile(`^\d{1,2}\s*(?:-|~|to|至|到)\s*\d{1,2}`), func(fields *naturalFields, match []string) error {
+		return fmt.Errorf("%w: '%s' can be days, months or hours, add a unit", ErrNaturalLanguageAmbiguous, match[0])
+	}},
+}
+
+// ParseNaturalLanguage 解析受限的英文/中文短语, 转换为时间表达式
+// etc: "every day 20:00-22:00 in March 2024", "每周六日 18点到22点"
+// Describe 生成的句子都可以被解析回来
+func ParseNaturalLanguage(text string) (*DateTimeExpression, error) {
+	fields := &naturalFields{}
+
+	rest := strings.ToLower(strings.TrimSpace(text))
+	for rest != "" {
+		matched := false
+		for _, rule := range naturalRules {
+			match := rule.pattern.FindStringSubmatch(rest)
+			if match == nil || match[0] == "" {
+				continue
+			}
+			if rule.apply != nil {
+				if err := rule.apply(fields, match); err != nil {
+					return nil, err
+				}
+			}
+			rest = rest[len(match[0]):]
+			matched = true
+			break
+		}
+		if !matched {
+			return nil, fmt.Errorf("%w: cannot understand '%s'", ErrNaturalLanguageFormat, rest)
+		}
+	}
+
+	return fields.build()
+}
+
+// chineseClock 把 18点30分 转换为 18:30
+func chineseClock(hour string, minute string) string {
+	if minute == "" {
+		minute = "0"
+	}
+	minuteInt, _ := strconv.Atoi(minute)
+	return fmt.Sprintf("%s:%02d", hour, minuteInt)
+}
+
+func (fields *naturalFields) setYear(year string) error {
+	if fields.year != "" && fields.year != year {
+		return fmt.Errorf("%w: year is given twice", ErrNaturalLanguageAmbiguous)
+	}
+	fields.year = year
+	return nil
+}
+
+func (fields *naturalFields) setMonth(start int, end int) error {
+	month := fmt.Sprintf("%02d", start)
+	if end != start {
+		month += fmt.Sprintf("-%02d", end)
+	}
+	if fields.month != "" && fields.month != month {
+		return fmt.Errorf("%w: month is given twice", ErrNaturalLanguageAmbiguous)
+	}
+	fields.month = month
+	return nil
+}
+
+func (fields *naturalFields) setDay(day string) error {
+	if fields.day != "" && fields.day != day {
+		return fmt.Errorf("%w: day and weekday are given together", ErrNaturalLanguageAmbiguous)
+	}
+	fields.day = day
+	return nil
+}
+
+func (fields *naturalFields) setDayRange(start string, end string) error {
+	startInt, _ := strconv.Atoi(start)
+	day := fmt.Sprintf("%02d", startInt)
+	if end != "" {
+		endInt, _ := strconv.Atoi(end)
+		day += fmt.Sprintf("-%02d", endInt)
+	}
+	return fields.setDay(day)
+}
+
+func (fields *naturalFields) setWeekday(start int, end int) error {
+	day := fmt.Sprintf("w%d", start)
+	if end != start {
+		day += fmt.Sprintf("-%d", end)
+	}
+	return fields.setDay(day)
+}
+
+func (fields *naturalFields) addHour(start string, end string) error {
+	fields.hours = append(fields.hours, fullClock(start)+"-"+fullClock(end))
+	return nil
+}
+
+// fullClock 把 8:00 补全为 8:00:00
+func fullClock(clock string) string {
+	if strings.Count(clock, ":") == 1 {
+		return clock + ":00"
+	}
+	return clock
+}
+
+// build 生成时间表达式
+func (fields *naturalFields) build() (*DateTimeExpression, error) {
+	if fields.always {
+		if fields.year != "" || fields.month != "" || fields.day != "" || len(fields.hours) > 0 {
+			return nil, fmt.Errorf("%w: 'always' is given with other conditions", ErrNaturalLanguageAmbiguous)
+		}
+		return NewDateTimeExpression("[*][*][*][*]")
+	}
+	if fields.year == "" && fields.month == "" && fields.day == "" && len(fields.hours) == 0 {
+		return nil, fmt.Errorf("%w: no condition is given", ErrNaturalLanguageFormat)
+	}
+
+	orAll := func(field string) string {
+		if field == "" {
+			return "*"
+		}
+		return field
+	}
+	hour := "*"
+	if len(fields.hours) > 0 {
+		hour = strings.Join(fields.hours, ",")
+	}
+
+	return NewDateTimeExpression("[" + orAll(fields.year) + "][" + orAll(fields.month) + "][" +
+		orAll(fields.day) + "][" + hour + "]")
+}
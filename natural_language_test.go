@@ -0,0 +1,124 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseNaturalLanguage(t *testing.T) {
+	testDatas := []struct {
+		text   string
+		err    error
+		result string
+	}{
+		{
+			text:   "every day 20:00-22:00 in March 2024",
+			result: "[2024][03][*][20:00:00-22:00:00]",
+		},
+		{
+			text:   "每周六日 18点到22点",
+			result: "[*][*][w6-7][18:00:00-22:00:00]",
+		},
+		{
+			text:   "Every Monday to Friday, 9:00 to 12:00 and 13:30-18:00",
+			result: "[*][*][w1-5][09:00:00-12:00:00,13:30:00-18:00:00]",
+		},
+		{
+			text:   "on weekends from June to August",
+			result: "[*][06-08][w6-7][*]",
+		},
+		{
+			text:   "2024年每月1日至7日 20点30分到22点",
+			result: "[2024][*][01-07][20:30:00-22:00:00]",
+		},
+		{
+			text:   "每个工作日 09:00到18:00",
+			result: "[*][*][w1-5][09:00:00-18:00:00]",
+		},
+		{
+			text:   "always",
+			result: "[*][*][*][*]",
+		},
+		{
+			text:   "on weekends from 2024 onwards",
+			result: "[2024-][*][w6-7][*]",
+		},
+		{
+			text:   "至2030年每年3月",
+			result: "[-2030][03][*][*]",
+		},
+		{
+			text: "every day 20-22",
+			err:  ErrNaturalLanguageAmbiguous,
+		},
+		{
+			text: "in March, in April",
+			err:  ErrNaturalLanguageAmbiguous,
+		},
+		{
+			text: "on day 5 every Saturday",
+			err:  ErrNaturalLanguageAmbiguous,
+		},
+		{
+			text: "每周一三",
+			err:  ErrNaturalLanguageFormat,
+		},
+		{
+			text: "every full moon",
+			err:  ErrNaturalLanguageFormat,
+		},
+		{
+			text: "",
+			err:  ErrNaturalLanguageFormat,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] text:%s\n", i, data.text)
+		expr, err := ParseNaturalLanguage(data.text)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), "%v", err)
+			continue
+		}
+		if assert.Nil(t, err) {
+			assert.Equal(t, data.result, expr.String())
+		}
+	}
+}
+
+func TestParseNaturalLanguage_RoundTrip(t *testing.T) {
+	expList := []string{
+		"[*][*][*][*]",
+		"[2000][02-04][05-07][08:00:00-10:00:00,11:00:00-12:30:30]",
+		"[2024][03][*][20:00:00-22:00:00]",
+		"[2020-2022][*][*][*]",
+		"[2020-2022][06-08][01][*]",
+		"[*][12][*][*]",
+		"[*][*][*][01:00:00-02:00:00,03:00:00-04:00:00,05:00:00-24:00:00]",
+		"[*][*][w6-7][18:00:00-22:00:00]",
+		"[*][*][w3][*]",
+		"[2024][*][w1-5][09:00:00-18:00:00]",
+		"[2024-][*][*][*]",
+		"[-2030][03][*][20:00:00-22:00:00]",
+		"[2024-][*][w6-7][*]",
+	}
+
+	for i, exp := range expList {
+		fmt.Printf("[%d] exp:%s\n", i, exp)
+		expr, err := NewDateTimeExpression(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, lang := range []string{"en", "zh"} {
+			text, err := expr.Describe(lang)
+			assert.Nil(t, err)
+			parsed, err := ParseNaturalLanguage(text)
+			if assert.Nil(t, err, text) {
+				assert.Equal(t, exp, parsed.String(), text)
+			}
+		}
+	}
+}
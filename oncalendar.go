@@ -0,0 +1,389 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrOnCalendarFormat systemd OnCalendar格式不对
+	ErrOnCalendarFormat = errors.New("oncalendar format not math")
+	// ErrOnCalendarUnsupported OnCalendar中有无法转换为时间表达式的部分
+	ErrOnCalendarUnsupported = errors.New("oncalendar feature is unsupported")
+	// ErrOnCalendarLossy 时间表达式转换为OnCalendar会丢失信息
+	ErrOnCalendarLossy = errors.New("conversion to oncalendar is lossy")
+)
+
+// onCalendarShorthands systemd.time中的简写
+var onCalendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// onCalendarWeekdays OnCalendar中星期的写法, 下标为星期几(1为周一, 7为周日)
+var onCalendarWeekdays = []string{"", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// onCalendarWeekdayNames 星期的全称
+var onCalendarWeekdayNames = []string{"", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// ParseOnCalendar 把systemd timer的OnCalendar转换为时间表达式, 格式为 [星期] [年-]月-日 [时:分[:秒]]
+// 和ParseCron一样, 每次触发转换为1秒的周期
+// 星期和日同时配置时(systemd中为"且"的关系)只支持每月第n个星期几, 例如 Fri *-*-15..21, Mon *-05~07/1
+// 时区, 小数秒以及不连续的年, 月, 日, 星期是不支持的
+func ParseOnCalendar(spec string) (*DateTimeExpression, error) {
+	spec = strings.TrimSpace(spec)
+	if shorthand, ok := onCalendarShorthands[strings.ToLower(spec)]; ok {
+		spec = shorthand
+	}
+
+	tokens := strings.Fields(spec)
+	if len(tokens) > 1 && !strings.ContainsAny(tokens[len(tokens)-1], "0123456789*:~") &&
+		!isOnCalendarWeekdayToken(tokens[len(tokens)-1]) {
+		// 最后的时区, etc: UTC, Europe/Berlin
+		return nil, fmt.Errorf("%w: time zone '%s'", ErrOnCalendarUnsupported, tokens[len(tokens)-1])
+	}
+	if len(tokens) == 0 || len(tokens) > 3 {
+		return nil, fmt.Errorf("%w: '%s'", ErrOnCalendarFormat, spec)
+	}
+
+	var weekdayToken, dateToken, timeToken string
+	for i, token := range tokens {
+		switch {
+		case i == 0 && isOnCalendarWeekdayToken(token):
+			weekdayToken = strings.TrimSuffix(token, ",")
+		case strings.Contains(token, ":") && timeToken == "":
+			timeToken = token
+		case (strings.Contains(token, "-") || strings.Contains(token, "~")) && dateToken == "" && timeToken == "":
+			dateToken = token
+		default:
+			return nil, fmt.Errorf("%w: unexpected '%s'", ErrOnCalendarFormat, token)
+		}
+	}
+	if dateToken == "" {
+		dateToken = "*-*-*"
+	}
+	if timeToken == "" {
+		timeToken = "00:00:00"
+	}
+
+	year, month, day, err := parseOnCalendarDate(dateToken)
+	if err != nil {
+		return nil, err
+	}
+	if weekdayToken != "" {
+		day, err = parseOnCalendarWeekday(weekdayToken, day)
+		if err != nil {
+			return nil, err
+		}
+	} else if strings.HasPrefix(day, "~") {
+		return nil, fmt.Errorf("%w: day '%s' without weekday", ErrOnCalendarUnsupported, day)
+	}
+	units, err := parseOnCalendarTime(timeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDateTimeExpression("[" + year + "][" + month + "][" + day + "][" + units + "]")
+}
+
+// isOnCalendarWeekdayToken 是否为星期的部分, etc: Mon..Fri, Sat,Sun, Wed,
+func isOnCalendarWeekdayToken(token string) bool {
+	for _, item := range strings.Split(strings.TrimSuffix(token, ","), ",") {
+		for _, name := range strings.Split(strings.Replace(item, "..", "-", 1), "-") {
+			if onCalendarWeekday(name) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// onCalendarWeekday 解析星期的缩写或者全称, 返回1为周一, 7为周日, 不是星期时返回0
+func onCalendarWeekday(name string) int {
+	name = strings.ToLower(name)
+	for weekday := 1; weekday <= 7; weekday++ {
+		if name == strings.ToLower(onCalendarWeekdays[weekday]) || name == onCalendarWeekdayNames[weekday] {
+			return weekday
+		}
+	}
+	return 0
+}
+
+// onCalendarList 把 .. 范围转换为cron的写法后解析
+func onCalendarList(field string, min int, max int) ([]int, error) {
+	values, err := parseCronList(strings.Replace(field, "..", "-", -1), min, max, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOnCalendarFormat, err)
+	}
+	return values, nil
+}
+
+// onCalendarContinuous 解析只能是连续范围的字段, 返回时间表达式中的格式
+func onCalendarContinuous(field string, min int, max int, layout string) (string, error) {
+	if field == "*" {
+		return "*", nil
+	}
+	values, err := onCalendarList(field, min, max)
+	if err != nil {
+		return "", err
+	}
+	if values[len(values)-1]-values[0]+1 != len(values) {
+		return "", fmt.Errorf("%w: '%s' is not a continuous range", ErrOnCalendarUnsupported, field)
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf(layout, values[0]), nil
+	}
+	return fmt.Sprintf(layout+"-"+layout, values[0], values[len(values)-1]), nil
+}
+
+// parseOnCalendarDate 解析日期部分, 支持 [年-]月-日 和 [年-]月~日(从月末倒数)
+func parseOnCalendarDate(token string) (year string, month string, day string, err error) {
+	var parts []string
+	if idx := strings.Index(token, "~"); idx >= 0 {
+		parts = append(strings.Split(token[:idx], "-"), token[idx:])
+	} else {
+		parts = strings.Split(token, "-")
+	}
+	switch len(parts) {
+	case 2:
+		parts = append([]string{"*"}, parts...)
+	case 3:
+	default:
+		return "", "", "", fmt.Errorf("%w: invalid date '%s'", ErrOnCalendarFormat, token)
+	}
+
+	yearField := parts[0]
+	if len(yearField) == 2 {
+		// 两位的年, 70-99为19xx, 其他为20xx
+		short, convErr := strconv.Atoi(yearField)
+		if convErr != nil {
+			return "", "", "", fmt.Errorf("%w: invalid year '%s'", ErrOnCalendarFormat, yearField)
+		}
+		if short >= 70 {
+			yearField = strconv.Itoa(1900 + short)
+		} else {
+			yearField = strconv.Itoa(2000 + short)
+		}
+	}
+	year, err = onCalendarContinuous(yearField, 1970, MaxYear, "%d")
+	if err != nil {
+		return "", "", "", err
+	}
+	// 一直到最大年的为没有结束年, etc: 2024/1
+	if strings.HasSuffix(year, "-"+strconv.Itoa(MaxYear)) {
+		year = strings.TrimSuffix(year, strconv.Itoa(MaxYear))
+	}
+	month, err = onCalendarContinuous(parts[1], 1, 12, "%02d")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	dayField := parts[2]
+	if strings.HasPrefix(dayField, "~") {
+		// ~n 为倒数第n天, ~07/1 为最后7天(和星期一起表示最后一个星期几)
+		if dayField == "~07/1" || dayField == "~7/1" {
+			return year, month, dayField, nil
+		}
+		offset, convErr := strconv.Atoi(dayField[1:])
+		if convErr != nil || offset < 1 || offset > 31 {
+			return "", "", "", fmt.Errorf("%w: invalid day '%s'", ErrOnCalendarUnsupported, dayField)
+		}
+		if offset == 1 {
+			return year, month, "L", nil
+		}
+		return year, month, fmt.Sprintf("L-%d", offset-1), nil
+	}
+	day, err = onCalendarContinuous(dayField, 1, 31, "%02d")
+	if err != nil {
+		return "", "", "", err
+	}
+	return year, month, day, nil
+}
+
+// parseOnCalendarWeekday 解析星期的部分并和日组合, 返回时间表达式中日的格式
+func parseOnCalendarWeekday(token string, day string) (string, error) {
+	var weekdays []int
+	for _, item := range strings.Split(token, ",") {
+		bounds := strings.Split(strings.Replace(item, "..", "-", 1), "-")
+		start := onCalendarWeekday(bounds[0])
+		end := onCalendarWeekday(bounds[len(bounds)-1])
+		if start == 0 || end == 0 || len(bounds) > 2 || start > end {
+			return "", fmt.Errorf("%w: invalid weekday '%s'", ErrOnCalendarFormat, item)
+		}
+		weekdays = append(weekdays, intRange(start, end)...)
+	}
+	weekday, err := formatWeekdayField(weekdays, token, ErrOnCalendarUnsupported)
+	if err != nil {
+		return "", err
+	}
+	if day == "*" {
+		return weekday, nil
+	}
+
+	// 星期和日为"且"的关系, 只支持连续7天中的某个星期几
+	if weekday == "*" {
+		return day, nil
+	}
+	if strings.Contains(weekday, "-") {
+		return "", fmt.Errorf("%w: weekday range '%s' with day '%s'", ErrOnCalendarUnsupported, token, day)
+	}
+	if strings.HasPrefix(day, "~") {
+		return weekday + "L", nil
+	}
+	for nth := 1; nth <= 4; nth++ {
+		if day == fmt.Sprintf("%02d-%02d", nth*7-6, nth*7) {
+			return fmt.Sprintf("%s#%d", weekday, nth), nil
+		}
+	}
+	return "", fmt.Errorf("%w: weekday '%s' with day '%s'", ErrOnCalendarUnsupported, token, day)
+}
+
+// parseOnCalendarTime 解析时间部分, 时:分[:秒], 返回时间表达式中时分秒的格式
+func parseOnCalendarTime(token string) (string, error) {
+	parts := strings.Split(token, ":")
+	switch len(parts) {
+	case 2:
+		parts = append(parts, "00")
+	case 3:
+	default:
+		return "", fmt.Errorf("%w: invalid time '%s'", ErrOnCalendarFormat, token)
+	}
+	if strings.Contains(parts[2], ".") {
+		return "", fmt.Errorf("%w: fractional seconds '%s'", ErrOnCalendarUnsupported, parts[2])
+	}
+
+	hours, err := onCalendarList(parts[0], 0, 23)
+	if err != nil {
+		return "", err
+	}
+	minutes, err := onCalendarList(parts[1], 0, 59)
+	if err != nil {
+		return "", err
+	}
+	seconds, err := onCalendarList(parts[2], 0, 59)
+	if err != nil {
+		return "", err
+	}
+	return cronFiringUnits(seconds, minutes, hours, ErrOnCalendarUnsupported)
+}
+
+// ToOnCalendar 转换为在每个周期开始时触发的systemd OnCalendar
+// 开始时间不同的时间段可能会生成多条, 离指定日期最近的工作日(W)无法表示, 返回ErrOnCalendarLossy
+func (expression *DateTimeExpression) ToOnCalendar() ([]string, error) {
+	plan, err := expression.triggerPlan(ErrOnCalendarLossy)
+	if err != nil {
+		return nil, err
+	}
+
+	yearField := "*"
+	switch year := expression.year; {
+	case year.isAll:
+	case !year.hasEnd:
+		// 没有结束年时每年重复, etc: 2024/1
+		yearField = fmt.Sprintf("%d/1", year.start)
+	case !year.hasStart:
+		// 没有开始年时从1970年开始
+		if year.end < 1970 {
+			return nil, fmt.Errorf("%w: year %s is before 1970", ErrOnCalendarLossy, year)
+		}
+		yearField = formatOnCalendarList(intRange(1970, year.end), 1970, year.end+1, "%d")
+	default:
+		yearField = formatOnCalendarList(intRange(year.start, year.end), year.start, year.end+1, "%d")
+	}
+	monthField := "*"
+	if !plan.month.isAll {
+		monthField = formatOnCalendarList(intRange(plan.month.start, plan.month.end), 1, 12, "%02d")
+	}
+
+	weekdayField := ""
+	dayField := "*"
+	switch day := plan.day; day.kind {
+	case dayKindNthWeekday:
+		weekdayField = onCalendarWeekdays[day.start] + " "
+		if day.nth < 0 {
+			dayField = "~07/1"
+		} else {
+			dayField = fmt.Sprintf("%02d..%02d", day.nth*7-6, day.nth*7)
+		}
+	case dayKindLast:
+		dayField = fmt.Sprintf("~%02d", day.lastOffset+1)
+	case dayKindWeekday:
+		weekdayField = formatOnCalendarWeekdays(day.start, day.end) + " "
+	case dayKindMonthDay:
+		if !day.isAll {
+			dayField = formatOnCalendarList(intRange(day.start, day.end), 1, 31, "%02d")
+		}
+	default:
+		return nil, fmt.Errorf("%w: day %s cannot be represented in oncalendar", ErrOnCalendarLossy, day)
+	}
+	separator := "-"
+	if strings.HasPrefix(dayField, "~") {
+		separator = ""
+	}
+
+	var specs []string
+	for _, group := range groupCronStarts(plan.starts) {
+		specs = append(specs, fmt.Sprintf("%s%s-%s%s%s %s:%s:%02d", weekdayField, yearField, monthField, separator, dayField,
+			formatOnCalendarList(group.hours, 0, 23, "%02d"), formatOnCalendarList(group.minutes, 0, 59, "%02d"), group.sec))
+	}
+	return specs, nil
+}
+
+// formatOnCalendarWeekdays 格式化星期几, etc: Mon..Fri, Sat,Sun
+func formatOnCalendarWeekdays(start int, end int) string {
+	if end-start >= 2 {
+		return onCalendarWeekdays[start] + ".." + onCalendarWeekdays[end]
+	}
+	names := make([]string, 0, 2)
+	for weekday := start; weekday <= end; weekday++ {
+		names = append(names, onCalendarWeekdays[weekday])
+	}
+	return strings.Join(names, ",")
+}
+
+// formatOnCalendarList 把数字列表格式化为 *, 步长 a/n, 或者逗号分隔的数字和 a..b 范围
+func formatOnCalendarList(values []int, min int, max int, layout string) string {
+	sort.Ints(values)
+	if len(values) == max-min+1 {
+		return "*"
+	}
+
+	// 一直到最大值的等差数列, etc: 00/15
+	if len(values) >= 3 {
+		step := values[1] - values[0]
+		progression := step > 1 && values[len(values)-1]+step > max
+		for i := 2; i < len(values) && progression; i++ {
+			progression = values[i]-values[i-1] == step
+		}
+		if progression {
+			return fmt.Sprintf(layout+"/%d", values[0], step)
+		}
+	}
+
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, fmt.Sprintf(layout+".."+layout, values[i], values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, fmt.Sprintf(layout, values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,175 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestParseOnCalendar 示例和规范化的写法来自systemd.time(7)
+func TestParseOnCalendar(t *testing.T) {
+	testDatas := []struct {
+		spec       string
+		normalized string
+		// formatted ToOnCalendar的结果, 为空时和normalized相同
+		formatted string
+		exp       string
+		err       error
+	}{
+		{spec: "Wed, 17:48", normalized: "Wed *-*-* 17:48:00", exp: "[*][*][w3][17:48:00-17:48:01]"},
+		{spec: "*-*-7 0:0:0", normalized: "*-*-07 00:00:00", exp: "[*][*][07][00:00:00-00:00:01]"},
+		{spec: "10-15", normalized: "*-10-15 00:00:00", exp: "[*][10][15][00:00:00-00:00:01]"},
+		{spec: "monday *-12-* 17:00", normalized: "Mon *-12-* 17:00:00", exp: "[*][12][w1][17:00:00-17:00:01]"},
+		{spec: "12,14,13,12:20,10,30", normalized: "*-*-* 12,13,14:10,20,30:00", formatted: "*-*-* 12..14:10,20,30:00"},
+		{spec: "12..14:10,20,30", normalized: "*-*-* 12..14:10,20,30:00"},
+		{spec: "03-05 08:05:40", normalized: "*-03-05 08:05:40", exp: "[*][03][05][08:05:40-08:05:41]"},
+		{spec: "08:05:40", normalized: "*-*-* 08:05:40", exp: "[*][*][*][08:05:40-08:05:41]"},
+		{spec: "05:40", normalized: "*-*-* 05:40:00", exp: "[*][*][*][05:40:00-05:40:01]"},
+		{spec: "Sat,Sun 08:05:40", normalized: "Sat,Sun *-*-* 08:05:40", exp: "[*][*][w6-7][08:05:40-08:05:41]"},
+		{spec: "2003-03-05 05:40", normalized: "2003-03-05 05:40:00", exp: "[2003][03][05][05:40:00-05:40:01]"},
+		{spec: "2003-02..04-05", normalized: "2003-02..04-05 00:00:00", exp: "[2003][02-04][05][00:00:00-00:00:01]"},
+		{spec: "2003-03-05", normalized: "2003-03-05 00:00:00", exp: "[2003][03][05][00:00:00-00:00:01]"},
+		{spec: "03-05", normalized: "*-03-05 00:00:00", exp: "[*][03][05][00:00:00-00:00:01]"},
+		{spec: "hourly", normalized: "*-*-* *:00:00"},
+		{spec: "daily", normalized: "*-*-* 00:00:00", exp: "[*][*][*][00:00:00-00:00:01]"},
+		{spec: "monthly", normalized: "*-*-01 00:00:00", exp: "[*][*][01][00:00:00-00:00:01]"},
+		{spec: "weekly", normalized: "Mon *-*-* 00:00:00", exp: "[*][*][w1][00:00:00-00:00:01]"},
+		{spec: "yearly", normalized: "*-01-01 00:00:00", exp: "[*][01][01][00:00:00-00:00:01]"},
+		{spec: "annually", normalized: "*-01-01 00:00:00", exp: "[*][01][01][00:00:00-00:00:01]"},
+		{spec: "*:2/3", normalized: "*-*-* *:02/3:00"},
+		{spec: "*-02~03", normalized: "*-02~03 00:00:00", exp: "[*][02][L-2][00:00:00-00:00:01]"},
+		{spec: "Mon *-05~07/1", normalized: "Mon *-05~07/1 00:00:00", exp: "[*][05][w1L][00:00:00-00:00:01]"},
+		{spec: "Fri *-*-15..21 10:00", normalized: "Fri *-*-15..21 10:00:00", exp: "[*][*][w5#3][10:00:00-10:00:01]"},
+		{spec: "2024/1-*-* 20:00", normalized: "2024/1-*-* 20:00:00", exp: "[2024-][*][*][20:00:00-20:00:01]"},
+
+		{spec: "Sat,Thu,Mon..Wed,Sat..Sun", err: ErrOnCalendarUnsupported},
+		{spec: "Mon,Sun 12-*-* 2,1:23", err: ErrOnCalendarUnsupported},
+		{spec: "Wed *-1", err: ErrOnCalendarUnsupported},
+		{spec: "Mon,Fri *-*-3,1,2 *:30:45", err: ErrOnCalendarUnsupported},
+		{spec: "05:40:23.4200004", err: ErrOnCalendarUnsupported},
+		{spec: "2003-03-05 05:40 UTC", err: ErrOnCalendarUnsupported},
+		{spec: "daily UTC", err: ErrOnCalendarUnsupported},
+		{spec: "quarterly", err: ErrOnCalendarUnsupported},
+		{spec: "semiannually", err: ErrOnCalendarUnsupported},
+		{spec: "*-*-* *:*:*", err: ErrOnCalendarUnsupported},
+		{spec: "*-*-* 25:00", err: ErrOnCalendarFormat},
+		{spec: "*-*-*-* 10:00", err: ErrOnCalendarFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] spec:%s\n", i, data.spec)
+		expr, err := ParseOnCalendar(data.spec)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		if data.exp != "" {
+			assert.Equal(t, data.exp, expr.String())
+		}
+
+		// 规范化的写法为同一个表达式
+		normalized, err := ParseOnCalendar(data.normalized)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, expr.String(), normalized.String())
+
+		// 格式化后和systemd规范化的写法一致
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.normalized
+		}
+		specs, err := expr.ToOnCalendar()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{formatted}, specs)
+	}
+}
+
+func TestDateTimeExpression_ToOnCalendar(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		err   error
+		specs []string
+	}{
+		{
+			exp:   "[*][*][*][20:00:00-22:00:00]",
+			specs: []string{"*-*-* 20:00:00"},
+		},
+		{
+			exp:   "[2024-2026][02-04][05-07][08:00:00-10:00:00,11:30:00-12:30:30,20:00:00-21:00:00]",
+			specs: []string{"2024..2026-02..04-05..07 08,20:00:00", "2024..2026-02..04-05..07 11:30:00"},
+		},
+		{
+			exp:   "[*][*][w1-5][09:00:00-12:00:00]",
+			specs: []string{"Mon..Fri *-*-* 09:00:00"},
+		},
+		{
+			exp:   "[*][*][w6-7][*]",
+			specs: []string{"Sat *-*-* 00:00:00"},
+		},
+		{
+			exp:   "[*][*][L][23:00:00-24:00:00]",
+			specs: []string{"*-*~01 23:00:00"},
+		},
+		{
+			exp:   "[*][03-04][*][*]",
+			specs: []string{"*-03-01 00:00:00"},
+		},
+		{
+			exp:   "[2024-][*][*][20:00:00-22:00:00]",
+			specs: []string{"2024/1-*-* 20:00:00"},
+		},
+		{
+			exp:   "[-2030][*][*][20:00:00-22:00:00]",
+			specs: []string{"1970..2030-*-* 20:00:00"},
+		},
+		{
+			exp: "[-1960][*][*][20:00:00-22:00:00]",
+			err: ErrOnCalendarLossy,
+		},
+		{
+			exp: "[*][*][15W][09:00:00-10:00:00]",
+			err: ErrOnCalendarLossy,
+		},
+		{
+			exp: "[*][*][*][22:00:00-24:00:00,00:00:00-02:00:00]",
+			err: ErrOnCalendarLossy,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs, err := expr.ToOnCalendar()
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.specs, specs)
+
+		// 每条都能解析回来, 触发时间是周期的开始时间
+		from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+		start, err := expr.GetStartTime(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fired := false
+		for _, spec := range specs {
+			parsed, err := ParseOnCalendar(spec)
+			if !assert.Nil(t, err) {
+				continue
+			}
+			fired = fired || parsed.IsIn(start)
+		}
+		assert.True(t, fired, start.String())
+	}
+}
@@ -0,0 +1,747 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOpeningHoursFormat opening_hours格式不对
+	ErrOpeningHoursFormat = errors.New("opening hours format not math")
+	// ErrOpeningHoursUnsupported opening_hours中有无法表达的部分
+	ErrOpeningHoursUnsupported = errors.New("opening hours feature is unsupported")
+	// ErrOpeningHoursLossy 时间表达式转换为opening_hours会丢失信息
+	ErrOpeningHoursLossy = errors.New("conversion to opening hours is lossy")
+)
+
+// openingWeekdays opening_hours中星期的写法, 下标为星期几(1为周一, 7为周日)
+var openingWeekdays = []string{"", "Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+
+// openingMonths opening_hours中月份的写法, 下标为月份
+var openingMonths = []string{"", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+const secondsPerDay = 24 * 3600
+
+var (
+	// openingAdditionalRegex 时间段后面的逗号, 表示附加的规则, etc: Mo-Fr 08:00-12:00, Sa 10:00-12:00
+	openingAdditionalRegex = regexp.MustCompile(`(\d|off|closed)\s*,\s*([A-Za-z])`)
+	// openingRangeSpaceRegex 范围中的空格, etc: Mo - Fr, 08:00 - 12:00
+	openingRangeSpaceRegex = regexp.MustCompile(`\s*-\s*`)
+	// openingListSpaceRegex 列表中的空格, etc: Sa, Su
+	openingListSpaceRegex = regexp.MustCompile(`\s*,\s*`)
+	// openingCommentRegex 规则中的注释, etc: "by appointment"
+	openingCommentRegex = regexp.MustCompile(`"[^"]*"`)
+	// openingYearRegex 年的选择, etc: 2024, 2024-2026, 2024+
+	openingYearRegex = regexp.MustCompile(`^(\d{4})(?:-(\d{4})|(\+))?$`)
+	// openingEventRegex 按日出日落等事件计算的时间, etc: sunrise-sunset, (sunset-01:00)
+	openingEventRegex = regexp.MustCompile(`sunrise|sunset|dawn|dusk`)
+	// openingTimeRegex 时间, etc: 09:00, 9:30, 26:00
+	openingTimeRegex = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+// openingSpan 一天中的时间段, 以距离0点的秒数表示, 跨天时end大于24小时
+type openingSpan struct {
+	start int
+	end   int
+}
+
+// openingRule opening_hours中的一条规则
+type openingRule struct {
+	// additional 由逗号连接的规则, 不覆盖之前的规则
+	additional bool
+
+	yearStart int   // 0表示不限
+	yearEnd   int   // 0表示没有结束年, etc: 2024+
+	months    []int // 为空表示不限
+	// month, days 指定的日期, etc: Dec 24-26, month为0表示不限
+	month    int
+	days     []int
+	weekdays []int // 1为周一, 7为周日, 为空表示不限
+	nth      []int // 每月第几个星期, -1为最后一个, 为空表示不限
+	holiday  bool  // PH
+
+	off   bool
+	spans []openingSpan
+}
+
+// OpeningHours OpenStreetMap opening_hours 格式的营业时间, 例如: Mo-Fr 09:00-18:00; Sa 10:00-14:00; PH off
+// 按规则的顺序, 后面的规则覆盖前面规则命中的日期
+// 还没有节假日的数据, PH不会命中任何日期
+type OpeningHours struct {
+	rules      []*openingRule
+	alwaysOpen bool
+	// calendar PH使用的节假日, 为nil时PH不命中任何日期
+	calendar *Calendar
+}
+
+var _ Expression = (*OpeningHours)(nil)
+
+// ParseOpeningHours 解析opening_hours
+// 支持年, 月, 日期(Dec 24), 星期(Mo-Fr, Th[3], Th[-1]), PH, 多个时间段, 跨过0点的时间段, off/closed 以及 24/7
+// sunrise等事件时间, 10:00+, ||, SH, week 等返回ErrOpeningHoursUnsupported
+// PH按WithCalendar设置的日历中的节假日计算, 没有设置时PH不命中任何日期
+func ParseOpeningHours(value string, opts ...Option) (*OpeningHours, error) {
+	value = strings.TrimSpace(openingCommentRegex.ReplaceAllString(value, ""))
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty", ErrOpeningHoursFormat)
+	}
+	if strings.Contains(value, "||") {
+		return nil, fmt.Errorf("%w: fallback rule '||'", ErrOpeningHoursUnsupported)
+	}
+
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	openingHours := &OpeningHours{calendar: options.calendar}
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// 附加的规则用 "\x00" 分隔
+		part = openingAdditionalRegex.ReplaceAllString(part, "$1\x00$2")
+		for i, text := range strings.Split(part, "\x00") {
+			rule, err := parseOpeningRule(text)
+			if err != nil {
+				return nil, err
+			}
+			rule.additional = i > 0
+			openingHours.rules = append(openingHours.rules, rule)
+		}
+	}
+	if len(openingHours.rules) == 0 {
+		return nil, fmt.Errorf("%w: no rule", ErrOpeningHoursFormat)
+	}
+	openingHours.alwaysOpen = len(openingHours.rules) == 1 && openingHours.rules[0].isAlwaysOpen()
+
+	return openingHours, nil
+}
+
+// parseOpeningRule 解析一条规则, 格式为 [年] [月 [日]] [星期] [时间段|off]
+func parseOpeningRule(text string) (*openingRule, error) {
+	text = strings.TrimSpace(text)
+	text = openingRangeSpaceRegex.ReplaceAllString(text, "-")
+	text = openingListSpaceRegex.ReplaceAllString(text, ",")
+
+	rule := &openingRule{}
+	if text == "24/7" {
+		rule.spans = []openingSpan{{start: 0, end: secondsPerDay}}
+		return rule, nil
+	}
+
+	tokens := strings.Fields(text)
+	hasTime := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		lower := strings.ToLower(token)
+		var err error
+		switch {
+		case hasTime:
+			return nil, fmt.Errorf("%w: unexpected '%s' after time", ErrOpeningHoursFormat, token)
+		case lower == "off" || lower == "closed":
+			rule.off = true
+			hasTime = true
+		case lower == "open":
+			// open 不带时间时为全天
+			if i+1 == len(tokens) {
+				rule.spans = []openingSpan{{start: 0, end: secondsPerDay}}
+				hasTime = true
+			}
+		case openingEventRegex.MatchString(lower):
+			return nil, fmt.Errorf("%w: event time '%s'", ErrOpeningHoursUnsupported, token)
+		case lower == "unknown":
+			return nil, fmt.Errorf("%w: modifier '%s'", ErrOpeningHoursUnsupported, token)
+		case lower == "week":
+			return nil, fmt.Errorf("%w: week selector", ErrOpeningHoursUnsupported)
+		case openingYearRegex.MatchString(token):
+			err = rule.parseYears(token)
+		case len(token) >= 3 && openingMonth(token[:3]) > 0 && !strings.Contains(token, ":"):
+			if i+1 < len(tokens) && isOpeningDayToken(tokens[i+1]) {
+				err = rule.parseDate(token, tokens[i+1])
+				i++
+			} else {
+				err = rule.parseMonths(token)
+			}
+		case strings.Contains(token, ":"):
+			rule.spans, err = parseOpeningSpans(token)
+			hasTime = true
+		default:
+			err = rule.parseWeekdays(token)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !hasTime && !rule.off && len(rule.spans) == 0 {
+		// 只有选择没有时间时为全天
+		rule.spans = []openingSpan{{start: 0, end: secondsPerDay}}
+	}
+
+	return rule, nil
+}
+
+// openingMonth 月份的缩写, 不是月份时返回0
+func openingMonth(name string) int {
+	for month := 1; month <= 12; month++ {
+		if strings.EqualFold(name, openingMonths[month]) {
+			return month
+		}
+	}
+	return 0
+}
+
+// openingWeekday 星期的缩写, 返回1为周一, 7为周日, 不是星期时返回0
+func openingWeekday(name string) int {
+	for weekday := 1; weekday <= 7; weekday++ {
+		if strings.EqualFold(name, openingWeekdays[weekday]) {
+			return weekday
+		}
+	}
+	return 0
+}
+
+// isOpeningDayToken 是否为月份后面的日, etc: 24, 24-26
+func isOpeningDayToken(token string) bool {
+	for _, item := range strings.Split(token, "-") {
+		if _, err := strconv.Atoi(item); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule *openingRule) parseYears(token string) error {
+	matches := openingYearRegex.FindStringSubmatch(token)
+	rule.yearStart, _ = strconv.Atoi(matches[1])
+	rule.yearEnd = rule.yearStart
+	if matches[2] != "" {
+		rule.yearEnd, _ = strconv.Atoi(matches[2])
+	}
+	if matches[3] != "" {
+		rule.yearEnd = 0
+		return nil
+	}
+	if rule.yearStart > rule.yearEnd {
+		return fmt.Errorf("%w: year range '%s'", ErrOpeningHoursFormat, token)
+	}
+	return nil
+}
+
+// parseMonths 解析月份, etc: Jan, Jan-Mar, Nov-Feb, Jan,Mar
+func (rule *openingRule) parseMonths(token string) error {
+	for _, item := range strings.Split(token, ",") {
+		bounds := strings.Split(item, "-")
+		start := openingMonth(bounds[0])
+		end := openingMonth(bounds[len(bounds)-1])
+		if start == 0 || end == 0 || len(bounds) > 2 {
+			return fmt.Errorf("%w: invalid month '%s'", ErrOpeningHoursFormat, item)
+		}
+		for month := start; ; month = month%12 + 1 {
+			rule.months = append(rule.months, month)
+			if month == end {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseDate 解析指定的日期, etc: Dec 24, Dec 24-26
+func (rule *openingRule) parseDate(monthToken string, dayToken string) error {
+	rule.month = openingMonth(monthToken)
+	if rule.month == 0 || len(monthToken) != 3 {
+		return fmt.Errorf("%w: date '%s %s'", ErrOpeningHoursUnsupported, monthToken, dayToken)
+	}
+	bounds := strings.Split(dayToken, "-")
+	start, _ := strconv.Atoi(bounds[0])
+	end, _ := strconv.Atoi(bounds[len(bounds)-1])
+	if len(bounds) > 2 || start < 1 || end > 31 || start > end {
+		return fmt.Errorf("%w: invalid day '%s'", ErrOpeningHoursFormat, dayToken)
+	}
+	rule.days = intRange(start, end)
+	return nil
+}
+
+// parseWeekdays 解析星期, etc: Mo-Fr, Sa,Su, Su-Tu, Th[3], Th[1,3], Th[-1], PH
+// 第几个星期需要对规则中所有的星期都相同, etc: Mo,Th[1] 返回ErrOpeningHoursUnsupported
+func (rule *openingRule) parseWeekdays(token string) error {
+	nths := map[int][]int{}
+	for _, item := range splitOpeningList(token) {
+		switch strings.ToUpper(item) {
+		case "PH":
+			rule.holiday = true
+			continue
+		case "SH":
+			return fmt.Errorf("%w: school holiday", ErrOpeningHoursUnsupported)
+		}
+
+		var nth []int
+		if idx := strings.Index(item, "["); idx >= 0 {
+			if !strings.HasSuffix(item, "]") {
+				return fmt.Errorf("%w: invalid weekday '%s'", ErrOpeningHoursFormat, item)
+			}
+			var err error
+			if nth, err = parseOpeningNth(item[idx+1 : len(item)-1]); err != nil {
+				return err
+			}
+			item = item[:idx]
+		}
+
+		bounds := strings.Split(item, "-")
+		start := openingWeekday(bounds[0])
+		end := openingWeekday(bounds[len(bounds)-1])
+		if start == 0 || end == 0 || len(bounds) > 2 {
+			return fmt.Errorf("%w: invalid selector '%s'", ErrOpeningHoursFormat, item)
+		}
+		// 允许跨过周日, etc: Su-Tu
+		for weekday := start; ; weekday = weekday%7 + 1 {
+			if _, ok := nths[weekday]; !ok {
+				rule.weekdays = append(rule.weekdays, weekday)
+			}
+			nths[weekday] = append(nths[weekday], nth...)
+			if weekday == end {
+				break
+			}
+		}
+	}
+
+	for i, weekday := range rule.weekdays {
+		if i == 0 {
+			rule.nth = nths[weekday]
+			continue
+		}
+		if fmt.Sprint(nths[weekday]) != fmt.Sprint(rule.nth) {
+			return fmt.Errorf("%w: different nth weekdays in '%s'", ErrOpeningHoursUnsupported, token)
+		}
+	}
+	return nil
+}
+
+// splitOpeningList 按逗号分隔, 忽略[]中的逗号
+func splitOpeningList(token string) []string {
+	var items []string
+	depth, last := 0, 0
+	for i, c := range token {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, token[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(items, token[last:])
+}
+
+// parseOpeningNth 解析每月第几个星期, etc: 3, 1,3, 1-2, -1
+func parseOpeningNth(text string) ([]int, error) {
+	var nth []int
+	for _, item := range strings.Split(text, ",") {
+		if value, err := strconv.Atoi(item); err == nil {
+			if value == 0 || value < -1 || value > 5 {
+				return nil, fmt.Errorf("%w: nth weekday '%s'", ErrOpeningHoursUnsupported, item)
+			}
+			nth = append(nth, value)
+			continue
+		}
+		bounds := strings.Split(item, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: invalid nth weekday '%s'", ErrOpeningHoursFormat, item)
+		}
+		start, startErr := strconv.Atoi(bounds[0])
+		end, endErr := strconv.Atoi(bounds[1])
+		if startErr != nil || endErr != nil || start < 1 || end > 5 || start > end {
+			return nil, fmt.Errorf("%w: invalid nth weekday '%s'", ErrOpeningHoursFormat, item)
+		}
+		nth = append(nth, intRange(start, end)...)
+	}
+	return nth, nil
+}
+
+// parseOpeningSpans 解析时间段, etc: 09:00-12:00,13:00-18:00, 22:00-02:00
+func parseOpeningSpans(token string) ([]openingSpan, error) {
+	var spans []openingSpan
+	for _, item := range strings.Split(token, ",") {
+		if strings.HasSuffix(item, "+") {
+			return nil, fmt.Errorf("%w: open end '%s'", ErrOpeningHoursUnsupported, item)
+		}
+		bounds := strings.Split(item, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: invalid time range '%s'", ErrOpeningHoursFormat, item)
+		}
+		start, err := parseOpeningTime(bounds[0], 24)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseOpeningTime(bounds[1], 48)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			// 跨过0点, etc: 22:00-02:00
+			end += secondsPerDay
+		}
+		if start >= secondsPerDay || end > 2*secondsPerDay {
+			return nil, fmt.Errorf("%w: time range '%s' out of range", ErrOpeningHoursFormat, item)
+		}
+		spans = append(spans, openingSpan{start: start, end: end})
+	}
+	return spans, nil
+}
+
+// parseOpeningTime 解析hh:mm, 返回距离0点的秒数
+func parseOpeningTime(value string, maxHour int) (int, error) {
+	matches := openingTimeRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("%w: invalid time '%s'", ErrOpeningHoursFormat, value)
+	}
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	if minute > 59 || hour > maxHour || (hour == maxHour && minute > 0) {
+		return 0, fmt.Errorf("%w: invalid time '%s'", ErrOpeningHoursFormat, value)
+	}
+	return hour*3600 + minute*60, nil
+}
+
+// isAlwaysOpen 是否为没有任何选择的全天规则, 即24/7
+func (rule *openingRule) isAlwaysOpen() bool {
+	return !rule.off && rule.yearStart == 0 && len(rule.months) == 0 && rule.month == 0 &&
+		len(rule.weekdays) == 0 && !rule.holiday &&
+		len(rule.spans) == 1 && rule.spans[0].start == 0 && rule.spans[0].end == secondsPerDay
+}
+
+// matchDay 规则是否命中某天, 同时配置星期和PH时命中其中一个即可
+func (rule *openingRule) matchDay(day time.Time, calendar *Calendar) bool {
+	if rule.yearStart > 0 && (day.Year() < rule.yearStart || rule.yearEnd > 0 && day.Year() > rule.yearEnd) {
+		return false
+	}
+	if len(rule.months) > 0 && !containsInt(rule.months, int(day.Month())) {
+		return false
+	}
+	if rule.month > 0 && (int(day.Month()) != rule.month || !containsInt(rule.days, day.Day())) {
+		return false
+	}
+	if rule.holiday && calendar != nil && calendar.IsHoliday(day) {
+		return true
+	}
+	if len(rule.weekdays) == 0 {
+		// 只有PH时, 不是节假日的日期不命中
+		return !rule.holiday
+	}
+	if !containsInt(rule.weekdays, isoWeekday(day)) {
+		return false
+	}
+	if len(rule.nth) > 0 {
+		nth := (day.Day()-1)/7 + 1
+		last := day.Day()+7 > daysIn(day.Month(), day.Year())
+		if !containsInt(rule.nth, nth) && !(last && containsInt(rule.nth, -1)) {
+			return false
+		}
+	}
+	return true
+}
+
+// daySpans 按规则顺序计算某天的时间段, 可能跨到第二天
+func (openingHours *OpeningHours) daySpans(day time.Time) []openingSpan {
+	var spans []openingSpan
+	for _, rule := range openingHours.rules {
+		if !rule.matchDay(day, openingHours.calendar) {
+			continue
+		}
+		switch {
+		case rule.off:
+			spans = nil
+		case rule.additional:
+			spans = append(spans, rule.spans...)
+		default:
+			spans = append([]openingSpan(nil), rule.spans...)
+		}
+	}
+	return spans
+}
+
+// dayUnits 实现daySchedule, 当天的时间段加上前一天跨过0点的部分
+func (openingHours *OpeningHours) dayUnits(day time.Time) []*hourUnitExpression {
+	var spans []openingSpan
+	for _, span := range openingHours.daySpans(day) {
+		if span.end > secondsPerDay {
+			span.end = secondsPerDay
+		}
+		spans = append(spans, span)
+	}
+	for _, span := range openingHours.daySpans(day.AddDate(0, 0, -1)) {
+		if span.end > secondsPerDay {
+			spans = append(spans, openingSpan{start: 0, end: span.end - secondsPerDay})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	var units []*hourUnitExpression
+	for _, span := range spans {
+		last := len(units) - 1
+		if last >= 0 && span.start <= units[last].end.toSec() {
+			if span.end > units[last].end.toSec() {
+				units[last].end = secToHourUnit(span.end)
+			}
+			continue
+		}
+		units = append(units, &hourUnitExpression{start: secToHourUnit(span.start), end: secToHourUnit(span.end)})
+	}
+	return units
+}
+
+// joinsDays 实现daySchedule, 跨过0点的营业时间按天拆分, 相邻两天首尾相接的时间段是同一个周期
+func (openingHours *OpeningHours) joinsDays() bool {
+	return true
+}
+
+// dayRange 实现daySchedule, 所有营业的规则都有年的限制时才有范围, 有规则没有结束年时没有last
+func (openingHours *OpeningHours) dayRange() (first time.Time, last time.Time) {
+	yearStart, yearEnd, noEnd := 0, 0, false
+	for _, rule := range openingHours.rules {
+		if rule.off {
+			continue
+		}
+		if rule.yearStart == 0 {
+			return time.Time{}, time.Time{}
+		}
+		if yearStart == 0 || rule.yearStart < yearStart {
+			yearStart = rule.yearStart
+		}
+		if rule.yearEnd == 0 {
+			noEnd = true
+		}
+		if rule.yearEnd > yearEnd {
+			yearEnd = rule.yearEnd
+		}
+	}
+	if yearStart == 0 {
+		return time.Time{}, time.Time{}
+	}
+	first = time.Date(yearStart, time.January, 1, 0, 0, 0, 0, time.Local)
+	if noEnd {
+		return first, time.Time{}
+	}
+	// 跨过0点的时间段会延续到下一年的第一天
+	return first, time.Date(yearEnd+1, time.January, 1, 0, 0, 0, 0, time.Local)
+}
+
+// secToHourUnit 距离0点的秒数转换为时分秒
+func secToHourUnit(sec int) hourUnit {
+	return hourUnit{Hour: sec / 3600, Minute: sec % 3600 / 60, Sec: sec % 60}
+}
+
+// IsIn 判断时间是否在营业时间内
+func (openingHours *OpeningHours) IsIn(t time.Time) bool {
+	if openingHours.alwaysOpen {
+		return true
+	}
+	_, _, ok := scanWindowAt(openingHours, t)
+	return ok
+}
+
+// GetStartTime 获取开始时间, 在营业时间内返回本次的开始时间, 否则返回下次的开始时间
+func (openingHours *OpeningHours) GetStartTime(t time.Time) (time.Time, error) {
+	if openingHours.alwaysOpen {
+		return time.Time{}, ErrAlwaysActiveNoStartTime
+	}
+	return scanStartTime(openingHours, t)
+}
+
+// GetEndTime 获取结束时间, 在营业时间内返回本次的结束时间, 否则返回下次的结束时间
+func (openingHours *OpeningHours) GetEndTime(t time.Time) (time.Time, error) {
+	if openingHours.alwaysOpen {
+		return time.Time{}, ErrNoEnd
+	}
+	return scanEndTime(openingHours, t)
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在营业时间内，都获取下次的时间
+func (openingHours *OpeningHours) GetNextStartTime(t time.Time) (time.Time, error) {
+	if openingHours.alwaysOpen {
+		return time.Time{}, ErrAlwaysActiveNoStartTime
+	}
+	return nextStartTime(openingHours, t)
+}
+
+// String 格式化为opening_hours, etc: Mo-Fr 09:00-18:00; Sa 10:00-14:00; PH off
+func (openingHours *OpeningHours) String() string {
+	var builder strings.Builder
+	for i, rule := range openingHours.rules {
+		if i > 0 {
+			if rule.additional {
+				builder.WriteString(", ")
+			} else {
+				builder.WriteString("; ")
+			}
+		}
+		builder.WriteString(rule.String())
+	}
+	return builder.String()
+}
+
+// String 格式化一条规则
+func (rule *openingRule) String() string {
+	if rule.isAlwaysOpen() {
+		return "24/7"
+	}
+
+	var parts []string
+	if rule.yearStart > 0 && rule.yearEnd == 0 {
+		parts = append(parts, strconv.Itoa(rule.yearStart)+"+")
+	} else if rule.yearStart > 0 {
+		parts = append(parts, formatOpeningRange(rule.yearStart, rule.yearEnd, strconv.Itoa))
+	}
+	if len(rule.months) > 0 {
+		parts = append(parts, formatOpeningList(rule.months, 12, func(month int) string { return openingMonths[month] }))
+	}
+	if rule.month > 0 {
+		parts = append(parts, openingMonths[rule.month]+" "+formatOpeningRange(rule.days[0], rule.days[len(rule.days)-1],
+			func(day int) string { return fmt.Sprintf("%02d", day) }))
+	}
+
+	var selectors []string
+	if len(rule.weekdays) > 0 {
+		weekdays := formatOpeningList(rule.weekdays, 7, func(weekday int) string { return openingWeekdays[weekday] })
+		if len(rule.nth) > 0 {
+			nths := make([]string, 0, len(rule.nth))
+			for _, nth := range rule.nth {
+				nths = append(nths, strconv.Itoa(nth))
+			}
+			weekdays += "[" + strings.Join(nths, ",") + "]"
+		}
+		selectors = append(selectors, weekdays)
+	}
+	if rule.holiday {
+		selectors = append(selectors, "PH")
+	}
+	if len(selectors) > 0 {
+		parts = append(parts, strings.Join(selectors, ","))
+	}
+
+	if rule.off {
+		parts = append(parts, "off")
+	} else {
+		spans := make([]string, 0, len(rule.spans))
+		for _, span := range rule.spans {
+			spans = append(spans, formatOpeningTime(span.start)+"-"+formatOpeningTime(span.end))
+		}
+		parts = append(parts, strings.Join(spans, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatOpeningTime 格式化为hh:mm, 跨过0点的时间去掉24小时, 24:00保持不变
+func formatOpeningTime(sec int) string {
+	if sec > secondsPerDay {
+		sec -= secondsPerDay
+	}
+	return fmt.Sprintf("%02d:%02d", sec/3600, sec%3600/60)
+}
+
+// formatOpeningRange 格式化为 a 或者 a-b
+func formatOpeningRange(start int, end int, format func(int) string) string {
+	if start == end {
+		return format(start)
+	}
+	return format(start) + "-" + format(end)
+}
+
+// formatOpeningList 按规则中的顺序格式化, 连续3个以上的使用范围, 可以跨过周期的末尾, etc: Mo-Fr, Sa,Su, Nov-Feb
+func formatOpeningList(values []int, period int, format func(int) string) string {
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]%period+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, format(values[i])+"-"+format(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, format(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// ToOpeningHours 把时间表达式转换为一条opening_hours规则
+// opening_hours只能精确到分钟, 日期只能在单个月份中指定, 无法表达的部分返回ErrOpeningHoursLossy
+func (expression *DateTimeExpression) ToOpeningHours() (string, error) {
+	if expression.alwaysActive {
+		return "24/7", nil
+	}
+	if expression.dayStart != 0 {
+		return "", fmt.Errorf("%w: day does not start at 00:00:00", ErrOpeningHoursLossy)
+	}
+	switch expression.month.kind {
+	case monthKindMonth, monthKindQuarter:
+	case monthKindLunar, monthKindLunarLeap:
+		return "", fmt.Errorf("%w: lunar month %s", ErrOpeningHoursLossy, expression.month)
+	case monthKindWeekOfYear:
+		return "", fmt.Errorf("%w: ISO week %s", ErrOpeningHoursLossy, expression.month)
+	case monthKindFiscal, monthKindFiscalQuarter:
+		return "", fmt.Errorf("%w: fiscal period %s", ErrOpeningHoursLossy, expression.month)
+	default:
+		return "", fmt.Errorf("%w: month %s", ErrOpeningHoursLossy, expression.month)
+	}
+	if expression.hour.hasSun {
+		return "", fmt.Errorf("%w: sunrise and sunset depend on the geo location", ErrOpeningHoursLossy)
+	}
+
+	rule := &openingRule{}
+	switch year := expression.year; {
+	case year.isAll:
+	case !year.hasStart:
+		return "", fmt.Errorf("%w: year %s has no start", ErrOpeningHoursLossy, year)
+	case !year.hasEnd:
+		rule.yearStart = year.start
+	default:
+		rule.yearStart, rule.yearEnd = year.start, year.end
+	}
+
+	switch day := expression.day; day.kind {
+	case dayKindWeekday:
+		rule.weekdays = intRange(day.start, day.end)
+	case dayKindNthWeekday:
+		rule.weekdays = intRange(day.start, day.end)
+		rule.nth = []int{day.nth}
+	case dayKindMonthDay:
+		if day.isAll {
+			break
+		}
+		if expression.month.isAll || expression.month.start != expression.month.end {
+			return "", fmt.Errorf("%w: day %s must be in a single month", ErrOpeningHoursLossy, day)
+		}
+		rule.month = expression.month.start
+		rule.days = intRange(day.start, day.end)
+	default:
+		return "", fmt.Errorf("%w: day %s cannot be represented in opening hours", ErrOpeningHoursLossy, day)
+	}
+	if !expression.month.isAll && rule.month == 0 {
+		rule.months = intRange(expression.month.start, expression.month.end)
+	}
+
+	for _, unit := range expression.hour.hourUnits {
+		if unit.start.Sec != 0 || unit.end.Sec != 0 || unit.start.Nsec != 0 || unit.end.Nsec != 0 {
+			return "", fmt.Errorf("%w: time %s-%s has seconds", ErrOpeningHoursLossy, unit.start.String(), unit.end.String())
+		}
+		rule.spans = append(rule.spans, openingSpan{start: unit.start.toSec(), end: unit.end.toSec()})
+	}
+
+	return rule.String(), nil
+}
@@ -0,0 +1,244 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestParseOpeningHours 来自OpenStreetMap中常见的opening_hours
+func TestParseOpeningHours(t *testing.T) {
+	testDatas := []struct {
+		value string
+		// formatted String()的结果, 为空时和value相同
+		formatted string
+		err       error
+	}{
+		{value: "24/7"},
+		{value: "Mo-Su 07:00-23:00"},
+		{value: "Mo-Fr 09:00-18:00; Sa 10:00-14:00; PH off"},
+		{value: "Mo-Fr 08:00-12:00,13:00-17:00"},
+		{value: "Mo-Sa 10:00-20:00; Su off"},
+		{value: "Mo-Fr 08:30-20:00, Sa 09:00-14:00"},
+		{value: "Mo-Th 11:00-23:00; Fr,Sa 11:00-01:00; Su 12:00-22:00"},
+		{value: "Mo-Fr 06:00-22:00; Sa,Su 08:00-22:00; PH off"},
+		{value: "Mo,Tu,Th,Fr 12:00-18:00; Sa,PH 12:00-17:00; Th[3],Th[-1] off", formatted: "Mo,Tu,Th,Fr 12:00-18:00; Sa,PH 12:00-17:00; Th[3,-1] off"},
+		{value: "Jan-Mar Mo-Fr 10:00-16:00; Apr-Dec Mo-Fr 09:00-18:00"},
+		{value: "Nov-Feb Mo-Fr 10:00-16:00"},
+		{value: "Mo-Fr 07:00-19:00; Sa 08:00-16:00; Dec 24 off"},
+		{value: "Mo-Sa 09:00-19:00; Dec 24-26 off; Dec 31 09:00-14:00"},
+		{value: "Mo-Fr 9:00-17:00", formatted: "Mo-Fr 09:00-17:00"},
+		{value: "Mo - Fr 08:00 - 18:00", formatted: "Mo-Fr 08:00-18:00"},
+		{value: "Mo-Fr 09:00-17:00; Sa closed", formatted: "Mo-Fr 09:00-17:00; Sa off"},
+		{value: "Su-Tu 10:00-18:00"},
+		{value: "Fr-Su 18:00-03:00"},
+		{value: "Mo-Fr 08:00-18:00; We 08:00-13:00"},
+		{value: `Mo-Fr 10:00-20:00 "appointments only"`, formatted: "Mo-Fr 10:00-20:00"},
+		{value: "2024 Jun-Aug Sa,Su 10:00-18:00"},
+		{value: "2024+ Jun-Aug Sa,Su 10:00-18:00"},
+		{value: "Mo-Sa 09:00-20:00; PH 10:00-14:00"},
+		{value: "Mo-Fr 00:00-24:00"},
+		{value: "Fr[1-2] 18:00-22:00", formatted: "Fr[1,2] 18:00-22:00"},
+		{value: "Mo-Fr 22:00-24:00,00:00-06:00;", formatted: "Mo-Fr 22:00-24:00,00:00-06:00"},
+
+		{value: "sunrise-sunset", err: ErrOpeningHoursUnsupported},
+		{value: "Mo-Fr 10:00-18:00; Sa 10:00-sunset", err: ErrOpeningHoursUnsupported},
+		{value: "Mo-Fr 10:00+", err: ErrOpeningHoursUnsupported},
+		{value: `Mo-Fr 09:00-18:00 || "by appointment"`, err: ErrOpeningHoursUnsupported},
+		{value: "Mo-Fr 08:00-16:00; SH off", err: ErrOpeningHoursUnsupported},
+		{value: "week 01-26 Mo 10:00-12:00", err: ErrOpeningHoursUnsupported},
+		{value: "Mo unknown", err: ErrOpeningHoursUnsupported},
+		{value: "Mo,Th[1] 10:00-12:00", err: ErrOpeningHoursUnsupported},
+		{value: "", err: ErrOpeningHoursFormat},
+		{value: "Mo-Xy 10:00-12:00", err: ErrOpeningHoursFormat},
+		{value: "Mo-Fr 25:00-26:00", err: ErrOpeningHoursFormat},
+		{value: "Mo-Fr 10:00", err: ErrOpeningHoursFormat},
+		{value: "Mo-Fr 10:00-12:00 Sa", err: ErrOpeningHoursFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] value:%s\n", i, data.value)
+		openingHours, err := ParseOpeningHours(data.value)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.value
+		}
+		assert.Equal(t, formatted, openingHours.String())
+
+		// 格式化后的结果可以解析回来
+		parsed, err := ParseOpeningHours(openingHours.String())
+		if assert.Nil(t, err) {
+			assert.Equal(t, formatted, parsed.String())
+		}
+	}
+}
+
+func TestOpeningHours(t *testing.T) {
+	testDatas := []struct {
+		value string
+		now   time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		next  time.Time
+		err   error
+	}{
+		{
+			value: "Mo-Fr 09:00-18:00; Sa 10:00-14:00; PH off",
+			now:   time.Date(2024, time.March, 6, 10, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 6, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 6, 18, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 7, 9, 0, 0, 0, time.Local),
+		},
+		{
+			value: "Mo-Fr 09:00-18:00; Sa 10:00-14:00; PH off",
+			now:   time.Date(2024, time.March, 9, 15, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 11, 9, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 11, 18, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 11, 9, 0, 0, 0, time.Local),
+		},
+		{
+			// 周五晚上的营业延续到周六凌晨
+			value: "Fr-Sa 18:00-03:00",
+			now:   time.Date(2024, time.March, 9, 2, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 8, 18, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 9, 3, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 9, 18, 0, 0, 0, time.Local),
+		},
+		{
+			value: "Fr-Sa 18:00-03:00",
+			now:   time.Date(2024, time.March, 10, 4, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 15, 18, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 16, 3, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 15, 18, 0, 0, 0, time.Local),
+		},
+		{
+			// 圣诞前夜是周二, 覆盖了前面的规则
+			value: "Mo-Fr 07:00-19:00; Sa 08:00-16:00; Dec 24 off",
+			now:   time.Date(2024, time.December, 24, 10, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.December, 25, 7, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.December, 25, 19, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.December, 25, 7, 0, 0, 0, time.Local),
+		},
+		{
+			// 2024-03-21是第3个周四
+			value: "Mo,Tu,Th,Fr 12:00-18:00; Sa,PH 12:00-17:00; Th[3],Th[-1] off",
+			now:   time.Date(2024, time.March, 21, 13, 0, 0, 0, time.Local),
+			start: time.Date(2024, time.March, 22, 12, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 22, 18, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 22, 12, 0, 0, 0, time.Local),
+		},
+		{
+			// 附加的规则不覆盖之前的时间段
+			value: "Mo-Fr 08:00-12:00; We 14:00-18:00; We 19:00-21:00, Mo-Fr 13:00-14:00",
+			now:   time.Date(2024, time.March, 6, 13, 30, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 6, 13, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 6, 14, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 6, 19, 0, 0, 0, time.Local),
+		},
+		{
+			value: "2024 Jun-Aug Sa,Su 10:00-18:00",
+			now:   time.Date(2024, time.September, 1, 10, 0, 0, 0, time.Local),
+			err:   ErrOutOfDate,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] value:%s now:%s\n", i, data.value, data.now)
+		openingHours, err := ParseOpeningHours(data.value)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.isIn, openingHours.IsIn(data.now))
+
+		start, err := openingHours.GetStartTime(data.now)
+		if data.err != nil {
+			assert.Equal(t, data.err, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+
+		end, err := openingHours.GetEndTime(data.now)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+
+		next, err := openingHours.GetNextStartTime(data.now)
+		assert.Nil(t, err)
+		assert.Equal(t, data.next, next)
+	}
+
+	// 24/7和永远有效的时间表达式一致
+	openingHours, err := ParseOpeningHours("24/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	assert.True(t, openingHours.IsIn(now))
+	_, err = openingHours.GetStartTime(now)
+	assert.Equal(t, ErrAlwaysActiveNoStartTime, err)
+	_, err = openingHours.GetEndTime(now)
+	assert.Equal(t, ErrNoEnd, err)
+	_, err = openingHours.GetNextStartTime(now)
+	assert.Equal(t, ErrAlwaysActiveNoStartTime, err)
+}
+
+func TestDateTimeExpression_ToOpeningHours(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		value string
+		err   error
+	}{
+		{exp: "[*][*][*][*]", value: "24/7"},
+		{exp: "[*][*][*][20:00:00-22:00:00]", value: "20:00-22:00"},
+		{exp: "[*][*][w1-5][09:00:00-12:00:00,13:00:00-18:00:00]", value: "Mo-Fr 09:00-12:00,13:00-18:00"},
+		{exp: "[2024][03][05-07][08:00:00-10:00:00]", value: "2024 Mar 05-07 08:00-10:00"},
+		{exp: "[2024-2025][06-08][w6-7][*]", value: "2024-2025 Jun-Aug Sa,Su 00:00-24:00"},
+		{exp: "[2024-][06-08][w6-7][*]", value: "2024+ Jun-Aug Sa,Su 00:00-24:00"},
+		{exp: "[*][*][w5#3][10:00:00-11:00:00]", value: "Fr[3] 10:00-11:00"},
+		{exp: "[*][*][w7L][*]", value: "Su[-1] 00:00-24:00"},
+
+		{exp: "[*][*][L][*]", err: ErrOpeningHoursLossy},
+		{exp: "[-2030][*][*][*]", err: ErrOpeningHoursLossy},
+		{exp: "[*][*][15W][*]", err: ErrOpeningHoursLossy},
+		{exp: "[*][02-03][05][*]", err: ErrOpeningHoursLossy},
+		{exp: "[*][*][05][*]", err: ErrOpeningHoursLossy},
+		{exp: "[*][*][*][10:00:30-11:00:00]", err: ErrOpeningHoursLossy},
+	}
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+	until := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := expr.ToOpeningHours()
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.value, value)
+
+		// 解析回来后周期一致
+		openingHours, err := ParseOpeningHours(value)
+		if !assert.Nil(t, err) || expr.alwaysActive {
+			continue
+		}
+		assert.Equal(t, icalWindows(t, expr, from, until), icalWindows(t, openingHours, from, until))
+	}
+}
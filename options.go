@@ -0,0 +1,77 @@
+package timeexpression
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option 创建表达式时的可选配置
+type Option func(*options) error
+
+// options 表达式的可选配置
+type options struct {
+	// dayStart 逻辑上一天开始的时间, 距离0点的秒数
+	dayStart int
+	// calendar 节假日和调休的日历, 工作日(bd)需要使用
+	calendar *Calendar
+	// location 观测者的位置, 日出日落(sunrise, sunset)需要使用
+	location *GeoLocation
+	// fiscal 财年, 月份为财年的期(F开头)时需要使用
+	fiscal *FiscalCalendar
+}
+
+// newOptions 应用所有的可选配置
+func newOptions(opts []Option) (*options, error) {
+	o := &options{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithDayStart 设置逻辑上一天开始的时间, 例如每天5点重置时为5*time.Hour, 需要为[0, 24h)中的整秒
+// 设置后日, 月, 年都在这个时间切换, 例如[*][*][01][*]为1日05:00到2日05:00
+// 时分秒仍然是钟表上的时间, 早于开始时间的属于逻辑上这一天的末尾, 例如02:00-04:00为2日的02:00-04:00
+func WithDayStart(offset time.Duration) Option {
+	return func(o *options) error {
+		if offset < 0 || offset >= 24*time.Hour || offset%time.Second != 0 {
+			return fmt.Errorf("%w: %s", ErrDayStartFormat, offset)
+		}
+		o.dayStart = int(offset / time.Second)
+		return nil
+	}
+}
+
+// WithCalendar 设置节假日和调休的日历, 日的字段为bd(工作日)时必须设置
+// opening_hours中的PH也按这个日历中的节假日计算
+func WithCalendar(calendar *Calendar) Option {
+	return func(o *options) error {
+		o.calendar = calendar
+		return nil
+	}
+}
+
+// WithGeoLocation 设置观测者的纬度, 经度(度, 北纬和东经为正)和海拔(米), 时分秒使用日出日落(sunrise, sunset)时必须设置
+func WithGeoLocation(latitude float64, longitude float64, elevation float64) Option {
+	return func(o *options) error {
+		location := GeoLocation{Latitude: latitude, Longitude: longitude, Elevation: elevation}
+		if err := location.check(); err != nil {
+			return err
+		}
+		o.location = &location
+		return nil
+	}
+}
+
+// WithFiscalCalendar 设置财年, 月份为财年的期(F开头)时必须设置, 此时年和日也按财年计算
+func WithFiscalCalendar(calendar FiscalCalendar) Option {
+	return func(o *options) error {
+		if err := calendar.check(); err != nil {
+			return err
+		}
+		o.fiscal = &calendar
+		return nil
+	}
+}
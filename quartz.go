@@ -0,0 +1,205 @@
+package timeexpression
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// quartzWeekdayNames Quartz星期字段支持的名字, 1为周日, 7为周六
+var quartzWeekdayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+var (
+	// quartzLastDayRegex 日字段中的 L, L-n, LW, nW
+	quartzLastDayRegex = regexp.MustCompile(`^(L(-\d+)?|LW|\d{1,2}W)$`)
+	// quartzNthWeekdayRegex 星期字段中的 nL, n#k
+	quartzNthWeekdayRegex = regexp.MustCompile(`^(\d|[A-Za-z]{3})(L|#(\d))$`)
+)
+
+// quartzYearMin, quartzYearMax Quartz年字段的范围
+const (
+	quartzYearMin = 1970
+	quartzYearMax = 2099
+)
+
+// ParseQuartz 把Quartz的cron表达式(秒 分 时 日 月 星期 [年])转换为时间表达式
+// 和ParseCron一样, 每次触发转换为1秒的周期
+// 支持 ?, 日字段的 L, L-n, LW, nW, 星期字段的 L, nL, n#k, Quartz中星期1为周日, 7为周六
+// 日, 月, 年只支持连续的范围, 同时配置日和星期是不支持的
+func ParseQuartz(spec string) (*DateTimeExpression, error) {
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 6:
+		fields = append(fields, "*")
+	case 7:
+	default:
+		return nil, fmt.Errorf("%w: expect 6 or 7 fields, got %d", ErrCronFormat, len(fields))
+	}
+
+	seconds, err := parseCronList(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := parseCronList(fields[1], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := parseCronList(fields[2], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	day, err := parseQuartzDay(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	month, err := parseCronMonth(fields[4])
+	if err != nil {
+		return nil, err
+	}
+	weekday, err := parseQuartzWeekday(fields[5])
+	if err != nil {
+		return nil, err
+	}
+	year, err := parseCronContinuous(fields[6], quartzYearMin, quartzYearMax, nil, "%d")
+	if err != nil {
+		return nil, err
+	}
+	if weekday != "*" {
+		if day != "*" {
+			return nil, fmt.Errorf("%w: both day of month and day of week are restricted", ErrCronUnsupported)
+		}
+		day = weekday
+	}
+
+	units, err := cronFiringUnits(seconds, minutes, hours, ErrCronUnsupported)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDateTimeExpression("[" + year + "][" + month + "][" + day + "][" + units + "]")
+}
+
+// parseQuartzDay 解析Quartz的日字段, L和W的写法和时间表达式中的相同
+func parseQuartzDay(field string) (string, error) {
+	if !quartzLastDayRegex.MatchString(field) {
+		return parseCronDay(field)
+	}
+	if strings.HasSuffix(field, "W") && field != "LW" {
+		day, err := parseCronValue(strings.TrimSuffix(field, "W"), 1, 31, nil)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%02dW", day), nil
+	}
+	if _, err := newPositionalDayExpression(field); err != nil {
+		return "", fmt.Errorf("%w: invalid day '%s'", ErrCronFormat, field)
+	}
+	return field, nil
+}
+
+// parseQuartzWeekday 解析Quartz的星期字段, 返回w1-7格式
+func parseQuartzWeekday(field string) (string, error) {
+	if field == "*" || field == "?" {
+		return "*", nil
+	}
+	if field == "L" {
+		// 单独的L表示周六
+		return "w6", nil
+	}
+	if matches := quartzNthWeekdayRegex.FindStringSubmatch(field); matches != nil {
+		value, err := parseCronValue(matches[1], 1, 7, quartzWeekdayNames)
+		if err != nil {
+			return "", err
+		}
+		if matches[2] == "L" {
+			return fmt.Sprintf("w%dL", quartzToISOWeekday(value)), nil
+		}
+		nth, _ := strconv.Atoi(matches[3])
+		if nth < 1 || nth > 5 {
+			return "", fmt.Errorf("%w: invalid nth weekday '%s'", ErrCronFormat, field)
+		}
+		return fmt.Sprintf("w%d#%d", quartzToISOWeekday(value), nth), nil
+	}
+
+	values, err := parseCronList(field, 1, 7, quartzWeekdayNames)
+	if err != nil {
+		return "", err
+	}
+	weekdays := make([]int, 0, len(values))
+	for _, value := range values {
+		weekdays = append(weekdays, quartzToISOWeekday(value))
+	}
+	return formatWeekdayField(weekdays, field, ErrCronUnsupported)
+}
+
+// quartzToISOWeekday Quartz的星期(1为周日)转换为1为周一, 7为周日
+func quartzToISOWeekday(value int) int {
+	if value == 1 {
+		return 7
+	}
+	return value - 1
+}
+
+// isoToQuartzWeekday 1为周一, 7为周日 转换为Quartz的星期(1为周日)
+func isoToQuartzWeekday(weekday int) int {
+	return weekday%7 + 1
+}
+
+// ToQuartz 转换为在每个周期开始时触发的Quartz cron表达式(秒 分 时 日 月 星期 年)
+// 开始时间不同的时间段可能会生成多条表达式, 年超出1970-2099时返回ErrCronLossy
+func (expression *DateTimeExpression) ToQuartz() ([]string, error) {
+	plan, err := expression.triggerPlan(ErrCronLossy)
+	if err != nil {
+		return nil, err
+	}
+
+	yearField := "*"
+	if !expression.year.isAll {
+		// 没有开始年时从Quartz最小的年开始, 没有结束年时无法表示
+		if !expression.year.hasEnd || !expression.year.inRange(quartzYearMin, quartzYearMax) {
+			return nil, fmt.Errorf("%w: year %s out of range %d-%d", ErrCronLossy, expression.year, quartzYearMin, quartzYearMax)
+		}
+		yearStart := quartzYearMin
+		if expression.year.hasStart {
+			yearStart = expression.year.start
+		}
+		yearField = formatCronRange(yearStart, expression.year.end, false)
+	}
+	monthField := formatCronRange(plan.month.start, plan.month.end, plan.month.isAll)
+	dayField, weekdayField := formatQuartzDay(plan.day)
+
+	var specs []string
+	for _, group := range groupCronStarts(plan.starts) {
+		specs = append(specs, strings.Join([]string{
+			strconv.Itoa(group.sec),
+			formatCronList(group.minutes, 0, 59),
+			formatCronList(group.hours, 0, 23),
+			dayField, monthField, weekdayField, yearField,
+		}, " "))
+	}
+	return specs, nil
+}
+
+// formatQuartzDay 格式化日和星期字段, 没有配置的一方为?
+func formatQuartzDay(day *dayExpression) (dayField string, weekdayField string) {
+	switch day.kind {
+	case dayKindNthWeekday:
+		if day.nth < 0 {
+			return "?", fmt.Sprintf("%dL", isoToQuartzWeekday(day.start))
+		}
+		return "?", fmt.Sprintf("%d#%d", isoToQuartzWeekday(day.start), day.nth)
+	case dayKindWeekday:
+		var values []int
+		for weekday := day.start; weekday <= day.end; weekday++ {
+			values = append(values, isoToQuartzWeekday(weekday))
+		}
+		return "?", formatCronList(values, 1, 7)
+	case dayKindLast, dayKindLastWeekday, dayKindNearestWeekday:
+		// L, L-n, LW 和 Quartz相同, ddW 去掉前导0
+		return strings.TrimPrefix(day.String(), "0"), "?"
+	}
+	return formatCronRange(day.start, day.end, day.isAll), "?"
+}
@@ -0,0 +1,241 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestParseQuartz 示例来自Quartz CronTrigger Tutorial
+func TestParseQuartz(t *testing.T) {
+	testDatas := []struct {
+		spec string
+		exp  string
+		err  error
+		// from之后的第一次触发
+		from time.Time
+		next time.Time
+	}{
+		{
+			spec: "0 0 12 * * ?",
+			exp:  "[*][*][*][12:00:00-12:00:01]",
+		},
+		{
+			spec: "0 15 10 ? * *",
+			exp:  "[*][*][*][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 15 10 * * ? *",
+			exp:  "[*][*][*][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 15 10 * * ? 2005",
+			exp:  "[2005][*][*][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 0-5 14 * * ?",
+			exp:  "[*][*][*][14:00:00-14:00:01,14:01:00-14:01:01,14:02:00-14:02:01,14:03:00-14:03:01,14:04:00-14:04:01,14:05:00-14:05:01]",
+		},
+		{
+			spec: "0 0/30 14,18 * * ?",
+			exp:  "[*][*][*][14:00:00-14:00:01,14:30:00-14:30:01,18:00:00-18:00:01,18:30:00-18:30:01]",
+		},
+		{
+			spec: "0 10,44 14 ? 3 WED",
+			exp:  "[*][03][w3][14:10:00-14:10:01,14:44:00-14:44:01]",
+		},
+		{
+			spec: "0 15 10 ? * MON-FRI",
+			exp:  "[*][*][w1-5][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 15 10 15 * ?",
+			exp:  "[*][*][15][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 15 10 L * ?",
+			exp:  "[*][*][L][10:15:00-10:15:01]",
+			from: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.February, 29, 10, 15, 0, 0, time.Local),
+		},
+		{
+			spec: "0 15 10 L-2 * ?",
+			exp:  "[*][*][L-2][10:15:00-10:15:01]",
+			from: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.February, 27, 10, 15, 0, 0, time.Local),
+		},
+		{
+			spec: "0 15 10 ? * 6L",
+			exp:  "[*][*][w5L][10:15:00-10:15:01]",
+			from: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.March, 29, 10, 15, 0, 0, time.Local),
+		},
+		{
+			spec: "0 15 10 ? * 6L 2002-2005",
+			exp:  "[2002-2005][*][w5L][10:15:00-10:15:01]",
+		},
+		{
+			spec: "0 15 10 ? * 6#3",
+			exp:  "[*][*][w5#3][10:15:00-10:15:01]",
+			from: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.March, 15, 10, 15, 0, 0, time.Local),
+		},
+		{
+			spec: "0 11 11 11 11 ?",
+			exp:  "[*][11][11][11:11:00-11:11:01]",
+		},
+		{
+			spec: "0 0 12 LW * ?",
+			exp:  "[*][*][LW][12:00:00-12:00:01]",
+			from: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.March, 29, 12, 0, 0, 0, time.Local),
+		},
+		{
+			spec: "0 0 12 1W * ?",
+			exp:  "[*][*][01W][12:00:00-12:00:01]",
+			from: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+			next: time.Date(2024, time.June, 3, 12, 0, 0, 0, time.Local),
+		},
+		{
+			spec: "0 0 12 ? * L",
+			exp:  "[*][*][w6][12:00:00-12:00:01]",
+		},
+		{
+			spec: "0 0 12 ? * 7,1",
+			exp:  "[*][*][w6-7][12:00:00-12:00:01]",
+		},
+		{
+			// 每5天触发, 不是连续的范围
+			spec: "0 0 12 1/5 * ?",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 12 1 * 2",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 12 ? * 2,4",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 12 * * ? 2005,2007",
+			err:  ErrCronUnsupported,
+		},
+		{
+			spec: "0 0 12 ? * 6#6",
+			err:  ErrCronFormat,
+		},
+		{
+			spec: "0 0 12 ? * 8",
+			err:  ErrCronFormat,
+		},
+		{
+			spec: "0 0 12 * *",
+			err:  ErrCronFormat,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] spec:%s\n", i, data.spec)
+		expr, err := ParseQuartz(data.spec)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		if !data.from.IsZero() {
+			next, err := expr.GetNextStartTime(data.from)
+			assert.Nil(t, err)
+			assert.Equal(t, data.next, next)
+		}
+	}
+}
+
+func TestDateTimeExpression_ToQuartz(t *testing.T) {
+	testDatas := []struct {
+		exp   string
+		err   error
+		specs []string
+	}{
+		{
+			exp:   "[*][*][*][20:00:00-22:00:00]",
+			specs: []string{"0 0 20 * * ? *"},
+		},
+		{
+			exp:   "[2024-2025][02-04][05-07][08:00:00-10:00:00,20:00:00-21:00:00]",
+			specs: []string{"0 0 8,20 5-7 2-4 ? 2024-2025"},
+		},
+		{
+			exp:   "[*][*][w6-7][18:00:00-22:00:00]",
+			specs: []string{"0 0 18 ? * 1,7 *"},
+		},
+		{
+			exp:   "[*][*][w5#3][10:00:00-11:00:00]",
+			specs: []string{"0 0 10 ? * 6#3 *"},
+		},
+		{
+			exp:   "[*][*][w5L][10:00:00-11:00:00]",
+			specs: []string{"0 0 10 ? * 6L *"},
+		},
+		{
+			exp:   "[*][*][L-2][*]",
+			specs: []string{"0 0 0 L-2 * ? *"},
+		},
+		{
+			exp:   "[*][*][01W][09:00:00-10:00:00]",
+			specs: []string{"0 0 9 1W * ? *"},
+		},
+		{
+			exp:   "[-2030][*][*][20:00:00-22:00:00]",
+			specs: []string{"0 0 20 * * ? 1970-2030"},
+		},
+		{
+			exp: "[1900][*][*][20:00:00-22:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[2024-][*][*][20:00:00-22:00:00]",
+			err: ErrCronLossy,
+		},
+		{
+			exp: "[*][*][*][*]",
+			err: ErrCronLossy,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs, err := expr.ToQuartz()
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data.specs, specs)
+
+		// 转换回来后, 触发时间和周期开始时间一致
+		if len(specs) == 1 {
+			parsed, err := ParseQuartz(specs[0])
+			if !assert.Nil(t, err) {
+				continue
+			}
+			from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+			for n := 0; n < 5; n++ {
+				want, wantErr := expr.GetNextStartTime(from)
+				got, gotErr := parsed.GetNextStartTime(from)
+				assert.Equal(t, wantErr, gotErr)
+				assert.Equal(t, want, got)
+				from = want
+			}
+		}
+	}
+}
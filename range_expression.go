@@ -0,0 +1,140 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRangeFormat 绝对时间范围的格式不对
+var ErrRangeFormat = errors.New("range format not math")
+
+var (
+	// rangeRegex 绝对时间范围, etc: 2024-01-05 10:00:00 ~ 2024-01-20 18:00:00 Asia/Shanghai
+	rangeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}(?: \d{1,2}:\d{2}:\d{2})?)\s*~\s*(\d{4}-\d{2}-\d{2}(?: \d{1,2}:\d{2}:\d{2})?)(?:\s+(\S+))?$`)
+	// rangeOffsetRegex 以UTC偏移表示的时区, etc: +08:00, -0500
+	rangeOffsetRegex = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})$`)
+)
+
+// RangeExpression 从开始时间到结束时间的一次性活动, 只有一个周期[start, end)
+type RangeExpression struct {
+	start time.Time
+	end   time.Time
+	// zone 表达式中的时区, 为空时使用time.Local
+	zone string
+}
+
+var _ Expression = (*RangeExpression)(nil)
+
+// NewRangeExpression 创建绝对时间范围的表达式, 格式为 yyyy-MM-dd[ hh:mm:ss] ~ yyyy-MM-dd[ hh:mm:ss][ 时区]
+// 只有日期时为当天的0点, 时区可以是IANA的名字(Asia/Shanghai, UTC)或者UTC偏移(+08:00, Z), 同时用于开始和结束时间
+func NewRangeExpression(expression string) (*RangeExpression, error) {
+	matches := rangeRegex.FindStringSubmatch(strings.TrimSpace(expression))
+	if matches == nil {
+		return nil, fmt.Errorf("%w: '%s' must be start ~ end", ErrRangeFormat, expression)
+	}
+
+	rangeExpression := &RangeExpression{zone: matches[3]}
+	location, err := parseRangeZone(rangeExpression.zone)
+	if err != nil {
+		return nil, err
+	}
+	if rangeExpression.start, err = parseRangeTime(matches[1], location); err != nil {
+		return nil, err
+	}
+	if rangeExpression.end, err = parseRangeTime(matches[2], location); err != nil {
+		return nil, err
+	}
+	if !rangeExpression.start.Before(rangeExpression.end) {
+		return nil, fmt.Errorf("%w: start %s is not before end %s", ErrRangeFormat, matches[1], matches[2])
+	}
+
+	return rangeExpression, nil
+}
+
+// parseRangeZone 解析时区, 为空时使用time.Local
+func parseRangeZone(zone string) (*time.Location, error) {
+	if zone == "" {
+		return time.Local, nil
+	}
+	if zone == "Z" {
+		return time.UTC, nil
+	}
+	if matches := rangeOffsetRegex.FindStringSubmatch(zone); matches != nil {
+		hours, _ := strconv.Atoi(matches[2])
+		minutes, _ := strconv.Atoi(matches[3])
+		offset := hours*3600 + minutes*60
+		if matches[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(zone, offset), nil
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone '%s'", ErrRangeFormat, zone)
+	}
+	return location, nil
+}
+
+// parseRangeTime 解析开始或结束时间
+func parseRangeTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range cycleAnchorLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid time '%s'", ErrRangeFormat, value)
+}
+
+// IsIn 判断时间是否在范围内
+func (expression *RangeExpression) IsIn(t time.Time) bool {
+	return !t.Before(expression.start) && t.Before(expression.end)
+}
+
+// GetStartTime 获取开始时间, 在范围内或者范围之前返回开始时间, 范围之后返回ErrOutOfDate
+func (expression *RangeExpression) GetStartTime(t time.Time) (time.Time, error) {
+	if !t.Before(expression.end) {
+		return time.Time{}, ErrOutOfDate
+	}
+	return expression.start, nil
+}
+
+// GetEndTime 获取结束时间, 在范围内或者范围之前返回结束时间, 范围之后返回ErrOutOfDate
+func (expression *RangeExpression) GetEndTime(t time.Time) (time.Time, error) {
+	if !t.Before(expression.end) {
+		return time.Time{}, ErrOutOfDate
+	}
+	return expression.end, nil
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在范围内，都获取下次的时间, 只有一个周期, 在范围内时返回ErrOutOfDate
+func (expression *RangeExpression) GetNextStartTime(t time.Time) (time.Time, error) {
+	return nextStartTime(expression, t)
+}
+
+// FirstStartTime 获取开始时间
+func (expression *RangeExpression) FirstStartTime() (time.Time, error) {
+	return expression.start, nil
+}
+
+// FinalEndTime 获取结束时间
+func (expression *RangeExpression) FinalEndTime() (time.Time, error) {
+	return expression.end, nil
+}
+
+// IsExpired 判断是否已经过了结束时间
+func (expression *RangeExpression) IsExpired(t time.Time) bool {
+	return !t.Before(expression.end)
+}
+
+// String 格式化为标准的表达式 yyyy-MM-dd hh:mm:ss ~ yyyy-MM-dd hh:mm:ss[ 时区]
+func (expression *RangeExpression) String() string {
+	value := expression.start.Format("2006-01-02 15:04:05") + " ~ " + expression.end.Format("2006-01-02 15:04:05")
+	if expression.zone != "" {
+		value += " " + expression.zone
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewRangeExpression(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDatas := []struct {
+		exp string
+		// formatted String()的结果, 为空时和exp相同
+		formatted string
+		start     time.Time
+		end       time.Time
+		err       error
+	}{
+		{
+			exp:   "2024-01-05 10:00:00 ~ 2024-01-20 18:00:00",
+			start: time.Date(2024, time.January, 5, 10, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.January, 20, 18, 0, 0, 0, time.Local),
+		},
+		{
+			exp:       "2024-01-05~2024-01-06",
+			formatted: "2024-01-05 00:00:00 ~ 2024-01-06 00:00:00",
+			start:     time.Date(2024, time.January, 5, 0, 0, 0, 0, time.Local),
+			end:       time.Date(2024, time.January, 6, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "2024-01-05 10:00:00 ~ 2024-01-20 18:00:00 Asia/Shanghai",
+			start: time.Date(2024, time.January, 5, 10, 0, 0, 0, shanghai),
+			end:   time.Date(2024, time.January, 20, 18, 0, 0, 0, shanghai),
+		},
+		{
+			exp:   "2024-01-05 10:00:00 ~ 2024-01-05 12:00:00 UTC",
+			start: time.Date(2024, time.January, 5, 10, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			exp:   "2024-01-05 10:00:00 ~ 2024-01-05 12:00:00 -05:00",
+			start: time.Date(2024, time.January, 5, 15, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.January, 5, 17, 0, 0, 0, time.UTC),
+		},
+
+		{exp: "2024-01-20 18:00:00 ~ 2024-01-05 10:00:00", err: ErrRangeFormat},
+		{exp: "2024-01-05 10:00:00 ~ 2024-01-05 10:00:00", err: ErrRangeFormat},
+		{exp: "2024-01-05 10:00:00", err: ErrRangeFormat},
+		{exp: "2024-02-30 ~ 2024-03-01", err: ErrRangeFormat},
+		{exp: "2024-01-05 ~ 2024-01-06 Mars/Olympus", err: ErrRangeFormat},
+		{exp: "[2024][*][*][*]", err: ErrRangeFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewRangeExpression(data.exp)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.True(t, data.start.Equal(expr.start), expr.start.String())
+		assert.True(t, data.end.Equal(expr.end), expr.end.String())
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.exp
+		}
+		assert.Equal(t, formatted, expr.String())
+	}
+}
+
+func TestRangeExpression(t *testing.T) {
+	expr, err := NewRangeExpression("2024-01-05 10:00:00 ~ 2024-01-20 18:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, time.January, 5, 10, 0, 0, 0, time.Local)
+	end := time.Date(2024, time.January, 20, 18, 0, 0, 0, time.Local)
+
+	testDatas := []struct {
+		t        time.Time
+		isIn     bool
+		start    time.Time
+		startErr error
+		end      time.Time
+		endErr   error
+		next     time.Time
+		nextErr  error
+		expired  bool
+	}{
+		{
+			t:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: start,
+			end:   end,
+			next:  start,
+		},
+		{
+			t:       start,
+			isIn:    true,
+			start:   start,
+			end:     end,
+			nextErr: ErrOutOfDate,
+		},
+		{
+			t:       end.Add(-time.Second),
+			isIn:    true,
+			start:   start,
+			end:     end,
+			nextErr: ErrOutOfDate,
+		},
+		{
+			t:        end,
+			startErr: ErrOutOfDate,
+			endErr:   ErrOutOfDate,
+			nextErr:  ErrOutOfDate,
+			expired:  true,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] t:%s\n", i, data.t)
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		startTime, err := expr.GetStartTime(data.t)
+		assert.Equal(t, data.startErr, err)
+		assert.Equal(t, data.start, startTime)
+		endTime, err := expr.GetEndTime(data.t)
+		assert.Equal(t, data.endErr, err)
+		assert.Equal(t, data.end, endTime)
+		next, err := expr.GetNextStartTime(data.t)
+		assert.Equal(t, data.nextErr, err)
+		assert.Equal(t, data.next, next)
+		assert.Equal(t, data.expired, expr.IsExpired(data.t))
+	}
+
+	first, err := expr.FirstStartTime()
+	assert.Nil(t, err)
+	assert.Equal(t, start, first)
+	final, err := expr.FinalEndTime()
+	assert.Nil(t, err)
+	assert.Equal(t, end, final)
+}
@@ -0,0 +1,268 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRepeatingIntervalFormat ISO 8601重复区间格式不对
+	ErrRepeatingIntervalFormat = errors.New("repeating interval format not math")
+	// ErrRepeatingIntervalUnsupported ISO 8601重复区间中有无法表达的部分
+	ErrRepeatingIntervalUnsupported = errors.New("repeating interval feature is unsupported")
+)
+
+var (
+	// isoDurationRegex ISO 8601的时长, etc: P1Y2M10DT2H30M, P2W, PT90S
+	isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+	// isoZoneRegex 时间最后的时区, etc: Z, +08:00, -0500
+	isoZoneRegex = regexp.MustCompile(`T.*(Z|[+-]\d{2}(:?\d{2})?)$`)
+)
+
+// isoDateTimeLayouts 支持的ISO 8601时间格式, 带时区的格式在前
+var isoDateTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"20060102T150405Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"20060102T150405",
+	"2006-01-02",
+	"20060102",
+}
+
+// isoPeriod ISO 8601的时长, 年月日按日历计算, 时分秒按实际时长计算
+type isoPeriod struct {
+	years   int
+	months  int
+	weeks   int
+	days    int
+	hours   int
+	minutes int
+	seconds int
+}
+
+// RepeatingInterval ISO 8601的重复区间, 例如: R5/2024-01-01T10:00:00Z/PT2H
+// 每次重复的区间首尾相接, 第k次为[start+k*duration, start+(k+1)*duration)
+type RepeatingInterval struct {
+	start time.Time
+	// hasZone start是否带有时区, 不带时区时使用time.Local
+	hasZone bool
+	// repeat 重复的次数, -1表示不限
+	repeat int
+
+	// period 以时长表示的区间, etc: PT2H
+	period *isoPeriod
+	// end 以结束时间表示的区间, 为第一个区间的结束时间
+	end time.Time
+}
+
+var _ Expression = (*RepeatingInterval)(nil)
+
+// ParseRepeatingInterval 解析ISO 8601的重复区间, 支持 Rn/start/duration, Rn/start/end, 以及不限次数的 R/start/duration
+func ParseRepeatingInterval(value string) (*RepeatingInterval, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, fmt.Errorf("%w: '%s' must be Rn/start/duration or Rn/start/end", ErrRepeatingIntervalFormat, value)
+	}
+
+	interval := &RepeatingInterval{repeat: -1}
+	if parts[0] != "R" {
+		repeat, err := strconv.Atoi(parts[0][1:])
+		if err != nil || repeat < 1 {
+			return nil, fmt.Errorf("%w: invalid repetitions '%s'", ErrRepeatingIntervalFormat, parts[0])
+		}
+		interval.repeat = repeat
+	}
+
+	if strings.HasPrefix(parts[1], "P") {
+		return nil, fmt.Errorf("%w: duration/end '%s'", ErrRepeatingIntervalUnsupported, value)
+	}
+	var err error
+	interval.start, interval.hasZone, err = parseISODateTime(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(parts[2], "P") {
+		if interval.period, err = parseISOPeriod(parts[2]); err != nil {
+			return nil, err
+		}
+	} else {
+		end, hasZone, err := parseISODateTime(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		if hasZone != interval.hasZone {
+			return nil, fmt.Errorf("%w: start and end must both have or lack a time zone", ErrRepeatingIntervalFormat)
+		}
+		interval.end = end
+	}
+	if !interval.startOf(1).After(interval.start) {
+		return nil, fmt.Errorf("%w: interval '%s' is not positive", ErrRepeatingIntervalFormat, value)
+	}
+
+	return interval, nil
+}
+
+// parseISODateTime 解析ISO 8601的时间, 不带时区时使用time.Local
+func parseISODateTime(value string) (time.Time, bool, error) {
+	if strings.ContainsAny(value, ".,") {
+		return time.Time{}, false, fmt.Errorf("%w: fractional seconds '%s'", ErrRepeatingIntervalUnsupported, value)
+	}
+	hasZone := isoZoneRegex.MatchString(value)
+	for _, layout := range isoDateTimeLayouts {
+		if strings.Contains(layout, "Z07") != hasZone {
+			continue
+		}
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, hasZone, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%w: invalid time '%s'", ErrRepeatingIntervalFormat, value)
+}
+
+// parseISOPeriod 解析ISO 8601的时长
+func parseISOPeriod(value string) (*isoPeriod, error) {
+	matches := isoDurationRegex.FindStringSubmatch(value)
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		if strings.ContainsAny(value, ".,") {
+			return nil, fmt.Errorf("%w: fractional duration '%s'", ErrRepeatingIntervalUnsupported, value)
+		}
+		return nil, fmt.Errorf("%w: invalid duration '%s'", ErrRepeatingIntervalFormat, value)
+	}
+	values := make([]int, len(matches)-1)
+	for i, match := range matches[1:] {
+		if match != "" {
+			values[i], _ = strconv.Atoi(match)
+		}
+	}
+	return &isoPeriod{
+		years: values[0], months: values[1], weeks: values[2], days: values[3],
+		hours: values[4], minutes: values[5], seconds: values[6],
+	}, nil
+}
+
+// addTo 在t上加n个时长
+func (period *isoPeriod) addTo(t time.Time, n int) time.Time {
+	clock := time.Duration(period.hours)*time.Hour + time.Duration(period.minutes)*time.Minute + time.Duration(period.seconds)*time.Second
+	return t.AddDate(n*period.years, n*period.months, n*(period.weeks*7+period.days)).Add(time.Duration(n) * clock)
+}
+
+// String 格式化时长, 为0的部分省略
+func (period *isoPeriod) String() string {
+	var builder strings.Builder
+	builder.WriteString("P")
+	for _, item := range []struct {
+		value int
+		unit  string
+	}{{period.years, "Y"}, {period.months, "M"}, {period.weeks, "W"}, {period.days, "D"}} {
+		if item.value > 0 {
+			builder.WriteString(strconv.Itoa(item.value) + item.unit)
+		}
+	}
+	if period.hours > 0 || period.minutes > 0 || period.seconds > 0 {
+		builder.WriteString("T")
+		for _, item := range []struct {
+			value int
+			unit  string
+		}{{period.hours, "H"}, {period.minutes, "M"}, {period.seconds, "S"}} {
+			if item.value > 0 {
+				builder.WriteString(strconv.Itoa(item.value) + item.unit)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// startOf 第k次区间的开始时间, 第k次的结束时间为startOf(k+1)
+func (interval *RepeatingInterval) startOf(k int) time.Time {
+	if interval.period != nil {
+		return interval.period.addTo(interval.start, k)
+	}
+	return interval.start.Add(time.Duration(k) * interval.end.Sub(interval.start))
+}
+
+// indexOf 获取包含时间t的区间是第几次, t需要不早于start, 不考虑重复的次数
+func (interval *RepeatingInterval) indexOf(t time.Time) int {
+	return periodIndex(t, interval.startOf)
+}
+
+// periodIndex 获取时间t所在的是第几个周期, 即startOf(k) <= t < startOf(k+1), t需要不早于startOf(0)
+func periodIndex(t time.Time, startOf func(k int) time.Time) int {
+	// 按第一个周期的长度估算, 按日历计算的时长再前后调整
+	k := int(t.Sub(startOf(0)) / startOf(1).Sub(startOf(0)))
+	for k > 0 && startOf(k).After(t) {
+		k--
+	}
+	for !startOf(k + 1).After(t) {
+		k++
+	}
+	return k
+}
+
+// exhausted 第k次是否超过了重复的次数
+func (interval *RepeatingInterval) exhausted(k int) bool {
+	return interval.repeat >= 0 && k >= interval.repeat
+}
+
+// IsIn 判断时间是否在某次区间内
+func (interval *RepeatingInterval) IsIn(t time.Time) bool {
+	return !t.Before(interval.start) && !interval.exhausted(interval.indexOf(t))
+}
+
+// GetStartTime 获取开始时间, 在区间内返回本次的开始时间, 否则返回下次的开始时间
+func (interval *RepeatingInterval) GetStartTime(t time.Time) (time.Time, error) {
+	if t.Before(interval.start) {
+		return interval.start, nil
+	}
+	k := interval.indexOf(t)
+	if interval.exhausted(k) {
+		return time.Time{}, ErrOutOfDate
+	}
+	return interval.startOf(k), nil
+}
+
+// GetEndTime 获取结束时间, 在区间内返回本次的结束时间, 否则返回下次的结束时间
+func (interval *RepeatingInterval) GetEndTime(t time.Time) (time.Time, error) {
+	k := 0
+	if !t.Before(interval.start) {
+		k = interval.indexOf(t)
+	}
+	if interval.exhausted(k) {
+		return time.Time{}, ErrOutOfDate
+	}
+	return interval.startOf(k + 1), nil
+}
+
+// GetNextStartTime 获取下次开始时间,不管是否在区间内，都获取下次的时间
+func (interval *RepeatingInterval) GetNextStartTime(t time.Time) (time.Time, error) {
+	return nextStartTime(interval, t)
+}
+
+// String 格式化为ISO 8601的重复区间
+func (interval *RepeatingInterval) String() string {
+	repeat := "R"
+	if interval.repeat >= 0 {
+		repeat += strconv.Itoa(interval.repeat)
+	}
+	second := ""
+	if interval.period != nil {
+		second = interval.period.String()
+	} else {
+		second = interval.formatTime(interval.end)
+	}
+	return repeat + "/" + interval.formatTime(interval.start) + "/" + second
+}
+
+// formatTime 按是否带时区格式化时间
+func (interval *RepeatingInterval) formatTime(t time.Time) string {
+	if interval.hasZone {
+		return t.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return t.Format("2006-01-02T15:04:05")
+}
@@ -0,0 +1,149 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseRepeatingInterval(t *testing.T) {
+	testDatas := []struct {
+		value string
+		// formatted String()的结果, 为空时和value相同
+		formatted string
+		err       error
+	}{
+		{value: "R5/2024-01-01T10:00:00Z/PT2H"},
+		{value: "R/2024-03-04T20:00:00+08:00/P2W"},
+		{value: "R12/2024-01-31T00:00:00/P1M"},
+		{value: "R5/2008-03-01T13:00:00Z/P1Y2M10DT2H30M"},
+		{value: "R3/2024-01-01T10:00:00Z/2024-01-01T12:30:00Z"},
+		{value: "R/2024-01-01/P1D", formatted: "R/2024-01-01T00:00:00/P1D"},
+		{value: "R2/20240101T100000Z/PT90S", formatted: "R2/2024-01-01T10:00:00Z/PT90S"},
+		{value: "R/2024-01-01T10:00Z/PT0H30M", formatted: "R/2024-01-01T10:00:00Z/PT30M"},
+
+		{value: "R5/PT2H/2024-01-01T10:00:00Z", err: ErrRepeatingIntervalUnsupported},
+		{value: "R5/2024-01-01T10:00:00.5Z/PT2H", err: ErrRepeatingIntervalUnsupported},
+		{value: "R5/2024-01-01T10:00:00Z/PT1.5H", err: ErrRepeatingIntervalUnsupported},
+		{value: "2024-01-01T10:00:00Z/PT2H", err: ErrRepeatingIntervalFormat},
+		{value: "R0/2024-01-01T10:00:00Z/PT2H", err: ErrRepeatingIntervalFormat},
+		{value: "Rx/2024-01-01T10:00:00Z/PT2H", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-13-01T10:00:00Z/PT2H", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-01-01T10:00:00Z/P", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-01-01T10:00:00Z/PT", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-01-01T10:00:00Z/PT0S", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-01-01T12:00:00Z/2024-01-01T10:00:00Z", err: ErrRepeatingIntervalFormat},
+		{value: "R5/2024-01-01T10:00:00Z/2024-01-01T12:00:00", err: ErrRepeatingIntervalFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] value:%s\n", i, data.value)
+		interval, err := ParseRepeatingInterval(data.value)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if !assert.Nil(t, err) {
+			continue
+		}
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.value
+		}
+		assert.Equal(t, formatted, interval.String())
+	}
+}
+
+func TestRepeatingInterval(t *testing.T) {
+	testDatas := []struct {
+		value string
+		now   time.Time
+		isIn  bool
+		start time.Time
+		end   time.Time
+		next  time.Time
+		err   error
+	}{
+		{
+			value: "R5/2024-01-01T10:00:00Z/PT2H",
+			now:   time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC),
+			start: time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			value: "R5/2024-01-01T10:00:00Z/PT2H",
+			now:   time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+			isIn:  true,
+			start: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.January, 1, 14, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, time.January, 1, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			// 最后一次区间
+			value: "R5/2024-01-01T10:00:00Z/PT2H",
+			now:   time.Date(2024, time.January, 1, 19, 59, 59, 0, time.UTC),
+			isIn:  true,
+			start: time.Date(2024, time.January, 1, 18, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.January, 1, 20, 0, 0, 0, time.UTC),
+			err:   ErrOutOfDate,
+		},
+		{
+			value: "R5/2024-01-01T10:00:00Z/PT2H",
+			now:   time.Date(2024, time.January, 1, 20, 0, 0, 0, time.UTC),
+			err:   ErrOutOfDate,
+		},
+		{
+			// 按日历计算月份, 1月31日加1个月为3月2日
+			value: "R/2024-01-31T00:00:00/P1M",
+			now:   time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local),
+			isIn:  true,
+			start: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2024, time.March, 31, 0, 0, 0, 0, time.Local),
+			next:  time.Date(2024, time.March, 31, 0, 0, 0, 0, time.Local),
+		},
+		{
+			value: "R/2024-01-01T10:00:00Z/2024-01-01T12:30:00Z",
+			now:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			isIn:  true,
+			start: time.Date(2024, time.December, 31, 22, 30, 0, 0, time.UTC),
+			end:   time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC),
+			next:  time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			value: "R/2024-03-04T20:00:00+08:00/P2W",
+			now:   time.Date(2024, time.March, 4, 11, 59, 0, 0, time.UTC),
+			start: time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, time.March, 18, 12, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] value:%s now:%s\n", i, data.value, data.now)
+		interval, err := ParseRepeatingInterval(data.value)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.isIn, interval.IsIn(data.now))
+
+		if !data.start.IsZero() {
+			start, err := interval.GetStartTime(data.now)
+			assert.Nil(t, err)
+			assert.True(t, data.start.Equal(start), start.String())
+			end, err := interval.GetEndTime(data.now)
+			assert.Nil(t, err)
+			assert.True(t, data.end.Equal(end), end.String())
+		}
+
+		next, err := interval.GetNextStartTime(data.now)
+		if data.err != nil {
+			assert.Equal(t, data.err, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.True(t, data.next.Equal(next), next.String())
+	}
+}
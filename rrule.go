@@ -0,0 +1,297 @@
+package timeexpression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule 支持的频率
+const (
+	rruleDaily   = "DAILY"
+	rruleWeekly  = "WEEKLY"
+	rruleMonthly = "MONTHLY"
+	rruleYearly  = "YEARLY"
+)
+
+// rruleWeekdays RRULE中星期的写法, 下标为星期几(1为周一, 7为周日)
+var rruleWeekdays = []string{"", "MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// rrule RFC 5545 RRULE 的子集: FREQ, INTERVAL, BYMONTH, BYMONTHDAY, BYDAY(不带序号), UNTIL, COUNT
+type rrule struct {
+	freq       string
+	interval   int
+	byMonth    []int
+	byMonthDay []int
+	byDay      []int // 星期几, 1为周一, 7为周日
+	until      time.Time
+	count      int
+}
+
+// String 格式化为 FREQ=DAILY;BYMONTH=2,3 这种格式
+func (rule *rrule) String() string {
+	parts := []string{"FREQ=" + rule.freq}
+	if rule.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.interval))
+	}
+	if len(rule.byMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(rule.byMonth))
+	}
+	if len(rule.byMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(rule.byMonthDay))
+	}
+	if len(rule.byDay) > 0 {
+		days := make([]string, 0, len(rule.byDay))
+		for _, weekday := range rule.byDay {
+			days = append(days, rruleWeekdays[weekday])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !rule.until.IsZero() {
+		parts = append(parts, "UNTIL="+formatICalTime(rule.until))
+	}
+	if rule.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseRRule 解析RRULE的值, etc: FREQ=DAILY;BYMONTH=2,3
+// 不带时区的UNTIL按location解释
+func parseRRule(value string, location *time.Location) (*rrule, error) {
+	rule := &rrule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: invalid rrule part '%s'", ErrICalFormat, part)
+		}
+		var err error
+		switch strings.ToUpper(kv[0]) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(kv[1])
+			switch rule.freq {
+			case rruleDaily, rruleWeekly, rruleMonthly, rruleYearly:
+			default:
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrICalUnsupported, kv[1])
+			}
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(kv[1])
+			if err != nil || rule.interval <= 0 {
+				return nil, fmt.Errorf("%w: INTERVAL=%s", ErrICalFormat, kv[1])
+			}
+		case "BYMONTH":
+			rule.byMonth, err = parseRRuleInts(kv[1], 1, 12)
+		case "BYMONTHDAY":
+			rule.byMonthDay, err = parseRRuleInts(kv[1], 1, 31)
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(kv[1]), ",") {
+				weekday := indexOf(rruleWeekdays, day)
+				if weekday <= 0 {
+					// 带序号的 1MO, -1FR 等
+					return nil, fmt.Errorf("%w: BYDAY=%s", ErrICalUnsupported, day)
+				}
+				rule.byDay = append(rule.byDay, weekday)
+			}
+		case "UNTIL":
+			if len(kv[1]) == len(icalDateLayout) {
+				// 只有日期时包含当天
+				var date time.Time
+				date, err = parseICalDate(kv[1], location)
+				rule.until = date.AddDate(0, 0, 1).Add(-time.Second)
+			} else {
+				rule.until, err = parseICalTime(kv[1], location)
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(kv[1])
+			if err == nil && rule.count <= 0 {
+				err = fmt.Errorf("%w: COUNT=%s", ErrICalFormat, kv[1])
+			}
+		case "WKST":
+			if strings.ToUpper(kv[1]) != "MO" {
+				return nil, fmt.Errorf("%w: WKST=%s", ErrICalUnsupported, kv[1])
+			}
+		default:
+			return nil, fmt.Errorf("%w: rrule part %s", ErrICalUnsupported, kv[0])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rule.freq == "" {
+		return nil, fmt.Errorf("%w: rrule without FREQ", ErrICalFormat)
+	}
+	if !rule.until.IsZero() && rule.count > 0 {
+		return nil, fmt.Errorf("%w: rrule with both UNTIL and COUNT", ErrICalFormat)
+	}
+	return rule, nil
+}
+
+func parseRRuleInts(value string, min int, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number '%s'", ErrICalFormat, item)
+		}
+		if number < min || number > max {
+			// 负数表示倒数, 不支持
+			return nil, fmt.Errorf("%w: value %d", ErrICalUnsupported, number)
+		}
+		values = append(values, number)
+	}
+	return values, nil
+}
+
+func indexOf(values []string, target string) int {
+	for i, value := range values {
+		if value == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchDay 判断某天是否为规则的发生日期, dtStart为第一次发生的时间
+func (rule *rrule) matchDay(dtStart time.Time, day time.Time) bool {
+	startDay := time.Date(dtStart.Year(), dtStart.Month(), dtStart.Day(), 0, 0, 0, 0, dtStart.Location())
+	interval := rule.interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	// 间隔
+	var periods int
+	switch rule.freq {
+	case rruleDaily:
+		periods = daysBetween(startDay, day)
+	case rruleWeekly:
+		// 周从周一开始
+		periods = daysBetween(startDay.AddDate(0, 0, 1-isoWeekday(startDay)), day) / 7
+	case rruleMonthly:
+		periods = (day.Year()-startDay.Year())*12 + int(day.Month()) - int(startDay.Month())
+	case rruleYearly:
+		periods = day.Year() - startDay.Year()
+	}
+	if periods%interval != 0 {
+		return false
+	}
+
+	// 没有配置BY*时, 按照频率使用DTSTART中对应的部分
+	byMonth, byMonthDay, byDay := rule.byMonth, rule.byMonthDay, rule.byDay
+	switch rule.freq {
+	case rruleWeekly:
+		if len(byDay) == 0 {
+			byDay = []int{isoWeekday(startDay)}
+		}
+	case rruleMonthly:
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{startDay.Day()}
+		}
+	case rruleYearly:
+		if len(byMonth) == 0 && len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonth = []int{int(startDay.Month())}
+		}
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{startDay.Day()}
+		}
+	}
+
+	if len(byMonth) > 0 && !containsInt(byMonth, int(day.Month())) {
+		return false
+	}
+	if len(byMonthDay) > 0 && !containsInt(byMonthDay, day.Day()) {
+		return false
+	}
+	if len(byDay) > 0 && !containsInt(byDay, isoWeekday(day)) {
+		return false
+	}
+	return true
+}
+
+// occurrences 获取规则在[from, to)内的发生时间, 最多返回max个
+// 按DTSTART所在时区的本地时间重复, DTSTART总是第一次发生, 和规则不符时也计入COUNT(RFC 5545)
+func (rule *rrule) occurrences(dtStart time.Time, from time.Time, to time.Time, max int) []time.Time {
+	result, _ := rule.expand(dtStart, from, to, max, 0)
+	return result
+}
+
+// expand 同occurrences, horizon大于0时, 距离上一次发生超过horizon天还没有下一次发生就停止, 这时ok返回false
+func (rule *rrule) expand(dtStart time.Time, from time.Time, to time.Time, max int, horizon int) (result []time.Time, ok bool) {
+	count := 0
+	location := dtStart.Location()
+	startDay := time.Date(dtStart.Year(), dtStart.Month(), dtStart.Day(), 0, 0, 0, 0, location)
+	day := startDay
+	if fromDay := truncateDayIn(from, location); rule.count == 0 && fromDay.After(day) {
+		// 没有COUNT时某天是否发生和之前的发生无关, 直接从from所在的那天开始
+		day = fromDay
+	}
+	lastDay := day
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if horizon > 0 && daysBetween(lastDay, day) > horizon {
+			return result, false
+		}
+		occurrence := dtStart
+		if !day.Equal(startDay) {
+			if !rule.matchDay(dtStart, day) {
+				continue
+			}
+			occurrence = time.Date(day.Year(), day.Month(), day.Day(),
+				dtStart.Hour(), dtStart.Minute(), dtStart.Second(), 0, location)
+			if !rule.until.IsZero() && occurrence.After(rule.until) {
+				break
+			}
+		}
+		count++
+		lastDay = day
+		if rule.count > 0 && count > rule.count {
+			break
+		}
+		if !occurrence.Before(from) && occurrence.Before(to) {
+			result = append(result, occurrence)
+			if len(result) >= max {
+				break
+			}
+		}
+	}
+	return result, true
+}
+
+// truncateDayIn 获取时间t在location中当天的0点
+func truncateDayIn(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// daysBetween 两个0点之间相差的天数
+func daysBetween(from time.Time, to time.Time) int {
+	fromUTC := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toUTC := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toUTC.Sub(fromUTC).Hours() / 24)
+}
+
+func containsInt(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		items = append(items, strconv.Itoa(value))
+	}
+	return strings.Join(items, ",")
+}
+
+// intRange 生成[start, end]的数字列表
+func intRange(start int, end int) []int {
+	values := make([]int, 0, end-start+1)
+	for value := start; value <= end; value++ {
+		values = append(values, value)
+	}
+	return values
+}
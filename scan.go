@@ -0,0 +1,207 @@
+package timeexpression
+
+import "time"
+
+// maxScanDays 逐天扫描时, 没有年范围限制的最多扫描天数
+const maxScanDays = 366 * 8
+
+// daySchedule 按天给出活动时间段, 用于无法按字段推算开始/结束时间的表达式(例如按星期几)
+type daySchedule interface {
+	// dayUnits 返回某一天的时间段, day为当天的0点
+	dayUnits(day time.Time) []*hourUnitExpression
+	// dayRange 有效日期的范围[first, last], 没有限制时返回零值
+	dayRange() (first time.Time, last time.Time)
+	// joinsDays 相邻两天首尾相接的时间段(24:00:00与00:00:00)是否为同一个周期
+	joinsDays() bool
+}
+
+// daySeeker 可以跳过大段不会命中的日期的daySchedule, 例如只在部分年份存在的农历闰月
+type daySeeker interface {
+	// seekDay 返回day及之后第一个可能命中的日期, 之后不会再命中时found为false
+	seekDay(day time.Time) (next time.Time, found bool)
+}
+
+// truncateDay 获取当天的0点
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// unitTime 获取某天中时分秒对应的时间, 24:00:00会转换为第二天的0点
+func unitTime(day time.Time, unit hourUnit) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), unit.Hour, unit.Minute, unit.Sec, unit.Nsec, time.Local)
+}
+
+// isoWeekday 星期几, 1为周一, 7为周日
+func isoWeekday(t time.Time) int {
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		return 7
+	}
+	return weekday
+}
+
+// isoWeekYearStart ISO周所属的年的第一天, 即第1周的周一, 第1周为包含1月4日的周
+func isoWeekYearStart(year int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	return jan4.AddDate(0, 0, 1-isoWeekday(jan4))
+}
+
+// scanLimits 计算扫描的边界, 向后从day和有效日期的第一天中较晚的一天开始, 最多扫描maxScanDays天
+// first为向前合并时的边界, bounded表示last为有效日期的最后一天
+func scanLimits(schedule daySchedule, day time.Time) (first time.Time, last time.Time, bounded bool) {
+	first = day.AddDate(0, 0, -maxScanDays)
+	from := day
+	rangeFirst, rangeLast := schedule.dayRange()
+	if !rangeFirst.IsZero() && rangeFirst.After(first) {
+		first = rangeFirst
+	}
+	if first.After(from) {
+		from = first
+	}
+	last = from.AddDate(0, 0, maxScanDays)
+	if !rangeLast.IsZero() && !rangeLast.After(last) {
+		last = rangeLast
+		bounded = true
+	}
+	return first, last, bounded
+}
+
+// scanWindowAt 查找包含时间t的时间段
+func scanWindowAt(schedule daySchedule, t time.Time) (start time.Time, end time.Time, ok bool) {
+	day := truncateDay(t)
+	for _, unit := range schedule.dayUnits(day) {
+		start = unitTime(day, unit.start)
+		end = unitTime(day, unit.end)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// scanStartTime 逐天扫描获取开始时间
+// 1. 如果在周期内,则返回本次周期的开始时间
+// 2. 如果在周期外,则返回下次周期的开始时间
+func scanStartTime(schedule daySchedule, t time.Time) (time.Time, error) {
+	day := truncateDay(t)
+	first, last, bounded := scanLimits(schedule, day)
+
+	if start, _, ok := scanWindowAt(schedule, t); ok {
+		return joinBefore(schedule, start, first), nil
+	}
+
+	if day.Before(first) {
+		day = first
+	}
+	if seeker, ok := schedule.(daySeeker); ok {
+		next, found := seeker.seekDay(day)
+		if !found {
+			return time.Time{}, ErrOutOfDate
+		}
+		if next.After(day) {
+			day = next
+			_, last, bounded = scanLimits(schedule, day)
+		}
+	}
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, unit := range schedule.dayUnits(day) {
+			start := unitTime(day, unit.start)
+			if start.After(t) {
+				return start, nil
+			}
+		}
+	}
+
+	if bounded {
+		return time.Time{}, ErrOutOfDate
+	}
+	return time.Time{}, ErrBeyondScanHorizon
+}
+
+// scanEndTime 逐天扫描获取结束时间
+// 1. 如果在周期内,则返回本次周期的结束时间
+// 2. 如果在周期外,则返回下次周期的结束时间
+func scanEndTime(schedule daySchedule, t time.Time) (time.Time, error) {
+	start, err := scanStartTime(schedule, t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, end, _ := scanWindowAt(schedule, start)
+
+	_, last, _ := scanLimits(schedule, truncateDay(start))
+	return joinAfter(schedule, end, last), nil
+}
+
+// joinBefore 向前合并首尾相接的时间段, start为0点时和前一天结束于24:00:00的时间段合并为一个周期, 最早到first
+func joinBefore(schedule daySchedule, start time.Time, first time.Time) time.Time {
+	if !schedule.joinsDays() {
+		return start
+	}
+	for start.Equal(truncateDay(start)) && start.After(first) {
+		preDay := start.AddDate(0, 0, -1)
+		merged := false
+		for _, unit := range schedule.dayUnits(preDay) {
+			if unitTime(preDay, unit.end).Equal(start) {
+				start = unitTime(preDay, unit.start)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			break
+		}
+	}
+	return start
+}
+
+// joinAfter 向后合并首尾相接的时间段, end为0点时和当天开始于00:00:00的时间段合并为一个周期, 最晚到last
+func joinAfter(schedule daySchedule, end time.Time, last time.Time) time.Time {
+	if !schedule.joinsDays() {
+		return end
+	}
+	for end.Equal(truncateDay(end)) && !end.After(last) {
+		merged := false
+		for _, unit := range schedule.dayUnits(end) {
+			if unitTime(end, unit.start).Equal(end) {
+				end = unitTime(end, unit.end)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			break
+		}
+	}
+	return end
+}
+
+// scanFinalEndTime 从最后一天向前扫描, 获取最后一个周期的结束时间
+func scanFinalEndTime(schedule daySchedule) (time.Time, error) {
+	first, last := schedule.dayRange()
+	if last.IsZero() {
+		return time.Time{}, ErrNoEnd
+	}
+	bounded := !first.IsZero()
+	if !bounded {
+		first = last.AddDate(0, 0, -maxScanDays)
+	}
+
+	for day := last; !day.Before(first); day = day.AddDate(0, 0, -1) {
+		units := schedule.dayUnits(day)
+		if len(units) == 0 {
+			continue
+		}
+		end := units[0].end
+		for _, unit := range units {
+			if unit.end.toDuration() > end.toDuration() {
+				end = unit.end
+			}
+		}
+		return unitTime(day, end), nil
+	}
+
+	if !bounded {
+		return time.Time{}, ErrBeyondScanHorizon
+	}
+	return time.Time{}, ErrNeverMatch
+}
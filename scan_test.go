@@ -0,0 +1,136 @@
+package timeexpression
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testSchedule 每月1日和2日全天, 以及每月10日 20:00:00-24:00:00 和11日 00:00:00-02:00:00
+type testSchedule struct{}
+
+func (testSchedule) dayUnits(day time.Time) []*hourUnitExpression {
+	switch day.Day() {
+	case 1, 2:
+		unit, _ := newHourUnitExpression("*")
+		return []*hourUnitExpression{unit}
+	case 10:
+		unit, _ := newHourUnitExpression("20:00:00-24:00:00")
+		return []*hourUnitExpression{unit}
+	case 11:
+		unit, _ := newHourUnitExpression("00:00:00-02:00:00")
+		return []*hourUnitExpression{unit}
+	}
+	return nil
+}
+
+func (testSchedule) dayRange() (first time.Time, last time.Time) {
+	return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2000, time.December, 31, 0, 0, 0, 0, time.Local)
+}
+
+func (testSchedule) joinsDays() bool {
+	return true
+}
+
+func TestScanStartEndTime(t *testing.T) {
+	testDatas := []struct {
+		input time.Time
+		err   error
+		start time.Time
+		end   time.Time
+	}{
+		{
+			input: time.Date(2000, time.March, 2, 12, 0, 0, 0, time.Local),
+			start: time.Date(2000, time.March, 1, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2000, time.March, 3, 0, 0, 0, 0, time.Local),
+		},
+		{
+			input: time.Date(2000, time.March, 11, 1, 0, 0, 0, time.Local),
+			start: time.Date(2000, time.March, 10, 20, 0, 0, 0, time.Local),
+			end:   time.Date(2000, time.March, 11, 2, 0, 0, 0, time.Local),
+		},
+		{
+			input: time.Date(2000, time.March, 11, 2, 0, 0, 0, time.Local),
+			start: time.Date(2000, time.April, 1, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2000, time.April, 3, 0, 0, 0, 0, time.Local),
+		},
+		{
+			input: time.Date(2000, time.December, 11, 2, 0, 0, 0, time.Local),
+			err:   ErrOutOfDate,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] input:%s\n", i, data.input)
+		start, err := scanStartTime(testSchedule{}, data.input)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.start, start)
+
+		end, err := scanEndTime(testSchedule{}, data.input)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	finalEnd, err := scanFinalEndTime(testSchedule{})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2000, time.December, 11, 2, 0, 0, 0, time.Local), finalEnd)
+}
+
+func TestScanStartTime_FarYears(t *testing.T) {
+	input := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local)
+	testDatas := []struct {
+		exp   string
+		err   error
+		start time.Time
+		end   time.Time
+	}{
+		{
+			exp:   "[2040][*][w1][*]",
+			start: time.Date(2040, time.January, 2, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2040, time.January, 3, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2030-][*][D366][12:00:00-13:00:00]",
+			start: time.Date(2032, time.December, 31, 12, 0, 0, 0, time.Local),
+			end:   time.Date(2032, time.December, 31, 13, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2040][W10][w3][*]",
+			start: time.Date(2040, time.March, 7, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2040, time.March, 8, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2040][L01][01][*]",
+			start: time.Date(2040, time.February, 12, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2040, time.February, 13, 0, 0, 0, 0, time.Local),
+		},
+		{
+			exp:   "[2040][*][立春][*]",
+			start: time.Date(2040, time.February, 4, 0, 0, 0, 0, time.Local),
+			end:   time.Date(2040, time.February, 5, 0, 0, 0, 0, time.Local),
+		},
+		{
+			// 下一个有5个周一的2月在2044年, 超过了扫描的范围, 但不是永远不会命中
+			exp: "[*][02][w1#5][*]",
+			err: ErrBeyondScanHorizon,
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		expr, err := NewDateTimeExpression(data.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start, err := expr.GetStartTime(input)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.start, start)
+
+		end, err := expr.GetEndTime(input)
+		assert.Equal(t, data.err, err)
+		assert.Equal(t, data.end, end)
+	}
+}
@@ -0,0 +1,317 @@
+package timeexpression
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSolarTermOutOfRange 超出了节气计算支持的年份范围(1900-2100)
+var ErrSolarTermOutOfRange = errors.New("solar term out of range")
+
+// ErrSolarTermFormat 节气的名称不对
+var ErrSolarTermFormat = errors.New("solar term format not math")
+
+const (
+	solarTermMinYear = 1900
+	solarTermMaxYear = 2100
+)
+
+// SolarTerm 二十四节气, 按公历年中的顺序从小寒开始
+type SolarTerm int
+
+const (
+	XiaoHan     SolarTerm = iota // 小寒
+	DaHan                        // 大寒
+	LiChun                       // 立春
+	YuShui                       // 雨水
+	JingZhe                      // 惊蛰
+	ChunFen                      // 春分
+	QingMing                     // 清明
+	GuYu                         // 谷雨
+	LiXia                        // 立夏
+	XiaoMan                      // 小满
+	MangZhong                    // 芒种
+	XiaZhi                       // 夏至
+	XiaoShu                      // 小暑
+	DaShu                        // 大暑
+	LiQiu                        // 立秋
+	ChuShu                       // 处暑
+	BaiLu                        // 白露
+	QiuFen                       // 秋分
+	HanLu                        // 寒露
+	ShuangJiang                  // 霜降
+	LiDong                       // 立冬
+	XiaoXue                      // 小雪
+	DaXue                        // 大雪
+	DongZhi                      // 冬至
+)
+
+// solarTermNames 节气的中文名, 拼音和英文名
+var solarTermNames = [24]struct {
+	chinese string
+	pinyin  string
+	english string
+}{
+	{"小寒", "xiaohan", "Minor Cold"},
+	{"大寒", "dahan", "Major Cold"},
+	{"立春", "lichun", "Start of Spring"},
+	{"雨水", "yushui", "Rain Water"},
+	{"惊蛰", "jingzhe", "Awakening of Insects"},
+	{"春分", "chunfen", "Spring Equinox"},
+	{"清明", "qingming", "Pure Brightness"},
+	{"谷雨", "guyu", "Grain Rain"},
+	{"立夏", "lixia", "Start of Summer"},
+	{"小满", "xiaoman", "Grain Buds"},
+	{"芒种", "mangzhong", "Grain in Ear"},
+	{"夏至", "xiazhi", "Summer Solstice"},
+	{"小暑", "xiaoshu", "Minor Heat"},
+	{"大暑", "dashu", "Major Heat"},
+	{"立秋", "liqiu", "Start of Autumn"},
+	{"处暑", "chushu", "End of Heat"},
+	{"白露", "bailu", "White Dew"},
+	{"秋分", "qiufen", "Autumn Equinox"},
+	{"寒露", "hanlu", "Cold Dew"},
+	{"霜降", "shuangjiang", "Frost's Descent"},
+	{"立冬", "lidong", "Start of Winter"},
+	{"小雪", "xiaoxue", "Minor Snow"},
+	{"大雪", "daxue", "Major Snow"},
+	{"冬至", "dongzhi", "Winter Solstice"},
+}
+
+// ParseSolarTerm 按中文名或者拼音解析节气, etc: 立春, lichun
+func ParseSolarTerm(name string) (SolarTerm, error) {
+	name = strings.TrimSpace(name)
+	for i, names := range solarTermNames {
+		if name == names.chinese || strings.ToLower(name) == names.pinyin {
+			return SolarTerm(i), nil
+		}
+	}
+	return 0, ErrSolarTermFormat
+}
+
+// String 节气的中文名
+func (term SolarTerm) String() string {
+	return solarTermNames[term].chinese
+}
+
+// englishName 节气的英文名
+func (term SolarTerm) englishName() string {
+	return solarTermNames[term].english
+}
+
+// Longitude 节气对应的太阳视黄经, 单位为度, 小寒为285, 春分为0
+func (term SolarTerm) Longitude() float64 {
+	return math.Mod(285+15*float64(term), 360)
+}
+
+// Time 计算year年这个节气的时刻, 结果在loc时区, loc决定节气落在哪一天
+// 按VSOP87计算太阳视黄经, 和天文台公布的时刻相差在1分钟左右
+func (term SolarTerm) Time(year int, loc *time.Location) (time.Time, error) {
+	if year < solarTermMinYear || year > solarTermMaxYear {
+		return time.Time{}, ErrSolarTermOutOfRange
+	}
+	return solarTermTimes(year)[term].In(loc), nil
+}
+
+// SolarTerms 计算year年的24个节气的时刻, 按小寒到冬至的顺序, 结果在loc时区
+func SolarTerms(year int, loc *time.Location) ([]time.Time, error) {
+	if year < solarTermMinYear || year > solarTermMaxYear {
+		return nil, ErrSolarTermOutOfRange
+	}
+	times := solarTermTimes(year)
+	terms := make([]time.Time, len(times))
+	for i, t := range times {
+		terms[i] = t.In(loc)
+	}
+	return terms, nil
+}
+
+// solarTermCache 已经计算过的每年的节气时刻
+var solarTermCache = struct {
+	sync.Mutex
+	years map[int][24]time.Time
+}{years: map[int][24]time.Time{}}
+
+// solarTermTimes 计算year年的24个节气的时刻(UTC), 结果会缓存
+func solarTermTimes(year int) [24]time.Time {
+	solarTermCache.Lock()
+	defer solarTermCache.Unlock()
+	if times, ok := solarTermCache.years[year]; ok {
+		return times
+	}
+
+	var times [24]time.Time
+	jan1 := julianDay(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+	for i := range times {
+		term := SolarTerm(i)
+		// 小寒约在1月6日, 之后每个节气约隔15.2天
+		jde := jan1 + 5 + 15.22*float64(i)
+		for iteration := 0; iteration < 10; iteration++ {
+			diff := math.Mod(term.Longitude()-sunApparentLongitude(jde)+540, 360) - 180
+			jde += diff * 365.2422 / 360
+			if math.Abs(diff) < 1e-7 {
+				break
+			}
+		}
+		jd := jde - deltaT(year)/86400
+		times[i] = fromJulianDay(jd).Round(time.Second)
+	}
+	solarTermCache.years[year] = times
+	return times
+}
+
+// julianDay 时间对应的儒略日
+func julianDay(t time.Time) float64 {
+	return float64(t.Unix())/86400 + 2440587.5
+}
+
+// fromJulianDay 儒略日对应的时间(UTC)
+func fromJulianDay(jd float64) time.Time {
+	seconds := (jd - 2440587.5) * 86400
+	sec := math.Floor(seconds)
+	return time.Unix(int64(sec), int64((seconds-sec)*1e9)).UTC()
+}
+
+// deltaT 力学时和世界时的差(秒), 使用Espenak和Meeus的多项式
+func deltaT(year int) float64 {
+	y := float64(year) + 0.5
+	switch {
+	case y < 1920:
+		t := y - 1900
+		return -2.79 + 1.494119*t - 0.0598939*t*t + 0.0061966*t*t*t - 0.000197*t*t*t*t
+	case y < 1941:
+		t := y - 1920
+		return 21.20 + 0.84493*t - 0.076100*t*t + 0.0020936*t*t*t
+	case y < 1961:
+		t := y - 1950
+		return 29.07 + 0.407*t - t*t/233 + t*t*t/2547
+	case y < 1986:
+		t := y - 1975
+		return 45.45 + 1.067*t - t*t/260 - t*t*t/718
+	case y < 2005:
+		t := y - 2000
+		return 63.86 + 0.3345*t - 0.060374*t*t + 0.0017275*t*t*t + 0.000651814*t*t*t*t + 0.00002373599*t*t*t*t*t
+	case y < 2050:
+		t := y - 2000
+		return 62.92 + 0.32217*t + 0.005589*t*t
+	}
+	u := (y - 1820) / 100
+	return -20 + 32*u*u - 0.5628*(2150-y)
+}
+
+// sunApparentLongitude 太阳的视黄经(度), jde为力学时的儒略日
+// 地球的日心黄经使用截断的VSOP87, 加上FK5修正, 章动和光行差
+func sunApparentLongitude(jde float64) float64 {
+	tau := (jde - 2451545) / 365250
+	var longitude, power float64 = 0, 1
+	for _, series := range vsop87EarthL {
+		var sum float64
+		for _, term := range series {
+			sum += term[0] * math.Cos(term[1]+term[2]*tau)
+		}
+		longitude += sum * power
+		power *= tau
+	}
+	// 日心黄经转换为地心黄经
+	longitude = longitude/1e8*180/math.Pi + 180
+
+	t := tau * 10
+	radians := math.Pi / 180
+	omega := (125.04452 - 1934.136261*t) * radians
+	sunMean := (280.4665 + 36000.7698*t) * radians
+	moonMean := (218.3165 + 481267.8813*t) * radians
+	// 黄经章动(角秒)
+	nutation := -17.20*math.Sin(omega) - 1.32*math.Sin(2*sunMean) - 0.23*math.Sin(2*moonMean) + 0.21*math.Sin(2*omega)
+	// 日地距离(天文单位), 用于光行差
+	distance := 1.00014 - 0.01671*math.Cos((357.52911+35999.05029*t)*radians)
+	longitude += (-0.09033 + nutation - 20.4898/distance) / 3600
+
+	return math.Mod(math.Mod(longitude, 360)+360, 360)
+}
+
+// vsop87EarthL 地球日心黄经的VSOP87级数(L0-L5), 每项为A, B, C, 值为A*cos(B+C*tau), 单位为1e-8弧度
+var vsop87EarthL = [][][3]float64{
+	{
+		{175347046, 0, 0}, {3341656, 4.6692568, 6283.0758500}, {34894, 4.62610, 12566.15170},
+		{3497, 2.7441, 5753.3849}, {3418, 2.8289, 3.5231}, {3136, 3.6277, 77713.7715},
+		{2676, 4.4181, 7860.4194}, {2343, 6.1352, 3930.2097}, {1324, 0.7425, 11506.7698},
+		{1273, 2.0371, 529.6910}, {1199, 1.1096, 1577.3435}, {990, 5.233, 5884.927},
+		{902, 2.045, 26.298}, {857, 3.508, 398.149}, {780, 1.179, 5223.694},
+		{753, 2.533, 5507.553}, {505, 4.583, 18849.228}, {492, 4.205, 775.523},
+		{357, 2.920, 0.067}, {317, 5.849, 11790.629}, {284, 1.899, 796.298},
+		{271, 0.315, 10977.079}, {243, 0.345, 5486.778}, {206, 4.806, 2544.314},
+		{205, 1.869, 5573.143}, {202, 2.458, 6069.777}, {156, 0.833, 213.299},
+		{132, 3.411, 2942.463}, {126, 1.083, 20.775}, {115, 0.645, 0.980},
+		{103, 0.636, 4694.003}, {102, 0.976, 15720.839}, {102, 4.267, 7.114},
+		{99, 6.21, 2146.17}, {98, 0.68, 155.42}, {86, 5.98, 161000.69},
+		{85, 1.30, 6275.96}, {85, 3.67, 71430.70}, {80, 1.81, 17260.15},
+		{79, 3.04, 12036.46}, {75, 1.76, 5088.63}, {74, 3.50, 3154.69},
+		{74, 4.68, 801.82}, {70, 0.83, 9437.76}, {62, 3.98, 8827.39},
+		{61, 1.82, 7084.90}, {57, 2.78, 6286.60}, {56, 4.39, 14143.50},
+		{56, 3.47, 6279.55}, {52, 0.19, 12139.55}, {52, 1.33, 1748.02},
+		{51, 0.28, 5856.48}, {49, 0.49, 1194.45}, {41, 5.37, 8429.24},
+		{41, 2.40, 19651.05}, {39, 6.17, 10447.39}, {37, 6.04, 10213.29},
+		{37, 2.57, 1059.38}, {36, 1.71, 2352.87}, {36, 1.78, 6812.77},
+		{33, 0.59, 17789.85}, {30, 0.44, 83996.85}, {30, 2.74, 1349.87},
+		{25, 3.16, 4690.48},
+	},
+	{
+		{628331966747, 0, 0}, {206059, 2.678235, 6283.075850}, {4303, 2.6351, 12566.1517},
+		{425, 1.590, 3.523}, {119, 5.796, 26.298}, {109, 2.966, 1577.344},
+		{93, 2.59, 18849.23}, {72, 1.14, 529.69}, {68, 1.87, 398.15},
+		{67, 4.41, 5507.55}, {59, 2.89, 5223.69}, {56, 2.17, 155.42},
+		{45, 0.40, 796.30}, {36, 0.47, 775.52}, {29, 2.65, 7.11},
+		{21, 5.34, 0.98}, {19, 1.85, 5486.78}, {19, 4.97, 213.30},
+		{17, 2.99, 6275.96}, {16, 0.03, 2544.31}, {16, 1.43, 2146.17},
+		{15, 1.21, 10977.08}, {12, 2.83, 1748.02}, {12, 3.26, 5088.63},
+		{12, 5.27, 1194.45}, {12, 2.08, 4694.00}, {11, 0.77, 553.57},
+		{10, 1.30, 6286.60}, {10, 4.24, 1349.87}, {9, 2.70, 242.73},
+		{9, 5.64, 951.72}, {8, 5.30, 2352.87}, {6, 2.65, 9437.76},
+		{6, 4.67, 4690.48},
+	},
+	{
+		{52919, 0, 0}, {8720, 1.0721, 6283.0758}, {309, 0.867, 12566.152},
+		{27, 0.05, 3.52}, {16, 5.19, 26.30}, {16, 3.68, 155.42},
+		{10, 0.76, 18849.23}, {9, 2.06, 77713.77}, {7, 0.83, 775.52},
+		{5, 4.66, 1577.34}, {4, 1.03, 7.11}, {4, 3.44, 5573.14},
+		{3, 5.14, 796.30}, {3, 6.05, 5507.55}, {3, 1.19, 242.73},
+		{3, 6.12, 529.69}, {3, 0.31, 398.15}, {3, 2.28, 553.57},
+		{2, 4.38, 5223.69}, {2, 3.75, 0.98},
+	},
+	{
+		{289, 5.844, 6283.076}, {35, 0, 0}, {17, 5.49, 12566.15},
+		{3, 5.20, 155.42}, {1, 4.72, 3.52}, {1, 5.30, 18849.23},
+		{1, 5.97, 242.73},
+	},
+	{
+		{114, 3.142, 0}, {8, 4.13, 6283.08}, {1, 3.84, 12566.15},
+	},
+	{
+		{1, 3.14, 0},
+	},
+}
+
+// solarTermOfDay 时间t所在的日期处于哪个节气之后(在time.Local中), isTermDay表示这一天就是节气当天
+func solarTermOfDay(t time.Time) (term SolarTerm, isTermDay bool, ok bool) {
+	day := truncateDay(t)
+	year := day.Year()
+	if year < solarTermMinYear || year > solarTermMaxYear {
+		return 0, false, false
+	}
+
+	times := solarTermTimes(year)
+	for i := len(times) - 1; i >= 0; i-- {
+		termDay := truncateDay(times[i].In(time.Local))
+		if !termDay.After(day) {
+			return SolarTerm(i), termDay.Equal(day), true
+		}
+	}
+	// 在当年小寒之前, 属于上一年的冬至之后
+	if year == solarTermMinYear {
+		return 0, false, false
+	}
+	return DongZhi, false, true
+}
@@ -0,0 +1,183 @@
+package timeexpression
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseSolarTerm(t *testing.T) {
+	testDatas := []struct {
+		name string
+		term SolarTerm
+		err  error
+	}{
+		{name: "立春", term: LiChun},
+		{name: "lichun", term: LiChun},
+		{name: "DongZhi", term: DongZhi},
+		{name: "小寒", term: XiaoHan},
+		{name: "立春节", err: ErrSolarTermFormat},
+		{name: "", err: ErrSolarTermFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] name:%s\n", i, data.name)
+		term, err := ParseSolarTerm(data.name)
+		assert.Equal(t, data.err, err)
+		if data.err == nil {
+			assert.Equal(t, data.term, term)
+		}
+	}
+	assert.Equal(t, 315.0, LiChun.Longitude())
+	assert.Equal(t, 0.0, ChunFen.Longitude())
+}
+
+// TestSolarTerms 和紫金山天文台公布的2024年节气时刻(北京时间)对比, 误差在1分钟内
+func TestSolarTerms(t *testing.T) {
+	beijing := time.FixedZone("CST", 8*3600)
+	published := []string{
+		"01-06 04:49", "01-20 22:07", "02-04 16:27", "02-19 12:13", "03-05 10:23", "03-20 11:06",
+		"04-04 15:02", "04-19 21:59", "05-05 08:10", "05-20 20:59", "06-05 12:10", "06-21 04:51",
+		"07-06 22:20", "07-22 15:44", "08-07 08:09", "08-22 22:55", "09-07 11:11", "09-22 20:44",
+		"10-08 03:00", "10-23 06:15", "11-07 06:20", "11-22 03:56", "12-06 23:17", "12-21 17:21",
+	}
+
+	terms, err := SolarTerms(2024, beijing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, value := range published {
+		fmt.Printf("[%d] term:%s published:%s\n", i, SolarTerm(i), value)
+		expected, err := time.ParseInLocation("2006-01-02 15:04", "2024-"+value, beijing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := terms[i].Sub(expected)
+		assert.True(t, diff > -time.Minute && diff < time.Minute, "%s: %s, published %s", SolarTerm(i), terms[i], value)
+	}
+
+	_, err = SolarTerms(1899, beijing)
+	assert.Equal(t, ErrSolarTermOutOfRange, err)
+	_, err = DongZhi.Time(2101, beijing)
+	assert.Equal(t, ErrSolarTermOutOfRange, err)
+}
+
+// TestSolarTerm_Time 和公布的二分二至时刻(UTC)对比, 误差在1分钟内
+func TestSolarTerm_Time(t *testing.T) {
+	testDatas := []struct {
+		term      SolarTerm
+		published time.Time
+	}{
+		{term: ChunFen, published: time.Date(2000, time.March, 20, 7, 35, 0, 0, time.UTC)},
+		{term: DongZhi, published: time.Date(2000, time.December, 21, 13, 37, 0, 0, time.UTC)},
+		{term: ChunFen, published: time.Date(2010, time.March, 20, 17, 32, 0, 0, time.UTC)},
+		{term: XiaZhi, published: time.Date(2010, time.June, 21, 11, 28, 0, 0, time.UTC)},
+		{term: QiuFen, published: time.Date(2010, time.September, 23, 3, 9, 0, 0, time.UTC)},
+		{term: DongZhi, published: time.Date(2010, time.December, 21, 23, 38, 0, 0, time.UTC)},
+		{term: DongZhi, published: time.Date(2012, time.December, 21, 11, 12, 0, 0, time.UTC)},
+		{term: XiaZhi, published: time.Date(2020, time.June, 20, 21, 43, 0, 0, time.UTC)},
+		{term: ChunFen, published: time.Date(2030, time.March, 20, 13, 51, 0, 0, time.UTC)},
+		{term: LiChun, published: time.Date(2025, time.February, 3, 14, 10, 0, 0, time.UTC)},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] term:%s published:%s\n", i, data.term, data.published)
+		value, err := data.term.Time(data.published.Year(), time.UTC)
+		assert.Nil(t, err)
+		diff := value.Sub(data.published)
+		assert.True(t, diff > -time.Minute && diff < time.Minute, "%s: %s", data.term, value)
+	}
+}
+
+func TestDateTimeExpression_SolarTerm(t *testing.T) {
+	// 节气落在哪一天取决于时区, 期望的结果按time.Local计算
+	termDay := func(term SolarTerm, year int) time.Time {
+		value, err := term.Time(year, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return truncateDay(value)
+	}
+
+	testDatas := []struct {
+		exp       string
+		formatted string
+		t         time.Time
+		isIn      bool
+		start     time.Time
+		end       time.Time
+	}{
+		{
+			exp:   "[*][*][立春][10:00:00-12:00:00]",
+			t:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local),
+			start: termDay(LiChun, 2024).Add(10 * time.Hour),
+			end:   termDay(LiChun, 2024).Add(12 * time.Hour),
+		},
+		{
+			exp:   "[*][*][立春][10:00:00-12:00:00]",
+			t:     termDay(LiChun, 2024).Add(11 * time.Hour),
+			isIn:  true,
+			start: termDay(LiChun, 2024).Add(10 * time.Hour),
+			end:   termDay(LiChun, 2024).Add(12 * time.Hour),
+		},
+		{
+			exp:       "[*][*][lichun-lixia][*]",
+			formatted: "[*][*][立春-立夏][*]",
+			t:         time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local),
+			isIn:      true,
+			start:     termDay(LiChun, 2024),
+			end:       termDay(LiXia, 2024).AddDate(0, 0, 1),
+		},
+		{
+			// 包含结束的节气当天
+			exp:   "[*][*][立冬-立春][*]",
+			t:     termDay(LiChun, 2025).Add(12 * time.Hour),
+			isIn:  true,
+			start: termDay(LiDong, 2024),
+			end:   termDay(LiChun, 2025).AddDate(0, 0, 1),
+		},
+		{
+			// 跨年的范围
+			exp:   "[*][*][冬至-小寒][*]",
+			t:     time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+			start: termDay(DongZhi, 2024),
+			end:   termDay(XiaoHan, 2025).AddDate(0, 0, 1),
+		},
+		{
+			exp:   "[2024][*][冬至-小寒][*]",
+			t:     time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+			start: termDay(DongZhi, 2024),
+			end:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		formatted := data.formatted
+		if formatted == "" {
+			formatted = data.exp
+		}
+		assert.Equal(t, formatted, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.start, start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.Equal(t, data.end, end)
+	}
+
+	_, err := NewDateTimeExpression("[*][*][立春-立春][*]")
+	assert.Equal(t, ErrDayFormat, err)
+	_, err = NewDateTimeExpression("[*][*][立春-立秋-立冬][*]")
+	assert.Equal(t, ErrDayFormat, err)
+	_, err = NewDateTimeExpression("[*][*][立春-秋天][*]")
+	assert.Equal(t, ErrSolarTermFormat, err)
+	_, err = NewDateTimeExpression("[1899-1900][*][立春][*]")
+	assert.Equal(t, ErrSolarTermOutOfRange, err)
+}
@@ -0,0 +1,237 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPolarDay 极昼, 这一天太阳不落下, 没有日出和日落
+	ErrPolarDay = errors.New("sun does not set on this day")
+	// ErrPolarNight 极夜, 这一天太阳不升起, 没有日出和日落
+	ErrPolarNight = errors.New("sun does not rise on this day")
+	// ErrGeoLocationFormat 经纬度或者海拔不对
+	ErrGeoLocationFormat = errors.New("geo location format not math")
+	// ErrNoGeoLocation 表达式使用了日出日落(sunrise, sunset), 但是没有通过WithGeoLocation设置位置
+	ErrNoGeoLocation = errors.New("expression needs a geo location")
+)
+
+// sunHorizon 日出日落时太阳中心的高度(度), 包含了大气折射和太阳的视半径
+const sunHorizon = -0.8333
+
+// siderealRate 平太阳日中恒星时增加的度数
+const siderealRate = 360.985647
+
+// SunEvent 日出或者日落
+type SunEvent int
+
+const (
+	// NoSunEvent 不以日出日落为基准, 为钟表上的时间
+	NoSunEvent SunEvent = iota
+	// Sunrise 日出
+	Sunrise
+	// Sunset 日落
+	Sunset
+)
+
+// String 格式化为sunrise或者sunset
+func (event SunEvent) String() string {
+	switch event {
+	case Sunrise:
+		return "sunrise"
+	case Sunset:
+		return "sunset"
+	}
+	return ""
+}
+
+// GeoLocation 观测者的位置
+type GeoLocation struct {
+	// Latitude 纬度, 北纬为正, [-90, 90]
+	Latitude float64
+	// Longitude 经度, 东经为正, [-180, 180]
+	Longitude float64
+	// Elevation 海拔(米), 越高地平线越低, 日出越早, 日落越晚, 低于海平面时按0计算
+	Elevation float64
+}
+
+// check 检查经纬度的范围
+func (location GeoLocation) check() error {
+	if math.IsNaN(location.Latitude) || location.Latitude < -90 || location.Latitude > 90 ||
+		math.IsNaN(location.Longitude) || location.Longitude < -180 || location.Longitude > 180 ||
+		math.IsNaN(location.Elevation) || math.IsInf(location.Elevation, 0) {
+		return fmt.Errorf("%w: %g,%g,%g", ErrGeoLocationFormat, location.Latitude, location.Longitude, location.Elevation)
+	}
+	return nil
+}
+
+// SunTimes 计算day所在日期(按day的时区)的日出和日落时刻, 结果在day的时区, 精确到秒
+// 取的是这一天正午前后的日出和日落, 和天文台公布的时刻相差在1分钟左右
+// 太阳这一天不落下时返回ErrPolarDay, 不升起时返回ErrPolarNight
+func (location GeoLocation) SunTimes(day time.Time) (sunrise time.Time, sunset time.Time, err error) {
+	if err = location.check(); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	sunrise, err = location.sunEventTime(day, Sunrise)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	sunset, err = location.sunEventTime(day, Sunset)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return sunrise, sunset, nil
+}
+
+// sunEventTime 计算day所在日期的日出或者日落时刻
+// 先迭代求出正午前后的中天, 再从中天出发迭代求出太阳高度为地平线高度的时刻
+func (location GeoLocation) sunEventTime(day time.Time, event SunEvent) (time.Time, error) {
+	jd := julianDay(time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, day.Location()))
+	for i := 0; i < 3; i++ {
+		jd -= location.hourAngle(jd) / siderealRate
+	}
+
+	// 海拔越高, 看到的地平线越低
+	horizon := sunHorizon - 2.076*math.Sqrt(math.Max(location.Elevation, 0))/60
+	for i := 0; i < 6; i++ {
+		_, declination := sunPosition(jd)
+		semiArc, err := location.semiDiurnalArc(declination, horizon)
+		if err != nil {
+			return time.Time{}, err
+		}
+		target := semiArc
+		if event == Sunrise {
+			target = -semiArc
+		}
+		delta := normalizeDegrees(target-location.hourAngle(jd)) / siderealRate
+		jd += delta
+		if math.Abs(delta) < 1.0/86400/10 {
+			break
+		}
+	}
+
+	return fromJulianDay(jd).Round(time.Second).In(day.Location()), nil
+}
+
+// hourAngle 太阳在jd(世界时的儒略日)时的时角(度), 在(-180, 180]之间, 上中天为0
+func (location GeoLocation) hourAngle(jd float64) float64 {
+	rightAscension, _ := sunPosition(jd)
+	return normalizeDegrees(siderealTime(jd) + location.Longitude - rightAscension)
+}
+
+// semiDiurnalArc 太阳从地平线升到中天的时角(度), 太阳高度为horizon时
+func (location GeoLocation) semiDiurnalArc(declination float64, horizon float64) (float64, error) {
+	radians := math.Pi / 180
+	latitude := location.Latitude * radians
+	denominator := math.Cos(latitude) * math.Cos(declination*radians)
+	if denominator < 1e-12 {
+		// 在极点上
+		denominator = 1e-12
+	}
+	cosArc := (math.Sin(horizon*radians) - math.Sin(latitude)*math.Sin(declination*radians)) / denominator
+	switch {
+	case cosArc < -1:
+		return 0, ErrPolarDay
+	case cosArc > 1:
+		return 0, ErrPolarNight
+	}
+	return math.Acos(cosArc) / radians, nil
+}
+
+// sunPosition 太阳在jd(世界时的儒略日)时的视赤经和视赤纬(度)
+func sunPosition(jd float64) (rightAscension float64, declination float64) {
+	jde := jd + deltaT(fromJulianDay(jd).Year())/86400
+	t := (jde - 2451545) / 36525
+	radians := math.Pi / 180
+	longitude := sunApparentLongitude(jde) * radians
+	// 黄赤交角, 加上章动的主要项
+	omega := (125.04452 - 1934.136261*t) * radians
+	obliquity := (23.439291111 - 0.0130041667*t + 0.00256*math.Cos(omega)) * radians
+
+	rightAscension = math.Atan2(math.Cos(obliquity)*math.Sin(longitude), math.Cos(longitude)) / radians
+	declination = math.Asin(math.Sin(obliquity)*math.Sin(longitude)) / radians
+	return math.Mod(rightAscension+360, 360), declination
+}
+
+// siderealTime 格林尼治平恒星时(度), jd为世界时的儒略日
+func siderealTime(jd float64) float64 {
+	t := (jd - 2451545) / 36525
+	value := 280.46061837 + 360.98564736629*(jd-2451545) + 0.000387933*t*t - t*t*t/38710000
+	return math.Mod(math.Mod(value, 360)+360, 360)
+}
+
+// normalizeDegrees 把角度转换到(-180, 180]
+func normalizeDegrees(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees > 180 {
+		degrees -= 360
+	} else if degrees <= -180 {
+		degrees += 360
+	}
+	return degrees
+}
+
+// sunAnchor 时间段的端点, 以日出或者日落为基准加上偏移
+type sunAnchor struct {
+	event SunEvent
+	// offset 偏移的秒数, 可以为负
+	offset int
+}
+
+// newSunAnchor 格式为sunrise, sunset, 可以加上偏移, etc: sunset-00:30:00, sunrise+01:00:00
+func newSunAnchor(value string) (*sunAnchor, error) {
+	anchor := &sunAnchor{}
+	switch {
+	case strings.HasPrefix(value, "sunrise"):
+		anchor.event = Sunrise
+	case strings.HasPrefix(value, "sunset"):
+		anchor.event = Sunset
+	default:
+		return nil, ErrHourUnitFormat
+	}
+
+	offset := strings.TrimPrefix(strings.TrimPrefix(value, "sunrise"), "sunset")
+	if offset == "" {
+		return anchor, nil
+	}
+	unit, err := newHourTimeUnit(offset[1:])
+	if err != nil {
+		return nil, err
+	}
+	switch offset[0] {
+	case '+':
+		anchor.offset = unit.toSec()
+	case '-':
+		anchor.offset = -unit.toSec()
+	default:
+		return nil, ErrHourUnitFormat
+	}
+	return anchor, nil
+}
+
+// resolve 计算day这一天的时刻, 没有日出日落时返回ErrPolarDay或者ErrPolarNight
+func (anchor *sunAnchor) resolve(day time.Time, location GeoLocation) (time.Time, error) {
+	eventTime, err := location.sunEventTime(day, anchor.event)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return eventTime.Add(time.Duration(anchor.offset) * time.Second), nil
+}
+
+// String 格式化为sunset或者sunset-hh:mm:ss
+func (anchor *sunAnchor) String() string {
+	if anchor.offset == 0 {
+		return anchor.event.String()
+	}
+	sign := "+"
+	offset := anchor.offset
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	unit := secToHourUnit(offset)
+	return anchor.event.String() + sign + unit.String()
+}
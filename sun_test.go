@@ -0,0 +1,273 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestGeoLocation_SunTimes 和NOAA公布的日出日落时刻对比, 误差在1分钟内
+func TestGeoLocation_SunTimes(t *testing.T) {
+	testDatas := []struct {
+		name     string
+		location GeoLocation
+		zone     string
+		day      string
+		sunrise  string
+		sunset   string
+		err      error
+	}{
+		{name: "New York", location: GeoLocation{Latitude: 40.7128, Longitude: -74.0060}, zone: "America/New_York",
+			day: "2024-06-20", sunrise: "05:25", sunset: "20:31"},
+		{name: "London", location: GeoLocation{Latitude: 51.5074, Longitude: -0.1278}, zone: "Europe/London",
+			day: "2024-12-21", sunrise: "08:04", sunset: "15:54"},
+		{name: "Beijing", location: GeoLocation{Latitude: 39.9042, Longitude: 116.4074}, zone: "Asia/Shanghai",
+			day: "2024-06-21", sunrise: "04:46", sunset: "19:46"},
+		{name: "Sydney", location: GeoLocation{Latitude: -33.8688, Longitude: 151.2093}, zone: "Australia/Sydney",
+			day: "2024-01-01", sunrise: "05:47", sunset: "20:09"},
+		// 极昼和极夜
+		{name: "Tromso", location: GeoLocation{Latitude: 69.6492, Longitude: 18.9553}, zone: "Europe/Oslo",
+			day: "2024-06-21", err: ErrPolarDay},
+		{name: "Tromso", location: GeoLocation{Latitude: 69.6492, Longitude: 18.9553}, zone: "Europe/Oslo",
+			day: "2024-12-21", err: ErrPolarNight},
+		{name: "North Pole", location: GeoLocation{Latitude: 90}, zone: "UTC", day: "2024-06-21", err: ErrPolarDay},
+		{name: "South Pole", location: GeoLocation{Latitude: -90}, zone: "UTC", day: "2024-06-21", err: ErrPolarNight},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] location:%s day:%s\n", i, data.name, data.day)
+		zone, err := time.LoadLocation(data.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		day, err := time.ParseInLocation("2006-01-02", data.day, zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sunrise, sunset, err := data.location.SunTimes(day)
+		assert.Equal(t, data.err, err)
+		if data.err != nil {
+			continue
+		}
+		for _, check := range []struct {
+			value     time.Time
+			published string
+		}{{value: sunrise, published: data.sunrise}, {value: sunset, published: data.sunset}} {
+			expected, err := time.ParseInLocation("2006-01-02 15:04", data.day+" "+check.published, zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := check.value.Sub(expected)
+			assert.True(t, diff > -time.Minute && diff < time.Minute, "%s: %s, published %s", data.name, check.value, check.published)
+		}
+	}
+
+	// 海拔越高日出越早, 日落越晚, 1000米时地平线下降约1度
+	day := time.Date(2024, time.June, 21, 0, 0, 0, 0, time.Local)
+	sunrise, sunset, err := GeoLocation{Latitude: 39.9042, Longitude: 116.4074}.SunTimes(day)
+	assert.Nil(t, err)
+	highSunrise, highSunset, err := GeoLocation{Latitude: 39.9042, Longitude: 116.4074, Elevation: 1000}.SunTimes(day)
+	assert.Nil(t, err)
+	assert.True(t, sunrise.Sub(highSunrise) > 5*time.Minute && sunrise.Sub(highSunrise) < 10*time.Minute, highSunrise.String())
+	assert.True(t, highSunset.Sub(sunset) > 5*time.Minute && highSunset.Sub(sunset) < 10*time.Minute, highSunset.String())
+
+	_, _, err = GeoLocation{Latitude: 91}.SunTimes(time.Now())
+	assert.True(t, errors.Is(err, ErrGeoLocationFormat))
+	_, _, err = GeoLocation{Longitude: -181}.SunTimes(time.Now())
+	assert.True(t, errors.Is(err, ErrGeoLocationFormat))
+}
+
+func TestNewSunAnchor(t *testing.T) {
+	testDatas := []struct {
+		value  string
+		event  SunEvent
+		offset int
+		err    error
+	}{
+		{value: "sunrise", event: Sunrise},
+		{value: "sunset", event: Sunset},
+		{value: "sunset-00:30:00", event: Sunset, offset: -1800},
+		{value: "sunrise+02:00:00", event: Sunrise, offset: 7200},
+		{value: "sunset*02:00:00", err: ErrHourUnitFormat},
+		{value: "sunset+02:00", err: ErrHourUnitFormat},
+		{value: "sundown", err: ErrHourUnitFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] value:%s\n", i, data.value)
+		anchor, err := newSunAnchor(data.value)
+		assert.Equal(t, data.err, err)
+		if data.err != nil {
+			continue
+		}
+		assert.Equal(t, data.event, anchor.event)
+		assert.Equal(t, data.offset, anchor.offset)
+		assert.Equal(t, data.value, anchor.String())
+	}
+}
+
+func TestDateTimeExpression_Sun(t *testing.T) {
+	// 日出日落取决于time.Local中的哪一天, 期望的结果按time.Local计算
+	beijing := GeoLocation{Latitude: 39.9042, Longitude: 116.4074}
+	stockholm := GeoLocation{Latitude: 59.3293, Longitude: 18.0686}
+	sunTimes := func(location GeoLocation, day time.Time) (time.Time, time.Time) {
+		sunrise, sunset, err := location.SunTimes(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sunrise, sunset
+	}
+	_, beijingSunset := sunTimes(beijing, time.Date(2024, time.June, 21, 0, 0, 0, 0, time.Local))
+	_, stockholmSunset := sunTimes(stockholm, time.Date(2024, time.June, 21, 0, 0, 0, 0, time.Local))
+	beijingSunrise, _ := sunTimes(beijing, time.Date(2024, time.June, 22, 0, 0, 0, 0, time.Local))
+
+	testDatas := []struct {
+		exp      string
+		location GeoLocation
+		t        time.Time
+		isIn     bool
+		start    time.Time
+		end      time.Time
+	}{
+		{
+			exp:      "[*][*][*][sunset-00:30:00-sunset+02:00:00]",
+			location: beijing,
+			t:        beijingSunset.Add(-2 * time.Hour),
+			start:    beijingSunset.Add(-30 * time.Minute),
+			end:      beijingSunset.Add(2 * time.Hour),
+		},
+		{
+			exp:      "[*][*][*][sunset-00:30:00-sunset+02:00:00]",
+			location: beijing,
+			t:        beijingSunset,
+			isIn:     true,
+			start:    beijingSunset.Add(-30 * time.Minute),
+			end:      beijingSunset.Add(2 * time.Hour),
+		},
+		{
+			// 日落到第二天日出
+			exp:      "[*][*][*][sunset-sunrise]",
+			location: beijing,
+			t:        beijingSunset.Add(time.Hour),
+			isIn:     true,
+			start:    beijingSunset,
+			end:      beijingSunrise,
+		},
+		{
+			// 跨过0点的时间段会合并为一个周期
+			exp:      "[*][*][*][sunset-sunset+03:00:00]",
+			location: stockholm,
+			t:        stockholmSunset.Add(150 * time.Minute),
+			isIn:     true,
+			start:    stockholmSunset,
+			end:      stockholmSunset.Add(3 * time.Hour),
+		},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s t:%s\n", i, data.exp, data.t)
+		expr, err := NewDateTimeExpression(data.exp,
+			WithGeoLocation(data.location.Latitude, data.location.Longitude, data.location.Elevation))
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, data.exp, expr.String())
+		assert.Equal(t, data.isIn, expr.IsIn(data.t))
+		start, err := expr.GetStartTime(data.t)
+		assert.Nil(t, err)
+		assert.True(t, data.start.Equal(start), "start %s, expected %s", start, data.start)
+		end, err := expr.GetEndTime(data.t)
+		assert.Nil(t, err)
+		assert.True(t, data.end.Equal(end), "end %s, expected %s", end, data.end)
+	}
+}
+
+func TestDateTimeExpression_SunPolar(t *testing.T) {
+	tromso := WithGeoLocation(69.6492, 18.9553, 0)
+
+	// 极昼期间没有日落, 整个6月都不会命中
+	expr, err := NewDateTimeExpression("[2024][06][*][sunset-sunset+01:00:00]", tromso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.FirstStartTime()
+	assert.Equal(t, ErrNeverMatch, err)
+
+	// 极昼结束后的第一次日落
+	expr, err = NewDateTimeExpression("[2024][*][*][sunset-sunset+01:00:00]", tromso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	midsummer := time.Date(2024, time.June, 21, 12, 0, 0, 0, time.Local)
+	assert.False(t, expr.IsIn(midsummer))
+	start, err := expr.GetStartTime(midsummer)
+	assert.Nil(t, err)
+	assert.True(t, start.After(time.Date(2024, time.July, 15, 0, 0, 0, 0, time.Local)), start.String())
+	assert.True(t, start.Before(time.Date(2024, time.August, 1, 0, 0, 0, 0, time.Local)), start.String())
+
+	// 12月是极夜, 跳过没有日出的天, 下一次在1月中旬
+	expr, err = NewDateTimeExpression("[*][*][*][sunrise-sunset]", tromso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	december := time.Date(2024, time.December, 10, 12, 0, 0, 0, time.Local)
+	assert.False(t, expr.IsIn(december))
+	for _, get := range []func(time.Time) (time.Time, error){expr.GetStartTime, expr.GetNextStartTime} {
+		start, err = get(december)
+		assert.Nil(t, err)
+		assert.True(t, start.After(time.Date(2025, time.January, 10, 0, 0, 0, 0, time.Local)), start.String())
+		assert.True(t, start.Before(time.Date(2025, time.January, 20, 0, 0, 0, 0, time.Local)), start.String())
+	}
+	findings := expr.Lint(december)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, LintPolarPeriod, findings[0].Code)
+		assert.Equal(t, SeverityWarning, findings[0].Severity)
+	}
+
+	// 没有极昼和极夜的位置
+	expr, err = NewDateTimeExpression("[*][*][*][sunrise-sunset]", WithGeoLocation(39.9042, 116.4074, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, expr.Lint(december))
+}
+
+func TestDateTimeExpression_SunError(t *testing.T) {
+	location := WithGeoLocation(39.9042, 116.4074, 0)
+	testDatas := []struct {
+		exp  string
+		opts []Option
+		err  error
+	}{
+		{exp: "[*][*][*][sunset-sunset+02:00:00]", err: ErrNoGeoLocation},
+		{exp: "[*][*][*][sunset-sunset+02:00:00]", opts: []Option{location, WithDayStart(5 * time.Hour)}, err: ErrDayStartFormat},
+		{exp: "[*][*][*][sunset-sunset+02:00:00]", opts: []Option{WithGeoLocation(0, 200, 0)}, err: ErrGeoLocationFormat},
+		{exp: "[*][*][*][sunset-sunset]", opts: []Option{location}},
+		{exp: "[*][*][*][sunset+01:00:00-sunset]", opts: []Option{location}},
+		{exp: "[*][*][*][*,sunset-sunset+02:00:00]", opts: []Option{location}},
+		{exp: "[*][*][*][sunset-sunset+25:00:00]", opts: []Option{location}, err: ErrHourUnitFormat},
+		{exp: "[*][*][*][sunset+01:00-sunrise]", opts: []Option{location}, err: ErrHourUnitFormat},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		_, err := NewDateTimeExpression(data.exp, data.opts...)
+		if data.err == nil {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+	}
+
+	expr, err := NewDateTimeExpression("[*][*][*][sunset-00:30:00-sunset+02:00:00]", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expr.ToCron(false)
+	assert.True(t, errors.Is(err, ErrCronLossy))
+	_, err = expr.ToOpeningHours()
+	assert.True(t, errors.Is(err, ErrOpeningHoursLossy))
+}
@@ -0,0 +1,189 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrTemplateFormat 模板的格式不对, 例如参数没有声明, 参数不是整个字段或者类型和所在的字段不一致
+	ErrTemplateFormat = errors.New("template format not math")
+	// ErrTemplateParam 实例化模板时参数不对, 例如缺少参数, 未知的参数或者值不符合类型
+	ErrTemplateParam = errors.New("template parameter not math")
+)
+
+// placeholderPattern 模板中的参数, etc: ${year}
+var placeholderPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// paramNamePattern 参数的名字
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParamType 模板参数的类型, 即参数所在的字段
+type ParamType int
+
+const (
+	// ParamYear 年的字段, etc: 2024, 2024-2025, *
+	ParamYear ParamType = iota
+	// ParamMonth 月的字段, etc: 03, 06-08, Q2, Jan-Mar
+	ParamMonth
+	// ParamDay 日的字段, etc: 01-07, w6-7, L, @weekend
+	ParamDay
+	// ParamHour 时分秒的字段, etc: 20:00:00-22:00:00
+	ParamHour
+)
+
+// String 格式化为year, month, day, hour
+func (paramType ParamType) String() string {
+	switch paramType {
+	case ParamYear:
+		return "year"
+	case ParamMonth:
+		return "month"
+	case ParamDay:
+		return "day"
+	case ParamHour:
+		return "hour"
+	}
+	return fmt.Sprintf("ParamType(%d)", int(paramType))
+}
+
+// TemplateParam 模板的参数
+type TemplateParam struct {
+	Name string
+	Type ParamType
+	// Default 默认值, 为空时实例化必须提供
+	Default string
+}
+
+// Template 带参数的表达式模板, 参数为${name}, 需要占据整个字段, etc: [${year}][${month}][01-07][20:00:00-22:00:00]
+type Template struct {
+	// fields 年, 月, 日, 时分秒四个字段, 参数字段为${name}
+	fields [4]string
+	params map[string]TemplateParam
+	opts   []Option
+}
+
+// NewTemplate 创建模板, 参数需要全部声明并且都被使用, 类型需要和所在的字段一致
+// 创建时用默认值(没有默认值时为*)代入参数解析一次, 固定部分有错误的模板在实例化之前就会失败
+// opts在每次实例化时使用
+func NewTemplate(expression string, params []TemplateParam, opts ...Option) (*Template, error) {
+	template := &Template{params: make(map[string]TemplateParam, len(params)), opts: opts}
+	for _, param := range params {
+		if !paramNamePattern.MatchString(param.Name) || param.Type < ParamYear || param.Type > ParamHour {
+			return nil, fmt.Errorf("%w: parameter %q of type %s", ErrTemplateFormat, param.Name, param.Type)
+		}
+		if _, ok := template.params[param.Name]; ok {
+			return nil, fmt.Errorf("%w: parameter %s is declared twice", ErrTemplateFormat, param.Name)
+		}
+		if param.Default != "" {
+			if err := param.check(param.Default); err != nil {
+				return nil, err
+			}
+		}
+		template.params[param.Name] = param
+	}
+
+	expression = strings.TrimPrefix(expression, "[")
+	expression = strings.TrimSuffix(expression, "]")
+	expSplits := strings.Split(expression, "][")
+	if len(expSplits) != 4 {
+		return nil, fmt.Errorf("%w: needs year, month, day and hour fields", ErrTemplateFormat)
+	}
+
+	used := make(map[string]bool, len(params))
+	for i, field := range expSplits {
+		field = strings.Trim(field, " ")
+		template.fields[i] = field
+		if !placeholderPattern.MatchString(field) {
+			continue
+		}
+		match := placeholderPattern.FindStringSubmatch(field)
+		if match[0] != field {
+			return nil, fmt.Errorf("%w: parameter must be a whole field: %s", ErrTemplateFormat, field)
+		}
+		param, ok := template.params[match[1]]
+		if !ok {
+			return nil, fmt.Errorf("%w: parameter %s is not declared", ErrTemplateFormat, match[1])
+		}
+		if param.Type != ParamType(i) {
+			return nil, fmt.Errorf("%w: %s parameter %s is used in the %s field", ErrTemplateFormat, param.Type, param.Name, ParamType(i))
+		}
+		used[param.Name] = true
+	}
+	for _, param := range params {
+		if !used[param.Name] {
+			return nil, fmt.Errorf("%w: parameter %s is not used", ErrTemplateFormat, param.Name)
+		}
+	}
+
+	// 用默认值检查模板的固定部分
+	if _, err := NewDateTimeExpression(template.expand(nil), opts...); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// check 检查参数的值, 值需要是参数所在字段的合法格式, 可以使用宏
+func (param TemplateParam) check(value string) error {
+	value, err := expandMacros(value)
+	if err == nil {
+		switch param.Type {
+		case ParamYear:
+			_, err = newYearExpression(value)
+		case ParamMonth:
+			_, err = newMonthExpression(value)
+		case ParamDay:
+			_, err = newDayExpression(value)
+		case ParamHour:
+			_, err = newHourExpression(value)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s=%s: %v", ErrTemplateParam, param.Name, value, err)
+	}
+	return nil
+}
+
+// Instantiate 用参数的值创建表达式, 没有提供的参数使用默认值, 提供了未声明的参数时返回错误
+func (template *Template) Instantiate(values map[string]string) (*DateTimeExpression, error) {
+	for name, value := range values {
+		param, ok := template.params[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown parameter %s", ErrTemplateParam, name)
+		}
+		if err := param.check(value); err != nil {
+			return nil, err
+		}
+	}
+	for name, param := range template.params {
+		if _, ok := values[name]; !ok && param.Default == "" {
+			return nil, fmt.Errorf("%w: missing parameter %s", ErrTemplateParam, name)
+		}
+	}
+
+	return NewDateTimeExpression(template.expand(values), template.opts...)
+}
+
+// expand 把参数替换为values中的值, 没有时使用默认值, 再没有时使用*
+func (template *Template) expand(values map[string]string) string {
+	fields := make([]string, 0, len(template.fields))
+	for _, field := range template.fields {
+		if match := placeholderPattern.FindStringSubmatch(field); match != nil {
+			field = "*"
+			if value, ok := values[match[1]]; ok {
+				field = value
+			} else if param := template.params[match[1]]; param.Default != "" {
+				field = param.Default
+			}
+		}
+		fields = append(fields, field)
+	}
+	return "[" + strings.Join(fields, "][") + "]"
+}
+
+// String 格式化为模板的表达式, etc: [${year}][${month}][01-07][20:00:00-22:00:00]
+func (template *Template) String() string {
+	return "[" + strings.Join(template.fields[:], "][") + "]"
+}
@@ -0,0 +1,122 @@
+package timeexpression
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTemplate_Instantiate(t *testing.T) {
+	template, err := NewTemplate("[${year}][${month}][01-07][20:00:00-22:00:00]", []TemplateParam{
+		{Name: "year", Type: ParamYear},
+		{Name: "month", Type: ParamMonth, Default: "*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "[${year}][${month}][01-07][20:00:00-22:00:00]", template.String())
+
+	testDatas := []struct {
+		values map[string]string
+		result string
+		err    error
+	}{
+		{values: map[string]string{"year": "2024", "month": "02"}, result: "[2024][02][01-07][20:00:00-22:00:00]"},
+		{values: map[string]string{"year": "2024-2025", "month": "Q3"}, result: "[2024-2025][Q3][01-07][20:00:00-22:00:00]"},
+		// 使用默认值
+		{values: map[string]string{"year": "2024"}, result: "[2024][*][01-07][20:00:00-22:00:00]"},
+		{values: map[string]string{"month": "02"}, err: ErrTemplateParam},
+		{values: map[string]string{"year": "2024", "day": "01"}, err: ErrTemplateParam},
+		{values: map[string]string{"year": "2024", "month": "13"}, err: ErrTemplateParam},
+		{values: map[string]string{"year": "2024][03", "month": "02"}, err: ErrTemplateParam},
+		{values: map[string]string{"year": "next"}, err: ErrTemplateParam},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] values:%v\n", i, data.values)
+		expr, err := template.Instantiate(data.values)
+		if data.err != nil {
+			assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+			continue
+		}
+		if assert.Nil(t, err) {
+			assert.Equal(t, data.result, expr.String())
+		}
+	}
+
+	expr, err := template.Instantiate(map[string]string{"year": "2024", "month": "02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, err := expr.GetStartTime(time.Date(2024, time.January, 10, 0, 0, 0, 0, time.Local))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 1, 20, 0, 0, 0, time.Local), start)
+}
+
+func TestTemplate_Options(t *testing.T) {
+	// 参数的值可以使用宏, 实例化时使用模板的选项
+	template, err := NewTemplate("[2024][*][${days}][${hours}]", []TemplateParam{
+		{Name: "days", Type: ParamDay, Default: "@weekend"},
+		{Name: "hours", Type: ParamHour},
+	}, WithDayStart(5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expr, err := template.Instantiate(map[string]string{"hours": "02:00:00-04:00:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "[2024][*][w6-7][02:00:00-04:00:00]", expr.String())
+	// 2024-03-09 是周六, 逻辑上周六的02:00-04:00在周日
+	assert.True(t, expr.IsIn(time.Date(2024, time.March, 10, 3, 0, 0, 0, time.Local)))
+	assert.False(t, expr.IsIn(time.Date(2024, time.March, 9, 3, 0, 0, 0, time.Local)))
+
+	_, err = template.Instantiate(map[string]string{"hours": "02:00:00-04:00:00", "days": "@nothing"})
+	assert.True(t, errors.Is(err, ErrTemplateParam), fmt.Sprint(err))
+}
+
+func TestNewTemplate_Error(t *testing.T) {
+	testDatas := []struct {
+		exp    string
+		params []TemplateParam
+		opts   []Option
+		err    error
+	}{
+		{exp: "[${year}][*][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrTemplateFormat},
+		// 没有声明, 没有使用, 声明两次
+		{exp: "[${year}][*][*][*]", err: ErrTemplateFormat},
+		{exp: "[2024][*][*][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrTemplateFormat},
+		{exp: "[${year}][*][*][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}, {Name: "year", Type: ParamYear}}, err: ErrTemplateFormat},
+		// 类型和字段不一致, 不是整个字段
+		{exp: "[${month}][*][*][*]", params: []TemplateParam{{Name: "month", Type: ParamMonth}}, err: ErrTemplateFormat},
+		{exp: "[${year}-2030][*][*][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrTemplateFormat},
+		{exp: "[*][*][*][${hours},23:00:00-24:00:00]", params: []TemplateParam{{Name: "hours", Type: ParamHour}}, err: ErrTemplateFormat},
+		// 参数的名字和类型
+		{exp: "[${1st}][*][*][*]", params: []TemplateParam{{Name: "1st", Type: ParamYear}}, err: ErrTemplateFormat},
+		{exp: "[${year}][*][*][*]", params: []TemplateParam{{Name: "year", Type: ParamType(9)}}, err: ErrTemplateFormat},
+		// 默认值不对
+		{exp: "[*][${month}][*][*]", params: []TemplateParam{{Name: "month", Type: ParamMonth, Default: "13"}}, err: ErrTemplateParam},
+		// 固定部分不对
+		{exp: "[${year}][13][*][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}},
+		{exp: "[${year}][*][32][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}},
+		{exp: "[${year}][*][@missing][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrUnknownMacro},
+		{exp: "[${year}][*][bd][*]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrNoCalendar},
+		{exp: "[${year}][*][*][sunset-sunrise]", params: []TemplateParam{{Name: "year", Type: ParamYear}}, err: ErrNoGeoLocation},
+	}
+
+	for i, data := range testDatas {
+		fmt.Printf("[%d] exp:%s\n", i, data.exp)
+		_, err := NewTemplate(data.exp, data.params, data.opts...)
+		if data.err == nil {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.True(t, errors.Is(err, data.err), fmt.Sprint(err))
+	}
+
+	_, err := NewTemplate("[${year}][*][bd][*]", []TemplateParam{{Name: "year", Type: ParamYear}}, WithCalendar(NewCalendar()))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,97 @@
+package timeexpression
+
+import (
+	"sort"
+	"time"
+)
+
+// window 一个周期[start, end)
+type window struct {
+	start time.Time
+	end   time.Time
+}
+
+// collectWindows 获取[from, until)内开始的周期, 包含from所在的周期, 最多返回max个
+func collectWindows(expression Expression, from time.Time, until time.Time, max int) ([]window, error) {
+	var windows []window
+	t := from
+	for len(windows) < max {
+		start, err := expression.GetStartTime(t)
+		if err == ErrOutOfDate {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !start.Before(until) {
+			break
+		}
+		end, err := expression.GetEndTime(start)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window{start: start, end: end})
+		t = end
+	}
+	return windows, nil
+}
+
+// mergeWindows 排序并合并重叠的周期
+// 首尾相接的周期保持独立, 和DateTimeExpression一致: 配置了时分秒的时间段不会跨过0点和第二天的时间段合并
+func mergeWindows(windows []window) []window {
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+
+	var merged []window
+	for _, w := range windows {
+		if !w.start.Before(w.end) {
+			continue
+		}
+		last := len(merged) - 1
+		if last >= 0 && w.start.Before(merged[last].end) {
+			if w.end.After(merged[last].end) {
+				merged[last].end = w.end
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// isInWindows 时间是否在某个周期内
+func isInWindows(windows []window, t time.Time) bool {
+	for _, w := range windows {
+		if !t.Before(w.start) && t.Before(w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowAt 获取包含t的周期, 不在周期内时获取下一个周期
+// windows 需要是合并过的, exhausted 表示windows之后不会再有周期
+func windowAt(windows []window, t time.Time, exhausted bool) (window, error) {
+	for _, w := range windows {
+		if t.Before(w.end) {
+			return w, nil
+		}
+	}
+	if exhausted {
+		return window{}, ErrOutOfDate
+	}
+	return window{}, ErrBeyondScanHorizon
+}
+
+// nextStartTime 获取下次开始时间, 在周期内时为本次周期结束后的下一个周期
+func nextStartTime(expression Expression, t time.Time) (time.Time, error) {
+	if expression.IsIn(t) {
+		end, err := expression.GetEndTime(t)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = end
+	}
+	return expression.GetStartTime(t)
+}
@@ -2,6 +2,7 @@ package timeexpression
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -18,39 +19,46 @@ type yearExpression struct {
 	start int
 	end   int
 	isAll bool
+	// hasStart, hasEnd 是否有开始年和结束年, etc: 2024- 没有结束年, -2030 没有开始年, *两者都没有
+	// 没有时对应的start或end不设置, 使用前先判断hasStart和hasEnd
+	hasStart bool
+	hasEnd   bool
 }
 
-//newYearExpression 创建年的时间表达式,支持格式为 [*,yyyy,yyyy-yyyy]
+//newYearExpression 创建年的时间表达式,支持格式为 [*,yyyy,yyyy-yyyy,yyyy-,-yyyy]
 func newYearExpression(expression string) (*yearExpression, error) {
 	yearExpression := &yearExpression{}
 
 	expression = strings.Trim(expression, " ")
 	if expression == "*" {
-		// *的情况
-		yearExpression.start = 0
-		yearExpression.end = MaxYear
+		// *的情况, 和两端都开放的范围相同
 		yearExpression.isAll = true
 		return yearExpression, nil
 	}
 
 	splitYearStr := strings.Split(expression, "-")
-	if len(splitYearStr) > 2 {
+	if len(splitYearStr) > 2 || expression == "-" {
 		return nil, ErrYearFormat
 	}
 
 	var err error
-	yearExpression.start, err = parseYearInt(splitYearStr[0])
-	if err != nil {
-		return nil, err
+	if splitYearStr[0] != "" {
+		yearExpression.start, err = parseYearInt(splitYearStr[0])
+		if err != nil {
+			return nil, err
+		}
+		yearExpression.hasStart = true
 	}
 
-	if len(splitYearStr) == 2 {
+	if len(splitYearStr) == 1 {
+		yearExpression.end = yearExpression.start
+		yearExpression.hasEnd = true
+	} else if splitYearStr[1] != "" {
 		yearExpression.end, err = parseYearInt(splitYearStr[1])
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		yearExpression.end = yearExpression.start
+		yearExpression.hasEnd = true
 	}
 
 	err = yearExpression.check()
@@ -63,7 +71,7 @@ func newYearExpression(expression string) (*yearExpression, error) {
 
 // check 检查参数
 func (expression *yearExpression) check() error {
-	if expression.start > expression.end {
+	if expression.hasStart && expression.hasEnd && expression.start > expression.end {
 		return errors.New("year error: start after end")
 	}
 
@@ -73,11 +81,37 @@ func (expression *yearExpression) check() error {
 // isIn 是否在范围内
 func (expression *yearExpression) isIn(year int) bool {
 
-	if year >= expression.start && year <= expression.end {
-		return true
+	if expression.hasStart && year < expression.start {
+		return false
+	}
+
+	return !expression.isAfterEnd(year)
+}
+
+// isAfterEnd 是否在结束年之后, 没有结束年时总是false
+func (expression *yearExpression) isAfterEnd(year int) bool {
+	return expression.hasEnd && year > expression.end
+}
+
+// inRange 配置的开始年和结束年是否都在[min, max]内, 没有配置的不检查
+func (expression *yearExpression) inRange(min int, max int) bool {
+	if expression.hasStart && (expression.start < min || expression.start > max) {
+		return false
 	}
+	return !expression.hasEnd || expression.end >= min && expression.end <= max
+}
 
-	return false
+// bounds 用于逐年遍历的范围, 没有开始年或结束年时只取400年(公历每400年重复一次)
+func (expression *yearExpression) bounds() (first int, last int) {
+	switch {
+	case expression.hasStart && expression.hasEnd:
+		return expression.start, expression.end
+	case expression.hasStart:
+		return expression.start, expression.start + 399
+	case expression.hasEnd:
+		return expression.end - 399, expression.end
+	}
+	return 2000, 2399
 }
 
 // getStart 获取开始年
@@ -86,12 +120,13 @@ func (expression *yearExpression) isIn(year int) bool {
 //    在开始前,返回开始的年
 //    在范围后，则返回错误
 func (expression *yearExpression) getStart(year int) (int, error) {
-	if expression.isAll {
-		return year, nil
+	if expression.isAfterEnd(year) {
+		return 0, ErrOutOfDate
 	}
 
-	if expression.end < year {
-		return 0, ErrOutOfDate
+	if !expression.hasStart {
+		// 没有开始年时和*一样
+		return year, nil
 	}
 
 	return expression.start, nil
@@ -100,13 +135,29 @@ func (expression *yearExpression) getStart(year int) (int, error) {
 // getEnd 获取结束年, 仅支持周期内
 // PS:如果年没有超出期限，则结束年为配置周期的结束年
 func (expression *yearExpression) getEnd(year int) (int, error) {
-	if expression.isAll {
-		return year, nil
+	if expression.isAfterEnd(year) {
+		return 0, ErrOutOfDate
 	}
 
-	if expression.end < year {
-		return 0, ErrOutOfDate
+	if !expression.hasEnd {
+		// 没有结束年时和*一样
+		return year, nil
 	}
 
 	return expression.end, nil
 }
+
+// String 格式化为*,yyyy,yyyy-yyyy,yyyy-,-yyyy
+func (expression *yearExpression) String() string {
+	switch {
+	case expression.isAll:
+		return "*"
+	case !expression.hasEnd:
+		return fmt.Sprintf("%04d-", expression.start)
+	case !expression.hasStart:
+		return fmt.Sprintf("-%04d", expression.end)
+	case expression.start == expression.end:
+		return fmt.Sprintf("%04d", expression.start)
+	}
+	return fmt.Sprintf("%04d-%04d", expression.start, expression.end)
+}
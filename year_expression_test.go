@@ -20,7 +20,7 @@ func TestNewYearExpression(t *testing.T) {
 			exp:   "*",
 			err:   nil,
 			start: 0,
-			end:   MaxYear,
+			end:   0,
 			IsAll: true,
 		},
 		{
@@ -61,6 +61,24 @@ func TestNewYearExpression(t *testing.T) {
 			exp: "2001-1999",
 			err: errors.New("year error: start after end"),
 		},
+		{
+			exp:   "2024-",
+			err:   nil,
+			start: 2024,
+			end:   0,
+			IsAll: false,
+		},
+		{
+			exp:   "-2030",
+			err:   nil,
+			start: 0,
+			end:   2030,
+			IsAll: false,
+		},
+		{
+			exp: "-",
+			err: ErrYearFormat,
+		},
 	}
 
 	for _, data := range testDatas {
@@ -137,6 +155,46 @@ func TestYearExpression_IsIn(t *testing.T) {
 		t.Fatal(err)
 	}
 	testIsInYear(t, expression, testDatas)
+
+	testDatas = []testData{
+		{
+			t:      "1990",
+			result: false,
+		},
+		{
+			t:      "1991",
+			result: true,
+		},
+		{
+			t:      "9999",
+			result: true,
+		},
+	}
+	expression, err = newYearExpression("1991-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testIsInYear(t, expression, testDatas)
+
+	testDatas = []testData{
+		{
+			t:      "0001",
+			result: true,
+		},
+		{
+			t:      "1991",
+			result: true,
+		},
+		{
+			t:      "1992",
+			result: false,
+		},
+	}
+	expression, err = newYearExpression("-1991")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testIsInYear(t, expression, testDatas)
 }
 
 func testIsInYear(t *testing.T, expression *yearExpression, testDatas []testData) {
@@ -204,6 +262,24 @@ func TestYearExpression_GetStart(t *testing.T) {
 			err:    ErrOutOfDate,
 			result: 0,
 		},
+		{
+			exp:    "2000-",
+			input:  2100,
+			err:    nil,
+			result: 2000,
+		},
+		{
+			exp:    "-2003",
+			input:  2001,
+			err:    nil,
+			result: 2001,
+		},
+		{
+			exp:    "-2003",
+			input:  2004,
+			err:    ErrOutOfDate,
+			result: 0,
+		},
 	}
 
 	for _, data := range testDatas {
@@ -268,6 +344,18 @@ func TestYearExpression_GetEnd(t *testing.T) {
 			err:    ErrOutOfDate,
 			result: 0,
 		},
+		{
+			exp:    "2000-",
+			input:  2100,
+			err:    nil,
+			result: 2100,
+		},
+		{
+			exp:    "-2003",
+			input:  1999,
+			err:    nil,
+			result: 2003,
+		},
 	}
 
 	for _, data := range testDatas {